@@ -0,0 +1,146 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/action"
+	"github.com/kaseat/pManager/storage"
+)
+
+// GetCorporateActions gets corporate actions
+// @summary Get corporate actions
+// @description Get splits, renames, spin-offs and conversions of securities
+// @id get-actions
+// @produce json
+// @param isin query string false "ISIN"
+// @success 200 {array} models.CorporateAction "Returns corporate actions sorted by date"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags actions
+// @security ApiKeyAuth
+// @router /actions [get]
+func GetCorporateActions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	s := storage.GetStorage()
+	actions, err := s.GetCorporateActions(r.FormValue("isin"))
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	writeOk(w, actions)
+}
+
+// AddCorporateActions adds corporate actions
+// @summary Add corporate actions
+// @description Adds corporate actions of securities. Action of the same security, type and date is overwritten.
+// @description Positions, average prices, reports and realized P&L restate operations made before action date. Fraction of resulting unit is sold at cost as cash in lieu
+// @id add-actions
+// @accept json
+// @produce json
+// @param actions body []models.CorporateAction true "Corporate actions"
+// @success 200 {object} commonResponse "Returns success status"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags actions
+// @security ApiKeyAuth
+// @router /actions [post]
+func AddCorporateActions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var actions []models.CorporateAction
+	err = json.Unmarshal(body, &actions)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for i, a := range actions {
+		if err = validateCorporateAction(a); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid action #%d: %v", i+1, err))
+			return
+		}
+	}
+
+	s := storage.GetStorage()
+	err = s.AddCorporateActions(actions)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	writeOk(w, commonResponse{Status: "ok"})
+}
+
+// DeleteCorporateActions deletes corporate actions
+// @summary Delete corporate actions
+// @description Deletes all corporate actions of given security
+// @id delete-actions
+// @produce json
+// @param isin query string true "ISIN"
+// @success 200 {object} delMutileSuccess "Returns number of deleted items"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags actions
+// @security ApiKeyAuth
+// @router /actions [delete]
+func DeleteCorporateActions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	isin := r.FormValue("isin")
+	if isin == "" {
+		writeError(w, http.StatusBadRequest, `You must provide "isin" parameter`)
+		return
+	}
+
+	s := storage.GetStorage()
+	n, err := s.DeleteCorporateActions(isin)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	writeOk(w, delMutileSuccess{DeletedItems: n})
+}
+
+func validateCorporateAction(a models.CorporateAction) error {
+	if a.ISIN == "" {
+		return errors.New("ISIN must be specified")
+	}
+	if a.Date.IsZero() {
+		return errors.New("Date must be specified")
+	}
+	if a.RatioFrom <= 0 || a.RatioTo <= 0 {
+		return errors.New("Ratio from and ratio to must be positive")
+	}
+	switch a.Type {
+	case action.Split:
+	case action.Rename:
+		if a.NewISIN == "" && a.NewTicker == "" {
+			return errors.New("New ISIN or new ticker must be specified for rename")
+		}
+	case action.SpinOff, action.Conversion:
+		if a.NewISIN == "" {
+			return errors.New("New ISIN must be specified for spin-off and conversion")
+		}
+	default:
+		return fmt.Errorf("Unknown action type '%s'", a.Type)
+	}
+	if a.CostRatio < 0 || a.CostRatio >= 1 {
+		return errors.New("Cost ratio must be in range [0, 1)")
+	}
+	return nil
+}
@@ -7,9 +7,16 @@ import (
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/kaseat/pManager/auth"
+	"github.com/kaseat/pManager/config"
+	"github.com/kaseat/pManager/storage"
 )
 
-var secret = []byte("my_secret_key")
+var secret = []byte(config.Default().Auth.Secret)
+
+// Configure sets authentication settings
+func Configure(c config.Auth) {
+	secret = []byte(c.Secret)
+}
 
 // Claims represents users claims
 type Claims struct {
@@ -78,6 +85,7 @@ func Login(w http.ResponseWriter, r *http.Request) {
 // @Param password formData string true "Password"
 // @Success 200 {object} tokenResponse
 // @Failure 401 {object} errorResponse
+// @Failure 409 {object} errorResponse
 // @Router /user/signup [post]
 func SignUp(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -88,6 +96,10 @@ func SignUp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	saved, err := auth.SaveСredentials(u.Username, u.Password)
+	if storage.KindOf(err) == storage.Conflict {
+		writeStorageError(w, err)
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusUnauthorized, err.Error())
 		return
@@ -2,18 +2,81 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/kaseat/pManager/fx"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
 	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/utils"
+	"github.com/kaseat/pManager/validation"
 )
 
 func writeError(w http.ResponseWriter, statusCode int, text string) {
-	resp := errorResponse{Error: text}
+	resp := errorResponse{Error: text, Code: errorCode(statusCode)}
 	bytes, _ := json.Marshal(&resp)
 	w.WriteHeader(statusCode)
 	w.Write(bytes)
 }
 
+// writeStorageError writes storage error with status matching its kind.
+// Unclassified errors come from database drivers, so their details are logged only
+func writeStorageError(w http.ResponseWriter, err error) {
+	switch storage.KindOf(err) {
+	case storage.NotFound:
+		writeError(w, http.StatusNotFound, err.Error())
+	case storage.Conflict:
+		writeError(w, http.StatusConflict, err.Error())
+	case storage.Forbidden:
+		writeError(w, http.StatusForbidden, err.Error())
+	case storage.Validation:
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Storage error:", err)
+		writeError(w, http.StatusInternalServerError, "Internal storage error")
+	}
+}
+
+// errorCode returns machine-readable code of error response with given status
+func errorCode(statusCode int) string {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return string(storage.Forbidden)
+	case http.StatusNotFound:
+		return string(storage.NotFound)
+	case http.StatusConflict:
+		return string(storage.Conflict)
+	case http.StatusUnprocessableEntity:
+		return string(storage.Validation)
+	case http.StatusInternalServerError:
+		return "internal"
+	default:
+		return "badRequest"
+	}
+}
+
+// writeValidationError lists every invalid field with 422 status.
+// Other errors are written as bad request
+func writeValidationError(w http.ResponseWriter, err error) {
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := validationErrorResponse{Error: "Validation failed", Code: errorCode(http.StatusUnprocessableEntity), Fields: errs}
+	bytes, _ := json.Marshal(&resp)
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	w.Write(bytes)
+}
+
 func writeOk(w http.ResponseWriter, resp interface{}) {
 	bytes, err := json.Marshal(&resp)
 	if err != nil {
@@ -24,6 +87,12 @@ func writeOk(w http.ResponseWriter, resp interface{}) {
 	w.Write(bytes)
 }
 
+// writeList writes page of items. Total number of matched items is set to X-Total-Count header
+func writeList(w http.ResponseWriter, items interface{}, total int64) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	writeOk(w, items)
+}
+
 func canAccess(s storage.Db, login string, pid string) (bool, error) {
 	u, err := s.GetUserByLogin(login)
 	if err != nil {
@@ -44,3 +113,210 @@ func canAccess(s storage.Db, login string, pid string) (bool, error) {
 	}
 	return canAccess, nil
 }
+
+// parseDateRange parses optional from and to query values in RFC3339 format
+func parseDateRange(from, to string) (time.Time, time.Time, error) {
+	var f, t time.Time
+	var err error
+	if from != "" {
+		f, err = time.Parse("2006-01-02T15:04:05Z07:00", from)
+		if err != nil {
+			return f, t, fmt.Errorf("Invalid 'from' parameter '%s'. Expected RFC3339 date", from)
+		}
+	}
+	if to != "" {
+		t, err = time.Parse("2006-01-02T15:04:05Z07:00", to)
+		if err != nil {
+			return f, t, fmt.Errorf("Invalid 'to' parameter '%s'. Expected RFC3339 date", to)
+		}
+	}
+	return f, t, nil
+}
+
+// parseListQuery reads pagination, sorting and given filters from query values.
+// Filter values may be repeated or comma separated
+func parseListQuery(r *http.Request, filters ...string) (models.ListQuery, error) {
+	q := models.ListQuery{
+		Filters: make(map[string][]string),
+		From:    r.FormValue("from"),
+		To:      r.FormValue("to"),
+	}
+	if v := r.FormValue("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			return q, fmt.Errorf("Invalid 'limit' parameter '%s'. Expected number from 1 to %d", v, maxListLimit)
+		}
+		q.Limit = limit
+	}
+	if v := r.FormValue("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return q, fmt.Errorf("Invalid 'offset' parameter '%s'. Expected non-negative number", v)
+		}
+		q.Offset = offset
+	}
+	q.Sort = splitValues(r.Form["sort"])
+	for _, key := range filters {
+		if values := splitValues(r.Form[key]); len(values) != 0 {
+			q.Filters[key] = values
+		}
+	}
+	return q, nil
+}
+
+const maxListLimit = 1000
+
+func splitValues(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// inRange checks whether date is in range. Zero bound means no limit
+func inRange(date, from, to time.Time) bool {
+	return (from.IsZero() || !date.Before(from)) && (to.IsZero() || !date.After(to))
+}
+
+// parseReportCurrency validates optional reportCurrency query value
+func parseReportCurrency(value string) (currency.Type, error) {
+	curr := currency.Type(value)
+	if curr == "" || curr == currency.EUR || curr == currency.RUB || curr == currency.USD {
+		return curr, nil
+	}
+	return curr, fmt.Errorf("Unknown report currency '%s'. Expected '%s', '%s' or '%s'",
+		curr, currency.EUR, currency.RUB, currency.USD)
+}
+
+type cashBalance struct {
+	Currency currency.Type
+	Amount   float64
+}
+
+// getCashBalances sums cash flows of given operations separately for every
+// currency. Operations without currency are considered to be in rubles
+func getCashBalances(ops []models.Operation) []cashBalance {
+	byCurrency := make(map[currency.Type][]models.Operation)
+	for _, op := range ops {
+		c := op.Currency
+		if c == "" {
+			c = currency.RUB
+		}
+		byCurrency[c] = append(byCurrency[c], op)
+	}
+	result := []cashBalance{}
+	for c, cops := range byCurrency {
+		result = append(result, cashBalance{Currency: c, Amount: utils.GetSum(cops)})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Currency < result[j].Currency })
+	return result
+}
+
+// loadConverter loads currency rates up to given date
+func loadConverter(s storage.Db, to time.Time) (fx.Converter, error) {
+	return fx.Load(s, to.AddDate(0, 0, 1))
+}
+
+// getAdjustedOperations returns all operations of portfolio restated by corporate actions
+func getAdjustedOperations(s storage.Db, pid string) ([]models.Operation, error) {
+	ops, err := s.GetOperations(pid, "", "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	actions, err := s.GetCorporateActions("")
+	if err != nil {
+		return nil, err
+	}
+	return utils.ApplyCorporateActions(ops, actions), nil
+}
+
+// adjustShares recalculates volumes of shares affected by corporate actions
+// made till given date. Shares of other securities are kept as is
+func adjustShares(s storage.Db, pid string, shares []models.Share, on time.Time) ([]models.Share, error) {
+	all, err := s.GetCorporateActions("")
+	if err != nil {
+		return nil, err
+	}
+	actions := []models.CorporateAction{}
+	affected := make(map[string]bool)
+	for _, a := range all {
+		if a.Date.After(on) {
+			continue
+		}
+		actions = append(actions, a)
+		affected[a.ISIN] = true
+		if a.NewISIN != "" {
+			affected[a.NewISIN] = true
+		}
+	}
+	if len(actions) == 0 {
+		return shares, nil
+	}
+
+	ops, err := s.GetOperations(pid, "", "", "", on.Format("2006-01-02T15:04:05Z07:00"))
+	if err != nil {
+		return nil, err
+	}
+	volumes := make(map[string]int64)
+	for _, op := range utils.ApplyCorporateActions(ops, actions) {
+		if !affected[op.ISIN] {
+			continue
+		}
+		switch op.OperationType {
+		case operation.Buy:
+			volumes[op.ISIN] += op.Volume
+		case operation.Sell, operation.Buyback:
+			volumes[op.ISIN] -= op.Volume
+		}
+	}
+
+	result := make([]models.Share, 0, len(shares))
+	for _, sh := range shares {
+		if !affected[sh.ISIN] {
+			result = append(result, sh)
+			continue
+		}
+		if vol := volumes[sh.ISIN]; vol != 0 {
+			sh.Volume = vol
+			result = append(result, sh)
+		}
+		delete(volumes, sh.ISIN)
+	}
+
+	// securities received by conversion or spin-off
+	tickers, err := getTickers(s)
+	if err != nil {
+		return nil, err
+	}
+	for isin, vol := range volumes {
+		if vol == 0 {
+			continue
+		}
+		sh := models.Share{ISIN: isin, Ticker: tickers[isin], Volume: vol, Date: on}
+		prices, err := s.GetPricesByIsin(isin, "", on.Format("2006-01-02T15:04:05Z07:00"))
+		if err != nil {
+			return nil, err
+		}
+		var last time.Time
+		for _, p := range prices {
+			if !p.Date.Before(last) {
+				last = p.Date
+				sh.Price = p.Price
+			}
+		}
+		result = append(result, sh)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		// cash balance goes last as storage returns it
+		if result[i].ISIN == "RUB" || result[j].ISIN == "RUB" {
+			return result[j].ISIN == "RUB" && result[i].ISIN != "RUB"
+		}
+		return result[i].ISIN < result[j].ISIN
+	})
+	return result, nil
+}
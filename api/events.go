@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/sync/moex"
+)
+
+// SyncEvents sync security events
+// @summary Sync security events
+// @description Sync bond coupons, amortizations, offers and share dividends from MOEX ISS
+// @id sync-events
+// @produce json
+// @success 200 {object} syncJobResponse "Returns id of started sync job"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @tags events
+// @security ApiKeyAuth
+// @router /events/sync [get]
+func SyncEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if moex.IsCalendarSyncing() {
+		writeError(w, http.StatusBadRequest, "Sync already in process")
+		return
+	}
+	id, err := jobs.Run(job.Calendar, r.Header.Get("user"), func(tr *jobs.Tracker) error {
+		return moex.SyncCalendar(tr, &http.Client{Timeout: 30 * time.Second})
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeOk(w, syncJobResponse{JobID: id})
+}
+
+// GetEvents gets security events
+// @summary Get security events
+// @description Get scheduled coupons, amortizations, offers and dividends
+// @id get-events
+// @produce json
+// @param isin query string false "ISIN"
+// @param from query string false "Filter events from this date"
+// @param to query string false "Filter events till this date"
+// @success 200 {array} models.SecurityEvent "Returns security events"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags events
+// @security ApiKeyAuth
+// @router /events [get]
+func GetEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	s := storage.GetStorage()
+	events, err := s.GetSecurityEvents(r.FormValue("isin"), r.FormValue("from"), r.FormValue("to"))
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	writeOk(w, events)
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/sync/csvfile"
+	"github.com/kaseat/pManager/sync/importer"
+)
+
+// maxReportsSize limits total size of uploaded broker reports
+const maxReportsSize = 32 << 20
+
+// ImportReports imports operations from uploaded broker reports
+// @summary Import broker reports
+// @description Parses uploaded broker reports (Sberbank HTML, VTB XLS or XLSX) and returns parsed operations.
+// @description Files are parsed as CSV when column mapping is provided.
+// @description Operations are saved to specified portfolio only when commit flag is set, so the same files can be previewed first.
+// @description Operations already present in portfolio are skipped on commit
+// @id import-reports
+// @accept multipart/form-data
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param files formData file true "Broker report files"
+// @param mapping formData string false "CSV column mapping as JSON, see csvfile.Mapping"
+// @param commit query bool false "Save parsed operations to portfolio"
+// @success 200 {object} importResult "Returns parsed operations"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags operations
+// @security ApiKeyAuth
+// @router /portfolios/{id}/import [post]
+func ImportReports(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	pid := mux.Vars(r)["id"]
+	user := r.Header.Get("user")
+
+	s := storage.GetStorage()
+	canAccess, err := canAccess(s, user, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot modify this portfolio")
+		return
+	}
+
+	commit := false
+	if v := r.URL.Query().Get("commit"); v != "" {
+		commit, err = strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid commit flag. Expected true or false")
+			return
+		}
+	}
+
+	files, err := readReportFiles(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var ops []models.Operation
+	if mapping := r.FormValue("mapping"); mapping != "" {
+		ops, err = parseCSVFiles(files, mapping)
+	} else {
+		ops, err = importer.Parse(files)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := importResult{Committed: commit, Operations: ops}
+	if commit && len(ops) != 0 {
+		ids, err := s.AddOperations(pid, ops)
+		if err != nil {
+			writeStorageError(w, err)
+			return
+		}
+		for _, id := range ids {
+			if id == "" {
+				result.Skipped++
+			} else {
+				result.Added++
+			}
+		}
+	}
+	writeOk(w, result)
+}
+
+// readReportFiles reads content of all files uploaded in "files" form field
+func readReportFiles(r *http.Request) ([]importer.File, error) {
+	err := r.ParseMultipartForm(maxReportsSize)
+	if err != nil {
+		return nil, err
+	}
+	headers := r.MultipartForm.File["files"]
+	if len(headers) == 0 {
+		return nil, errors.New(`You must upload at least one file in "files" field`)
+	}
+	files := make([]importer.File, 0, len(headers))
+	for _, h := range headers {
+		f, err := h.Open()
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, importer.File{Name: h.Filename, Data: b})
+	}
+	return files, nil
+}
+
+// parseCSVFiles parses uploaded CSV files using given JSON column mapping
+func parseCSVFiles(files []importer.File, mapping string) ([]models.Operation, error) {
+	var m csvfile.Mapping
+	err := json.Unmarshal([]byte(mapping), &m)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid mapping: %v", err)
+	}
+	ops := []models.Operation{}
+	for _, f := range files {
+		fileOps, err := csvfile.Read(bytes.NewReader(f.Data), m)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse '%s': %v", f.Name, err)
+		}
+		ops = append(ops, fileOps...)
+	}
+	sort.Sort(models.OperationSorter(ops))
+	return ops, nil
+}
+
+// ExportOperations exports operations of specified portfolio to CSV
+// @summary Export operations
+// @description Exports operations of specified portfolio to CSV file.
+// @description Default mapping exports all operation fields so that the file can be imported back without losses
+// @id operation-export
+// @produce text/csv
+// @param id path string true "Portfolio Id"
+// @param ticker query string false "Filter by ticker"
+// @param from query string false "Filter operations from this date"
+// @param to query string false "Filter operations till this date"
+// @param mapping query string false "CSV column mapping as JSON, see csvfile.Mapping"
+// @success 200 {string} string "Returns CSV file"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags operations
+// @security ApiKeyAuth
+// @router /portfolios/{id}/operations/export [get]
+func ExportOperations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	pid := mux.Vars(r)["id"]
+	user := r.Header.Get("user")
+
+	s := storage.GetStorage()
+	canAccess, err := canAccess(s, user, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot read operations for this portfolio")
+		return
+	}
+
+	m := csvfile.DefaultMapping()
+	if mapping := r.FormValue("mapping"); mapping != "" {
+		m = csvfile.Mapping{}
+		err = json.Unmarshal([]byte(mapping), &m)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid mapping: %v", err))
+			return
+		}
+	}
+	err = m.Validate()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ops, err := s.GetOperations(pid, "ticker", r.FormValue("ticker"), r.FormValue("from"), r.FormValue("to"))
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	sort.Sort(models.OperationSorter(ops))
+
+	var buf bytes.Buffer
+	err = csvfile.Write(&buf, ops, m)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="operations.csv"`)
+	w.Write(buf.Bytes())
+}
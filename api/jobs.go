@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/kaseat/pManager/storage"
+)
+
+// GetJob gets sync job state
+// @summary Get sync job
+// @description Gets progress, per-item errors and final state of sync job
+// @id get-job
+// @produce json
+// @param id path string true "Job Id"
+// @success 200 {object} models.Job "Returns job state"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when job not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags jobs
+// @security ApiKeyAuth
+// @router /jobs/{id} [get]
+func GetJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user := r.Header.Get("user")
+	id := mux.Vars(r)["id"]
+
+	s := storage.GetStorage()
+	j, err := s.GetJob(id)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	// jobs started by the scheduler have no user and are visible for everyone
+	if j.Login != "" && j.Login != user {
+		writeError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	writeOk(w, j)
+}
@@ -2,13 +2,22 @@ package api
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/kaseat/pManager/gmail"
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
 	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/models/provider"
 	"github.com/kaseat/pManager/storage"
 	"github.com/kaseat/pManager/sync/sberbank"
+	"github.com/kaseat/pManager/sync/tcs"
+	"github.com/kaseat/pManager/sync/vtb"
 	"github.com/kaseat/pManager/utils"
 )
 
@@ -22,6 +31,9 @@ import (
 // @success 200 {array} getAverageSuccess "Returns average price of given ticker"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags misc
 // @security ApiKeyAuth
 // @router /portfolios/{id}/average [get]
@@ -35,11 +47,11 @@ func GetAveragePrice(w http.ResponseWriter, r *http.Request) {
 
 	canAccess, err := canAccess(s, user, pid)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 	if !canAccess {
-		writeError(w, http.StatusUnauthorized, "You cannot get operations from this portfolio")
+		writeError(w, http.StatusForbidden, "You cannot get operations from this portfolio")
 		return
 	}
 
@@ -49,11 +61,31 @@ func GetAveragePrice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ops, err := s.GetOperations(pid, "ticker", ticker, "", "")
+	all, err := getAdjustedOperations(s, pid)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
+		return
+	}
+	tickers, err := getTickers(s)
+	if err != nil {
+		writeStorageError(w, err)
 		return
 	}
+	// restated operations may keep ticker of security before rename
+	ops := []models.Operation{}
+	for _, op := range all {
+		switch op.OperationType {
+		case operation.Buy, operation.Sell, operation.Buyback:
+		default:
+			continue
+		}
+		if t, ok := tickers[op.ISIN]; ok {
+			op.Ticker = t
+		}
+		if op.Ticker == ticker {
+			ops = append(ops, op)
+		}
+	}
 
 	avg := utils.GetAverage(ops)
 
@@ -62,15 +94,19 @@ func GetAveragePrice(w http.ResponseWriter, r *http.Request) {
 
 // GetBalance returns balance of specified currency
 // @summary Get balance
-// @description Gets balance of given currency
+// @description Gets balance of given currency. With report currency balances of all currencies are consolidated
 // @id get-balance
 // @produce json
 // @param id path string true "Portfolio Id"
-// @param currency query string true "Currency"
+// @param currency query string false "Currency, required without report currency"
+// @param reportCurrency query string false "Convert balance to this currency at the rate of 'on' date"
 // @param on query string false "On date"
 // @success 200 {array} getBalanceSuccess "Returns balance of given currency"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags misc
 // @security ApiKeyAuth
 // @router /portfolios/{id}/balance [get]
@@ -81,13 +117,19 @@ func GetBalance(w http.ResponseWriter, r *http.Request) {
 	user := r.Header.Get("user")
 	on := r.FormValue("on")
 
+	reportCurr, err := parseReportCurrency(r.FormValue("reportCurrency"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	curr := currency.Type(r.FormValue("currency"))
-	if curr == "" {
+	if curr == "" && reportCurr == "" {
 		writeError(w, http.StatusBadRequest, fmt.Sprint("You must provide 'currency' parameter"))
 		return
 	}
 
-	if !(curr == currency.EUR || curr == currency.RUB || curr == currency.USD) {
+	if curr != "" && !(curr == currency.EUR || curr == currency.RUB || curr == currency.USD) {
 		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown currency '%s'. Expected '%s', '%s' or '%s'",
 			curr, currency.EUR, currency.RUB, currency.USD))
 		return
@@ -97,22 +139,53 @@ func GetBalance(w http.ResponseWriter, r *http.Request) {
 
 	canAccess, err := canAccess(s, user, pid)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 	if !canAccess {
-		writeError(w, http.StatusUnauthorized, "You cannot get operations from this portfolio")
+		writeError(w, http.StatusForbidden, "You cannot get operations from this portfolio")
 		return
 	}
+
+	if reportCurr == "" {
+		ops, err := s.GetOperations(pid, "currency", string(curr), "", on)
+		if err != nil {
+			writeStorageError(w, err)
+			return
+		}
+
+		bal := utils.GetSum(ops)
+
+		writeOk(w, getBalanceSuccess{Balance: bal})
+		return
+	}
+
 	ops, err := s.GetOperations(pid, "currency", string(curr), "", on)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	date := today()
+	if t, err := time.Parse("2006-01-02T15:04:05Z07:00", on); err == nil {
+		date = t
+	}
+	conv, err := loadConverter(s, date)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	bal := utils.GetSum(ops)
+	total := float64(0)
+	for _, cash := range getCashBalances(ops) {
+		v, err := conv.Convert(cash.Amount, cash.Currency, reportCurr, date)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		total += v
+	}
 
-	writeOk(w, getBalanceSuccess{Balance: bal})
+	writeOk(w, getBalanceSuccess{Balance: math.Round(total*100) / 100, Currency: string(reportCurr)})
 }
 
 // AddGoogleAuth adds gmail support for import operations
@@ -161,15 +234,20 @@ func AppCallback(w http.ResponseWriter, r *http.Request) {
 
 // SyncOperations sync operations
 // @summary Sync operations
-// @description Sync operations for given portfolio
+// @description Sync operations for given portfolio from Sberbank or VTB reports via Gmail or from Tinkoff Invest API
 // @id sync-op
 // @produce json
 // @param id path string true "Portfolio Id"
+// @param provider query string false "Operations provider: sber (default), vtb or tcs"
+// @param account query string false "Tinkoff broker account Id. Default account used if not specified"
 // @param from query string false "Filter operations from this date"
 // @param to query string false "Filter operations till this date"
-// @success 200 {array} commonResponse "Returns success status"
+// @success 200 {object} syncJobResponse "Returns id of started sync job"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags misc
 // @security ApiKeyAuth
 // @router /portfolios/{id}/sync [get]
@@ -178,7 +256,41 @@ func SyncOperations(w http.ResponseWriter, r *http.Request) {
 	pid := mux.Vars(r)["id"]
 	login := r.Header.Get("user")
 
-	go sberbank.SyncGmail(login, pid, r.FormValue("from"), r.FormValue("to"))
+	canAccess, err := canAccess(storage.GetStorage(), login, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot sync operations to this portfolio")
+		return
+	}
+
+	from := r.FormValue("from")
+	to := r.FormValue("to")
 
-	writeOk(w, commonResponse{Status: "ok"})
+	var id string
+	switch provider.Type(r.FormValue("provider")) {
+	case "", provider.Sber:
+		id, err = jobs.Run(job.Gmail, login, func(tr *jobs.Tracker) error {
+			return sberbank.SyncGmail(tr, login, pid, from, to)
+		})
+	case provider.Vtb:
+		id, err = jobs.Run(job.Gmail, login, func(tr *jobs.Tracker) error {
+			return vtb.SyncGmail(tr, login, pid, from, to)
+		})
+	case provider.Tcs:
+		account := r.FormValue("account")
+		id, err = jobs.Run(job.Tcs, login, func(tr *jobs.Tracker) error {
+			return tcs.SyncOperations(tr, login, pid, account, from, to)
+		})
+	default:
+		writeError(w, http.StatusBadRequest, "Unsupported provider. Expected one of: sber, vtb, tcs")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeOk(w, syncJobResponse{JobID: id})
 }
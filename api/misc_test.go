@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/storage"
+)
+
+// addTestPortfolio creates user with portfolio in memory storage
+func addTestPortfolio(login string) (storage.Db, string) {
+	storage.SwitchStorage(storage.Memory)
+	s := storage.GetStorage()
+	uid, _ := s.AddUser(login, login+"@test.com", "hash")
+	pid, _ := s.AddPortfolio(uid, models.Portfolio{Name: "test"})
+	return s, pid
+}
+
+// newTestRequest makes request of given user with given route variables
+func newTestRequest(method, target, login string, body io.Reader, vars map[string]string) *http.Request {
+	r := httptest.NewRequest(method, target, body)
+	r.Header.Set("user", login)
+	return mux.SetURLVars(r, vars)
+}
+
+func TestGetAveragePriceAfterBuyback(t *testing.T) {
+	login := "average_test"
+	s, pid := addTestPortfolio(login)
+	defer s.DeleteUser(login)
+
+	date := time.Date(2020, 3, 4, 9, 0, 0, 0, time.UTC)
+	s.AddOperations(pid, []models.Operation{
+		{Currency: currency.RUB, ISIN: "RU000A101FA1", Ticker: "RU000A101FA1", DateTime: date, OperationType: operation.Buy, Price: 990, Volume: 10},
+		{Currency: currency.RUB, ISIN: "RU000A101FA1", Ticker: "RU000A101FA1", DateTime: date.AddDate(0, 6, 0), OperationType: operation.Buyback, Price: 1000, Volume: 10},
+	})
+
+	w := httptest.NewRecorder()
+	GetAveragePrice(w, newTestRequest("GET", "/portfolios/"+pid+"/average?ticker=RU000A101FA1", login, nil, map[string]string{"id": pid}))
+	var res getAverageSuccess
+	json.NewDecoder(w.Body).Decode(&res)
+	if w.Code == http.StatusOK && res.Average == 0 {
+		t.Logf("Success! Expected zero average after buyback, got %v", res.Average)
+	} else {
+		t.Errorf("Fail! Expected zero average after buyback, got %v %v", w.Code, res.Average)
+	}
+}
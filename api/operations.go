@@ -7,6 +7,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/kaseat/pManager/models"
 	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/validation"
 )
 
 // CreateSingleOperation creates single operation
@@ -19,7 +20,11 @@ import (
 // @param portfolio body operationRequest true "Operation info"
 // @success 200 {object} addOperationSuccess "Returns portfolio Id just created"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 422 {object} validationErrorResponse "Returns every invalid field of input"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags operations
 // @security ApiKeyAuth
 // @router /portfolios/{id}/operations [post]
@@ -37,17 +42,22 @@ func CreateSingleOperation(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	err = validation.Operation(op)
+	if err != nil {
+		writeValidationError(w, err)
+		return
+	}
 
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
 	ps, err := s.GetPortfolios(u.UserID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -60,13 +70,13 @@ func CreateSingleOperation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !canEdit {
-		writeError(w, http.StatusUnauthorized, "You cannot modify this portfolio")
+		writeError(w, http.StatusForbidden, "You cannot modify this portfolio")
 		return
 	}
 
 	oid, err := s.AddOperation(pid, op)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -79,12 +89,24 @@ func CreateSingleOperation(w http.ResponseWriter, r *http.Request) {
 // @id operation-get-all
 // @produce json
 // @param id path string true "Portfolio Id"
-// @param ticker query string false "Filter by ticker"
+// @param type query string false "Filter by operation types, comma separated"
+// @param currency query string false "Filter by currencies, comma separated"
+// @param ticker query string false "Filter by tickers, comma separated"
+// @param isin query string false "Filter by ISINs, comma separated"
+// @param figi query string false "Filter by FIGIs, comma separated"
 // @param from query string false "Filter operations from this date"
 // @param to query string false "Filter operations till this date"
+// @param sort query string false "Sort keys, comma separated. Prefix key with minus to sort descending" example(-date)
+// @param limit query int false "Max number of returned operations"
+// @param offset query int false "Number of operations to skip"
 // @success 200 {array} models.Operation "Returns operations info"
+// @header 200 {integer} X-Total-Count "Total number of matched operations"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 422 {object} errorResponse "Returns when filter or sort key is unknown"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags operations
 // @security ApiKeyAuth
 // @router /portfolios/{id}/operations [get]
@@ -97,13 +119,13 @@ func ReadOperations(w http.ResponseWriter, r *http.Request) {
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
 	ps, err := s.GetPortfolios(u.UserID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -116,16 +138,164 @@ func ReadOperations(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !canRead {
-		writeError(w, http.StatusUnauthorized, "You cannot read operations for this portfolio")
+		writeError(w, http.StatusForbidden, "You cannot read operations for this portfolio")
+		return
+	}
+
+	q, err := parseListQuery(r, "type", "currency", "ticker", "isin", "figi")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ops, total, err := s.FindOperations(pid, q)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	writeList(w, ops, total)
+}
+
+// ReadSingleOperation gets single operation by id
+// @summary Get operation by Id
+// @description Gets operation info by Id
+// @id operation-get-by-id
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param opId path string true "Operation Id"
+// @success 200 {object} models.Operation "Returns operation info"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags operations
+// @security ApiKeyAuth
+// @router /portfolios/{id}/operations/{opId} [get]
+func ReadSingleOperation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	user := r.Header.Get("user")
+
+	s := storage.GetStorage()
+	ok, err := canAccess(s, user, vars["id"])
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusForbidden, "You cannot read operations for this portfolio")
+		return
+	}
+
+	op, err := s.GetOperation(vars["id"], vars["opId"])
+	if err != nil {
+		writeStorageError(w, err)
 		return
 	}
+	writeOk(w, op)
+}
+
+// UpdateSingleOperation updates single operation
+// @summary Update operation
+// @description Replaces operation info by Id
+// @id operation-update
+// @accept json
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param opId path string true "Operation Id"
+// @param operation body operationRequest true "Operation info"
+// @success 200 {object} putOperationSuccess "Returns whether operation was modified"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 422 {object} validationErrorResponse "Returns every invalid field of input"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags operations
+// @security ApiKeyAuth
+// @router /portfolios/{id}/operations/{opId} [put]
+func UpdateSingleOperation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	user := r.Header.Get("user")
+
+	decoder := json.NewDecoder(r.Body)
+	var op models.Operation
 
-	ops, err := s.GetOperations(pid, "ticker", r.FormValue("ticker"), r.FormValue("from"), r.FormValue("to"))
+	err := decoder.Decode(&op)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	writeOk(w, ops)
+	err = validation.Operation(op)
+	if err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	s := storage.GetStorage()
+	ok, err := canAccess(s, user, vars["id"])
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusForbidden, "You cannot modify this portfolio")
+		return
+	}
+
+	modified, err := s.UpdateOperation(vars["id"], vars["opId"], op)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	writeOk(w, putOperationSuccess{HasModified: modified})
+}
+
+// DeleteSingleOperation removes single operation
+// @summary Delete operation
+// @description Deletes operation by Id
+// @id operation-del
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param opId path string true "Operation Id"
+// @success 200 {object} delOperationSuccess "Returns whether operation was deleted"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags operations
+// @security ApiKeyAuth
+// @router /portfolios/{id}/operations/{opId} [delete]
+func DeleteSingleOperation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	user := r.Header.Get("user")
+
+	s := storage.GetStorage()
+	ok, err := canAccess(s, user, vars["id"])
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusForbidden, "You cannot delete operations from this portfolio")
+		return
+	}
+
+	deleted, err := s.DeleteOperation(vars["id"], vars["opId"])
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	writeOk(w, delOperationSuccess{HasDeleted: deleted})
 }
 
 // DeleteAllOperations removes all operations of given portfolio
@@ -137,6 +307,9 @@ func ReadOperations(w http.ResponseWriter, r *http.Request) {
 // @success 200 {array} delMutileSuccess "Returns number of deleted items"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags operations
 // @security ApiKeyAuth
 // @router /portfolios/{id}/operations [delete]
@@ -149,13 +322,13 @@ func DeleteAllOperations(w http.ResponseWriter, r *http.Request) {
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
 	ps, err := s.GetPortfolios(u.UserID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -168,13 +341,13 @@ func DeleteAllOperations(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !canDel {
-		writeError(w, http.StatusUnauthorized, "You cannot delete operations from this portfolio")
+		writeError(w, http.StatusForbidden, "You cannot delete operations from this portfolio")
 		return
 	}
 
 	num, err := s.DeleteOperations(pid)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
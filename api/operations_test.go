@@ -0,0 +1,52 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+func TestUpdateSingleOperationNotFound(t *testing.T) {
+	login := "update_op_test"
+	s, pid := addTestPortfolio(login)
+	defer s.DeleteUser(login)
+
+	body, _ := json.Marshal(models.Operation{
+		Currency:      currency.RUB,
+		Ticker:        "SBER",
+		DateTime:      time.Date(2020, 3, 4, 9, 0, 0, 0, time.UTC),
+		OperationType: operation.Buy,
+		Price:         250,
+		Volume:        10,
+	})
+	vars := map[string]string{"id": pid, "opId": "unknown"}
+	w := httptest.NewRecorder()
+	UpdateSingleOperation(w, newTestRequest("PUT", "/portfolios/"+pid+"/operations/unknown", login, bytes.NewReader(body), vars))
+	if w.Code == http.StatusNotFound {
+		t.Logf("Success! Expected '%d' got '%d'", http.StatusNotFound, w.Code)
+	} else {
+		t.Errorf("Fail! Expected '%d' got '%d': %s", http.StatusNotFound, w.Code, w.Body)
+	}
+}
+
+func TestDeleteSingleOperationNotFound(t *testing.T) {
+	login := "delete_op_test"
+	s, pid := addTestPortfolio(login)
+	defer s.DeleteUser(login)
+
+	vars := map[string]string{"id": pid, "opId": "unknown"}
+	w := httptest.NewRecorder()
+	DeleteSingleOperation(w, newTestRequest("DELETE", "/portfolios/"+pid+"/operations/unknown", login, nil, vars))
+	if w.Code == http.StatusNotFound {
+		t.Logf("Success! Expected '%d' got '%d'", http.StatusNotFound, w.Code)
+	} else {
+		t.Errorf("Fail! Expected '%d' got '%d': %s", http.StatusNotFound, w.Code, w.Body)
+	}
+}
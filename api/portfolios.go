@@ -20,6 +20,8 @@ import (
 // @success 200 {object} addPortfoliioSuccess "Returns portfolio Id just created"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags portfolios
 // @security ApiKeyAuth
 // @router /portfolios [post]
@@ -36,7 +38,7 @@ func CreateSinglePortfolio(w http.ResponseWriter, r *http.Request) {
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -54,7 +56,7 @@ func CreateSinglePortfolio(w http.ResponseWriter, r *http.Request) {
 
 	pid, err := s.AddPortfolio(u.UserID, p)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -70,6 +72,8 @@ func CreateSinglePortfolio(w http.ResponseWriter, r *http.Request) {
 // @success 200 {object} models.Portfolio "Returns portfolio info if any"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags portfolios
 // @security ApiKeyAuth
 // @router /portfolios/{id} [get]
@@ -82,13 +86,13 @@ func ReadSinglePortfolio(w http.ResponseWriter, r *http.Request) {
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
 	p, err := s.GetPortfolio(u.UserID, id)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -103,6 +107,8 @@ func ReadSinglePortfolio(w http.ResponseWriter, r *http.Request) {
 // @success 200 {array} models.Portfolio "Returns portfolio info"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags portfolios
 // @security ApiKeyAuth
 // @router /portfolios [get]
@@ -114,13 +120,13 @@ func ReadAllPortfolios(w http.ResponseWriter, r *http.Request) {
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
 	ps, err := s.GetPortfolios(u.UserID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -138,6 +144,8 @@ func ReadAllPortfolios(w http.ResponseWriter, r *http.Request) {
 // @success 200 {object} putPortfoliioSuccess "Returns portfolio info if any"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags portfolios
 // @security ApiKeyAuth
 // @router /portfolios/{id} [put]
@@ -164,13 +172,13 @@ func UptateSinglePortfolio(w http.ResponseWriter, r *http.Request) {
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
 	modified, err := s.UpdatePortfolio(u.UserID, pid, p)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -186,6 +194,8 @@ func UptateSinglePortfolio(w http.ResponseWriter, r *http.Request) {
 // @success 200 {object} delPortfoliioSuccess "Returns true if portfolio has deleted"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags portfolios
 // @security ApiKeyAuth
 // @router /portfolios/{id} [delete]
@@ -197,13 +207,13 @@ func DeleteSinglePortfolio(w http.ResponseWriter, r *http.Request) {
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
 	deleted, err := s.DeletePortfolio(u.UserID, id)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -218,6 +228,8 @@ func DeleteSinglePortfolio(w http.ResponseWriter, r *http.Request) {
 // @success 200 {object} delPortfoliioSuccess "Returns true if portfolios has deleted"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags portfolios
 // @security ApiKeyAuth
 // @router /portfolios [delete]
@@ -229,13 +241,13 @@ func DeleteAllPortfolios(w http.ResponseWriter, r *http.Request) {
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
 	num, err := s.DeletePortfolios(u.UserID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
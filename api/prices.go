@@ -6,17 +6,19 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/kaseat/pManager/jobs"
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
 	"github.com/kaseat/pManager/storage"
-	"github.com/kaseat/pManager/sync/tcs"
+	"github.com/kaseat/pManager/sync/prices"
 )
 
 // SyncPrices sync prices
 // @summary Sync prices
-// @description Sync prices
+// @description Sync prices of all instruments using price provider of instrument exchange
 // @id sync-price
 // @produce json
-// @success 200 {array} commonResponse "Returns success status"
+// @success 200 {object} syncJobResponse "Returns id of started sync job"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
 // @tags prices
@@ -24,8 +26,18 @@ import (
 // @router /prices/sync [get]
 func SyncPrices(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	go tcs.SyncPrices()
-	writeOk(w, commonResponse{Status: "ok"})
+	if prices.IsSyncing() {
+		writeError(w, http.StatusBadRequest, "Sync already in process")
+		return
+	}
+	id, err := jobs.Run(job.Prices, r.Header.Get("user"), func(tr *jobs.Tracker) error {
+		return prices.Sync(tr, &http.Client{Timeout: 30 * time.Second})
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeOk(w, syncJobResponse{JobID: id})
 }
 
 // GetPrices gets prices
@@ -33,29 +45,39 @@ func SyncPrices(w http.ResponseWriter, r *http.Request) {
 // @description Get prices
 // @id get-price
 // @produce json
-// @param isin query string false "ISIN"
+// @param isin query string false "Filter by ISINs, comma separated"
+// @param ticker query string false "Filter by tickers, comma separated"
+// @param figi query string false "Filter by FIGIs, comma separated"
 // @param from query string false "Filter prices from this date"
 // @param to query string false "Filter prices till this date"
-// @success 200 {array} commonResponse "Returns success status"
+// @param sort query string false "Sort keys, comma separated. Prefix key with minus to sort descending" example(-date)
+// @param limit query int false "Max number of returned prices"
+// @param offset query int false "Number of prices to skip"
+// @success 200 {array} models.Price "Returns prices"
+// @header 200 {integer} X-Total-Count "Total number of matched prices"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 422 {object} errorResponse "Returns when filter or sort key is unknown"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags prices
 // @security ApiKeyAuth
 // @router /prices [get]
 func GetPrices(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	s := storage.GetStorage()
-	isin := r.FormValue("isin")
-	from := r.FormValue("from")
-	to := r.FormValue("to")
-
-	prices, err := s.GetPricesByIsin(isin, from, to)
+	q, err := parseListQuery(r, "isin", "ticker", "figi")
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	writeOk(w, prices)
+
+	prices, total, err := storage.GetStorage().FindPrices(q)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	writeList(w, prices, total)
 }
 
 // AddPrices gets prices
@@ -68,6 +90,8 @@ func GetPrices(w http.ResponseWriter, r *http.Request) {
 // @success 200 {array} commonResponse "Returns success status"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags prices
 // @security ApiKeyAuth
 // @router /prices [post]
@@ -100,7 +124,7 @@ func AddPrices(w http.ResponseWriter, r *http.Request) {
 
 	err = s.AddPrices(prices)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 	writeOk(w, commonResponse{Status: "ok"})
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/sync/cbr"
+)
+
+// SyncRates sync currency rates
+// @summary Sync currency rates
+// @description Sync currency rates from Central Bank of Russia
+// @id sync-rates
+// @produce json
+// @success 200 {object} syncJobResponse "Returns id of started sync job"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @tags rates
+// @security ApiKeyAuth
+// @router /rates/sync [get]
+func SyncRates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if cbr.IsSyncing() {
+		writeError(w, http.StatusBadRequest, "Sync already in process")
+		return
+	}
+	id, err := jobs.Run(job.Rates, r.Header.Get("user"), func(tr *jobs.Tracker) error {
+		return cbr.Sync(tr, &http.Client{Timeout: 30 * time.Second})
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeOk(w, syncJobResponse{JobID: id})
+}
+
+// GetRates gets currency rates
+// @summary Get currency rates
+// @description Get currency rates in rubles
+// @id get-rates
+// @produce json
+// @param currency query string false "Currency"
+// @param from query string false "Filter rates from this date"
+// @param to query string false "Filter rates till this date"
+// @success 200 {array} models.Rate "Returns currency rates"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags rates
+// @security ApiKeyAuth
+// @router /rates [get]
+func GetRates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	s := storage.GetStorage()
+	curr := currency.Type(r.FormValue("currency"))
+	from := r.FormValue("from")
+	to := r.FormValue("to")
+
+	rates, err := s.GetRates(curr, from, to)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	writeOk(w, rates)
+}
@@ -0,0 +1,565 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/tax"
+	"github.com/kaseat/pManager/utils"
+)
+
+// GetRealized returns realized profit and loss of specified portfolio
+// @summary Get realized P&L
+// @description Matches sells with buy lots using FIFO and returns realized result of every sell
+// @id get-realized
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param ticker query string false "Filter by ticker"
+// @param from query string false "Filter sells from this date"
+// @param to query string false "Filter sells till this date"
+// @success 200 {array} models.RealizedTrade "Returns realized trades"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags reports
+// @security ApiKeyAuth
+// @router /portfolios/{id}/realized [get]
+func GetRealized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	pid := mux.Vars(r)["id"]
+	user := r.Header.Get("user")
+	ticker := r.FormValue("ticker")
+
+	from, to, err := parseDateRange(r.FormValue("from"), r.FormValue("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s := storage.GetStorage()
+
+	canAccess, err := canAccess(s, user, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot get operations from this portfolio")
+		return
+	}
+
+	// lots are matched over whole history, filters apply to sells only
+	ops, err := getAdjustedOperations(s, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	tickers, err := getTickers(s)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	result := []models.RealizedTrade{}
+	for _, trade := range utils.GetRealized(ops) {
+		if trade.Ticker == "" {
+			trade.Ticker = tickers[trade.ISIN]
+		}
+		if ticker != "" && trade.Ticker != ticker {
+			continue
+		}
+		if !inRange(trade.Date, from, to) {
+			continue
+		}
+		result = append(result, trade)
+	}
+
+	writeOk(w, result)
+}
+
+// GetTaxReport returns personal income tax report of specified portfolio
+// @summary Get NDFL report
+// @description Builds personal income tax (NDFL) report for given year. Amounts are converted to rubles at stored CBR rate of operation date
+// @id get-tax-report
+// @produce json
+// @produce text/csv
+// @param id path string true "Portfolio Id"
+// @param year query int true "Calendar year"
+// @param format query string false "Response format: json (default) or csv"
+// @success 200 {object} models.TaxReport "Returns tax report"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags reports
+// @security ApiKeyAuth
+// @router /portfolios/{id}/tax [get]
+func GetTaxReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	pid := mux.Vars(r)["id"]
+	user := r.Header.Get("user")
+
+	year, err := strconv.Atoi(r.FormValue("year"))
+	if err != nil || year < 1900 {
+		writeError(w, http.StatusBadRequest, "You must provide valid 'year' parameter")
+		return
+	}
+	format := r.FormValue("format")
+	if format != "" && format != "json" && format != "csv" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown format '%s'. Expected 'json' or 'csv'", format))
+		return
+	}
+
+	s := storage.GetStorage()
+
+	canAccess, err := canAccess(s, user, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot get operations from this portfolio")
+		return
+	}
+
+	ops, err := getAdjustedOperations(s, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	rates, err := loadConverter(s, time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	report, err := tax.GetReport(ops, year, rates)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tickers, err := getTickers(s)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	for i := range report.Trades {
+		if report.Trades[i].Ticker == "" {
+			report.Trades[i].Ticker = tickers[report.Trades[i].ISIN]
+		}
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=ndfl_%d.csv", year))
+		tax.WriteCSV(w, report)
+		return
+	}
+	writeOk(w, report)
+}
+
+// GetIncome returns coupon and dividend income of specified portfolio
+// @summary Get income
+// @description Aggregates coupons and dividends by month, security and currency. Gross income is before withholding tax, net income is after it
+// @id get-income
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param ticker query string false "Filter by ticker"
+// @param from query string false "Filter income from this date"
+// @param to query string false "Filter income till this date"
+// @success 200 {array} models.Income "Returns income by month and security"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags reports
+// @security ApiKeyAuth
+// @router /portfolios/{id}/income [get]
+func GetIncome(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	pid := mux.Vars(r)["id"]
+	user := r.Header.Get("user")
+	ticker := r.FormValue("ticker")
+
+	from, to, err := parseDateRange(r.FormValue("from"), r.FormValue("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s := storage.GetStorage()
+
+	canAccess, err := canAccess(s, user, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot get operations from this portfolio")
+		return
+	}
+
+	ops, err := s.GetOperations(pid, "", "", "", "")
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	tickers, err := getTickers(s)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	filtered := []models.Operation{}
+	for _, op := range ops {
+		if t, ok := tickers[op.ISIN]; ok {
+			op.Ticker = t
+		}
+		if ticker != "" && op.Ticker != ticker {
+			continue
+		}
+		if !inRange(op.DateTime, from, to) {
+			continue
+		}
+		filtered = append(filtered, op)
+	}
+
+	writeOk(w, utils.GetIncome(filtered))
+}
+
+// GetCalendar returns expected cash inflows of specified portfolio
+// @summary Get income calendar
+// @description Projects coupons, amortizations, offers and dividends of current holdings.
+// @description Amount is event value per unit multiplied by currently held volume. Offers are shown at offer price
+// @id get-calendar
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param from query string false "Events from this date, today by default"
+// @param to query string false "Events till this date, one year from today by default"
+// @success 200 {array} models.CalendarEntry "Returns expected cash inflows sorted by date"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags reports
+// @security ApiKeyAuth
+// @router /portfolios/{id}/calendar [get]
+func GetCalendar(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	pid := mux.Vars(r)["id"]
+	user := r.Header.Get("user")
+
+	from, to, err := parseDateRange(r.FormValue("from"), r.FormValue("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if from.IsZero() {
+		from = today()
+	}
+	if to.IsZero() {
+		to = today().AddDate(1, 0, 0)
+	}
+
+	s := storage.GetStorage()
+
+	canAccess, err := canAccess(s, user, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot get operations from this portfolio")
+		return
+	}
+
+	ops, err := getAdjustedOperations(s, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	events, err := s.GetSecurityEvents("", from.Format("2006-01-02T15:04:05Z07:00"), to.Format("2006-01-02T15:04:05Z07:00"))
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	tickers, err := getTickers(s)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	result := utils.GetCalendar(ops, events)
+	for i := range result {
+		if t, ok := tickers[result[i].ISIN]; ok {
+			result[i].Ticker = t
+		}
+	}
+	writeOk(w, result)
+}
+
+// GetHistory returns valuation time series of specified portfolio
+// @summary Get valuation history
+// @description Gets market value (securities plus cash), cash balance and invested capital per currency for every point of given interval
+// @id get-history
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param from query string false "First point date, first operation date by default"
+// @param to query string false "Last point date, today by default"
+// @param interval query string false "Distance between points: day (default), week or month"
+// @param reportCurrency query string false "Add totals converted to this currency at the rate of point date"
+// @success 200 {array} models.HistoryPoint "Returns valuation history"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags reports
+// @security ApiKeyAuth
+// @router /portfolios/{id}/history [get]
+func GetHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	pid := mux.Vars(r)["id"]
+	user := r.Header.Get("user")
+	interval := utils.Interval(r.FormValue("interval"))
+	if interval == "" {
+		interval = utils.Day
+	}
+	reportCurr, err := parseReportCurrency(r.FormValue("reportCurrency"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	from, to, err := parseDateRange(r.FormValue("from"), r.FormValue("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s := storage.GetStorage()
+
+	canAccess, err := canAccess(s, user, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot get operations from this portfolio")
+		return
+	}
+
+	ops, err := getAdjustedOperations(s, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if to.IsZero() {
+		to = today()
+	}
+	if from.IsZero() {
+		from = to
+		for _, op := range ops {
+			if op.DateTime.Before(from) {
+				from = op.DateTime
+			}
+		}
+		from = from.UTC().Truncate(24 * time.Hour)
+	}
+
+	dates, err := utils.GetHistoryDates(from, to, interval)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	prices, err := getPrices(s, ops, to)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	history := utils.GetHistory(ops, prices, dates)
+	if reportCurr != "" {
+		conv, err := loadConverter(s, to)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		err = utils.ConsolidateHistory(history, conv, reportCurr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	writeOk(w, history)
+}
+
+// GetPerformance returns return metrics of specified portfolio
+// @summary Get performance
+// @description Gets money-weighted (XIRR) and time-weighted (TWR) return of given currency part of portfolio or of whole portfolio consolidated in report currency
+// @id get-performance
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param currency query string false "Currency, RUB by default"
+// @param reportCurrency query string false "Consolidate whole portfolio in this currency"
+// @param period query string false "Period: ytd, 1y or inception. All periods by default"
+// @success 200 {array} models.Performance "Returns performance for every requested period"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags reports
+// @security ApiKeyAuth
+// @router /portfolios/{id}/performance [get]
+func GetPerformance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	pid := mux.Vars(r)["id"]
+	user := r.Header.Get("user")
+
+	curr := currency.Type(r.FormValue("currency"))
+	if curr == "" {
+		curr = currency.RUB
+	}
+	if !(curr == currency.EUR || curr == currency.RUB || curr == currency.USD) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown currency '%s'. Expected '%s', '%s' or '%s'",
+			curr, currency.EUR, currency.RUB, currency.USD))
+		return
+	}
+	reportCurr, err := parseReportCurrency(r.FormValue("reportCurrency"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	periods := []string{"ytd", "1y", "inception"}
+	if period := r.FormValue("period"); period != "" {
+		if period != "ytd" && period != "1y" && period != "inception" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown period '%s'. Expected 'ytd', '1y' or 'inception'", period))
+			return
+		}
+		periods = []string{period}
+	}
+
+	s := storage.GetStorage()
+
+	canAccess, err := canAccess(s, user, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot get operations from this portfolio")
+		return
+	}
+
+	ops, err := getAdjustedOperations(s, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	to := today()
+	prices, err := getPrices(s, ops, to)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	var conv utils.RateConverter
+	if reportCurr != "" {
+		curr = reportCurr
+		conv, err = loadConverter(s, to)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	inception := to
+	for _, op := range ops {
+		if op.DateTime.Before(inception) {
+			inception = op.DateTime.UTC().Truncate(24 * time.Hour)
+		}
+	}
+
+	result := []models.Performance{}
+	for _, period := range periods {
+		var from time.Time
+		switch period {
+		case "ytd":
+			from = time.Date(to.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		case "1y":
+			from = to.AddDate(-1, 0, 0)
+		case "inception":
+			from = inception
+		}
+		if from.Before(inception) {
+			from = inception
+		}
+		perf, err := utils.GetPerformance(ops, prices, curr, from, to, conv)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		perf.Period = period
+		result = append(result, perf)
+	}
+
+	writeOk(w, result)
+}
+
+// getPrices returns prices of all securities ever traded in given operations keyed by ISIN
+func getPrices(s storage.Db, ops []models.Operation, to time.Time) (map[string][]models.Price, error) {
+	result := make(map[string][]models.Price)
+	for _, op := range ops {
+		switch op.OperationType {
+		case operation.Buy, operation.Sell, operation.Buyback:
+		default:
+			continue
+		}
+		if _, ok := result[op.ISIN]; ok {
+			continue
+		}
+		prices, err := s.GetPricesByIsin(op.ISIN, "", to.AddDate(0, 0, 1).Format("2006-01-02T15:04:05Z07:00"))
+		if err != nil {
+			return nil, err
+		}
+		result[op.ISIN] = prices
+	}
+	return result, nil
+}
+
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// getTickers returns tickers of all known instruments keyed by ISIN
+func getTickers(s storage.Db) (map[string]string, error) {
+	instruments, err := s.GetAllInstruments()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	for _, ins := range instruments {
+		result[ins.ISIN] = ins.Ticker
+	}
+	return result, nil
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/scheduler"
+	"github.com/kaseat/pManager/storage"
+)
+
+// GetSchedules gets background job schedules
+// @summary Get schedules
+// @description Get schedules of background syncs with results of their last runs.
+// @description Returns common schedules and Gmail import schedules of current user
+// @id get-schedules
+// @produce json
+// @success 200 {array} scheduleResponse "Returns schedules"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags schedules
+// @security ApiKeyAuth
+// @router /schedules [get]
+func GetSchedules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user := r.Header.Get("user")
+	s := storage.GetStorage()
+	schedules, err := s.GetSchedules()
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	now := time.Now()
+	result := []scheduleResponse{}
+	for _, sch := range schedules {
+		if sch.Login != "" && sch.Login != user {
+			continue
+		}
+		resp := scheduleResponse{Schedule: sch}
+		if c, err := scheduler.ParseCron(sch.Cron); err == nil && sch.Enabled {
+			if next := c.Next(now); !next.IsZero() {
+				resp.NextRun = &next
+			}
+		}
+		result = append(result, resp)
+	}
+	writeOk(w, result)
+}
+
+// UpdateSchedule updates background job schedule
+// @summary Update schedule
+// @description Updates cron expression of background job schedule and enables or disables it
+// @id put-schedule
+// @accept json
+// @produce json
+// @param id path string true "Schedule Id"
+// @param schedule body scheduleRequest true "Schedule info"
+// @success 200 {object} commonResponse "Returns success status"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when schedule not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags schedules
+// @security ApiKeyAuth
+// @router /schedules/{id} [put]
+func UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req scheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := scheduler.ParseCron(req.Cron); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user := r.Header.Get("user")
+	id := mux.Vars(r)["id"]
+	s := storage.GetStorage()
+	schedules, err := s.GetSchedules()
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	for _, sch := range schedules {
+		if sch.ID != id || (sch.Login != "" && sch.Login != user) {
+			continue
+		}
+		sch.Cron = req.Cron
+		sch.Enabled = req.Enabled
+		if err = s.SaveSchedule(sch); err != nil {
+			writeStorageError(w, err)
+			return
+		}
+		writeOk(w, commonResponse{Status: "ok"})
+		return
+	}
+	writeError(w, http.StatusNotFound, "Schedule not found")
+}
+
+// SetGmailSchedule sets Gmail import schedule
+// @summary Set Gmail import schedule
+// @description Schedules periodic import of Sberbank broker reports from Gmail of current user into given portfolio
+// @id put-gmail-schedule
+// @accept json
+// @produce json
+// @param id path string true "Portfolio Id"
+// @param schedule body scheduleRequest true "Schedule info"
+// @success 200 {object} models.Schedule "Returns saved schedule"
+// @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
+// @tags schedules
+// @security ApiKeyAuth
+// @router /portfolios/{id}/schedule [put]
+func SetGmailSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req scheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := scheduler.ParseCron(req.Cron); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user := r.Header.Get("user")
+	pid := mux.Vars(r)["id"]
+	s := storage.GetStorage()
+
+	canAccess, err := canAccess(s, user, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "You cannot sync operations to this portfolio")
+		return
+	}
+
+	sch := models.Schedule{
+		ID:          scheduler.GmailScheduleID(user),
+		Job:         job.Gmail,
+		Login:       user,
+		PortfolioID: pid,
+	}
+	schedules, err := s.GetSchedules()
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	for _, existing := range schedules {
+		if existing.ID == sch.ID {
+			sch = existing
+			sch.PortfolioID = pid
+		}
+	}
+	sch.Cron = req.Cron
+	sch.Enabled = req.Enabled
+	if err = s.SaveSchedule(sch); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	writeOk(w, sch)
+}
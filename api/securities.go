@@ -2,12 +2,18 @@ package api
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/kaseat/pManager/jobs"
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/job"
 	"github.com/kaseat/pManager/storage"
 	"github.com/kaseat/pManager/sync/tcs"
+	"github.com/kaseat/pManager/validation"
 )
 
 // SyncSecurities syncs securities
@@ -15,7 +21,7 @@ import (
 // @description Sync intruments dimension
 // @id sync-securities
 // @produce json
-// @success 200 {array} commonResponse "Returns success status"
+// @success 200 {object} syncJobResponse "Returns id of started sync job"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
 // @tags securities
@@ -23,13 +29,16 @@ import (
 // @router /securities/sync [get]
 func SyncSecurities(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	stat := tcs.GetSyncInstrumentsStatus()
-	if stat.Status != tcs.Processing {
-		go tcs.SyncInstruments()
-		writeOk(w, commonResponse{Status: "ok"})
-	} else {
+	if tcs.IsSyncingInstruments() {
 		writeError(w, http.StatusBadRequest, "Sync already in process")
+		return
+	}
+	id, err := jobs.Run(job.Instruments, r.Header.Get("user"), tcs.SyncInstruments)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
+	writeOk(w, syncJobResponse{JobID: id})
 }
 
 // GetSecurities gets securities
@@ -39,32 +48,46 @@ func SyncSecurities(w http.ResponseWriter, r *http.Request) {
 // @produce json
 // @param filter query string false "Filter by" Enums(none, ticker, isin, figi) default(none)
 // @param by query string false "Filter value"
-// @success 200 {array} models.Instrument "Returns success status"
+// @param type query string false "Filter by instrument types, comma separated"
+// @param exchange query string false "Filter by exchanges, comma separated"
+// @param currency query string false "Filter by currencies, comma separated"
+// @param isin query string false "Filter by ISINs, comma separated"
+// @param ticker query string false "Filter by tickers, comma separated"
+// @param figi query string false "Filter by FIGIs, comma separated"
+// @param sort query string false "Sort keys, comma separated. Prefix key with minus to sort descending" example(-ticker)
+// @param limit query int false "Max number of returned securities"
+// @param offset query int false "Number of securities to skip"
+// @success 200 {array} models.Instrument "Returns securities"
+// @header 200 {integer} X-Total-Count "Total number of matched securities"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 422 {object} validationErrorResponse "Returns every invalid field of input"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags securities
 // @security ApiKeyAuth
 // @router /securities [get]
 func GetSecurities(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	s := storage.GetStorage()
+	q, err := parseListQuery(r, "type", "exchange", "currency", "isin", "ticker", "figi")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// legacy single value filter
 	filter := r.FormValue("filter")
 	by := r.FormValue("by")
-
-	var err error
-	var ins []models.Instrument
-	if filter == "none" || filter == "" {
-		ins, err = s.GetAllInstruments()
-	} else {
-		ins, err = s.GetInstruments(filter, by)
+	if filter != "none" && filter != "" && by != "" {
+		q.Filters[filter] = append(q.Filters[filter], by)
 	}
 
+	ins, total, err := storage.GetStorage().FindInstruments(q)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
-	writeOk(w, ins)
+	writeList(w, ins, total)
 }
 
 // AddSecurities adds securities
@@ -75,7 +98,10 @@ func GetSecurities(w http.ResponseWriter, r *http.Request) {
 // @param instrument body models.Instrument true "Instrument info"
 // @success 200 {array} models.Instrument "Returns success status"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
+// @failure 422 {object} validationErrorResponse "Returns every invalid field of input"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags securities
 // @security ApiKeyAuth
 // @router /securities [post]
@@ -90,11 +116,16 @@ func AddSecurities(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	err = validation.Instrument(ins)
+	if err != nil {
+		writeValidationError(w, err)
+		return
+	}
 
 	s := storage.GetStorage()
 	err = s.AddInstruments([]models.Instrument{ins})
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 	} else {
 		writeOk(w, commonResponse{Status: "ok"})
 	}
@@ -107,9 +138,13 @@ func AddSecurities(w http.ResponseWriter, r *http.Request) {
 // @produce json
 // @param id path string true "Portfolio Id"
 // @param on query string false "Get securities on this date"
-// @success 200 {object} models.Portfolio "Returns portfolio info if any"
+// @param reportCurrency query string false "Add values converted to this currency at the rate of 'on' date and their total"
+// @success 200 {array} models.Share "Returns securities, or consolidatedShares object when report currency is set"
 // @failure 400 {object} errorResponse "Returns when any processing error occurs"
 // @failure 401 {object} errorResponse "Returns when authentication error occurs"
+// @failure 403 {object} errorResponse "Returns when portfolio belongs to other user"
+// @failure 404 {object} errorResponse "Returns when requested entity is not found"
+// @failure 500 {object} errorResponse "Returns when storage error occurs"
 // @tags securities
 // @security ApiKeyAuth
 // @router /portfolios/{id}/securities [get]
@@ -119,16 +154,22 @@ func GetSecuritiesForPortfolio(w http.ResponseWriter, r *http.Request) {
 	pid := mux.Vars(r)["id"]
 	user := r.Header.Get("user")
 
+	reportCurr, err := parseReportCurrency(r.FormValue("reportCurrency"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	s := storage.GetStorage()
 	u, err := s.GetUserByLogin(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
 	ps, err := s.GetPortfolios(u.UserID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeStorageError(w, err)
 		return
 	}
 
@@ -141,14 +182,88 @@ func GetSecuritiesForPortfolio(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !canRead {
-		writeError(w, http.StatusUnauthorized, "You cannot read securities for this portfolio")
+		writeError(w, http.StatusForbidden, "You cannot read securities for this portfolio")
 		return
 	}
 
 	ops, err := s.GetShares(pid, r.FormValue("on"))
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	on := time.Now()
+	if t, err := time.Parse("2006-01-02T15:04:05Z07:00", r.FormValue("on")); err == nil {
+		on = t
+	}
+	ops, err = adjustShares(s, pid, ops, on)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	if reportCurr == "" {
+		writeOk(w, ops)
+		return
+	}
+
+	date := today()
+	if t, err := time.Parse("2006-01-02T15:04:05Z07:00", r.FormValue("on")); err == nil {
+		date = t
+	}
+	conv, err := loadConverter(s, date)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	writeOk(w, ops)
+	instruments, err := s.GetAllInstruments()
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	currencies := make(map[string]currency.Type)
+	for _, ins := range instruments {
+		currencies[ins.ISIN] = ins.Currency
+	}
+
+	all, err := getAdjustedOperations(s, pid)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	cashOps := []models.Operation{}
+	for _, op := range all {
+		if !op.DateTime.After(on) {
+			cashOps = append(cashOps, op)
+		}
+	}
+
+	items := []consolidatedShare{}
+	amounts := []float64{}
+	for _, sh := range ops {
+		// cash of all currencies is summed up into RUB share,
+		// so it is replaced with balance of every currency
+		if sh.ISIN == "RUB" {
+			continue
+		}
+		items = append(items, consolidatedShare{Share: sh, Currency: currencies[sh.ISIN]})
+		amounts = append(amounts, sh.Price*float64(sh.Volume))
+	}
+	for _, cash := range getCashBalances(cashOps) {
+		sh := models.Share{ISIN: string(cash.Currency), Ticker: string(cash.Currency), Price: cash.Amount, Date: on}
+		items = append(items, consolidatedShare{Share: sh, Currency: cash.Currency})
+		amounts = append(amounts, cash.Amount)
+	}
+
+	result := consolidatedShares{Currency: reportCurr, Shares: []consolidatedShare{}}
+	for i, item := range items {
+		v, err := conv.Convert(amounts[i], item.Currency, reportCurr, date)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		item.Value = math.Round(v*100) / 100
+		result.Total += v
+		result.Shares = append(result.Shares, item)
+	}
+	result.Total = math.Round(result.Total*100) / 100
+	writeOk(w, result)
 }
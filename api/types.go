@@ -1,5 +1,13 @@
 package api
 
+import (
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/validation"
+)
+
 type responseStatus string
 
 const (
@@ -19,6 +27,14 @@ type putPortfoliioSuccess struct {
 	HasModified bool `json:"hasModified" example:"true"`
 }
 
+type putOperationSuccess struct {
+	HasModified bool `json:"hasModified" example:"true"`
+}
+
+type delOperationSuccess struct {
+	HasDeleted bool `json:"hasDeleted" example:"true"`
+}
+
 type commonResponse struct {
 	Status string `json:"status" example:"ok"`
 }
@@ -36,7 +52,20 @@ type gmailAuthURLSuccess struct {
 }
 
 type getBalanceSuccess struct {
-	Balance float64 `json:"balance" example:"42"`
+	Balance  float64 `json:"balance" example:"42"`
+	Currency string  `json:"currency,omitempty" example:"RUB"`
+}
+
+type consolidatedShare struct {
+	models.Share
+	Currency currency.Type `json:"currency" example:"USD"`
+	Value    float64       `json:"value" example:"19561.6"`
+}
+
+type consolidatedShares struct {
+	Currency currency.Type       `json:"currency" example:"RUB"`
+	Total    float64             `json:"total" example:"250000"`
+	Shares   []consolidatedShare `json:"shares"`
 }
 
 type getAverageSuccess struct {
@@ -65,8 +94,39 @@ type priceRequest struct {
 	Volume int     `json:"vol" example:"100"`
 }
 
+type importResult struct {
+	Committed bool `json:"committed" example:"false"`
+	// Added and Skipped count committed operations,
+	// operations already present in portfolio are skipped
+	Added      int                `json:"added" example:"12"`
+	Skipped    int                `json:"skipped" example:"3"`
+	Operations []models.Operation `json:"operations"`
+}
+
+type syncJobResponse struct {
+	JobID string `json:"jobId" example:"42"`
+}
+
+type scheduleRequest struct {
+	Cron    string `json:"cron" example:"0 3 * * *"`
+	Enabled bool   `json:"enabled" example:"true"`
+}
+
+type scheduleResponse struct {
+	models.Schedule
+	NextRun *time.Time `json:"nextRun,omitempty" example:"2020-06-07T03:00:00Z"`
+}
+
 type errorResponse struct {
 	Error string `json:"error" example:"Something went wrong"`
+	// Code is one of badRequest, unauthorized, forbidden, notFound, conflict, validation, internal
+	Code string `json:"code" example:"badRequest"`
+}
+
+type validationErrorResponse struct {
+	Error  string                  `json:"error" example:"Validation failed"`
+	Code   string                  `json:"code" example:"validation"`
+	Fields []validation.FieldError `json:"fields"`
 }
 
 type user struct {
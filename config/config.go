@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config represents application configuration
+type Config struct {
+	Server  Server  `yaml:"server" json:"server"`
+	Storage Storage `yaml:"storage" json:"storage"`
+	Auth    Auth    `yaml:"auth" json:"auth"`
+	Gmail   Gmail   `yaml:"gmail" json:"gmail"`
+	Sync    Sync    `yaml:"sync" json:"sync"`
+}
+
+// Server represents http server configuration
+type Server struct {
+	Addr        string `yaml:"addr" json:"addr"`
+	SwaggerHost string `yaml:"swaggerHost" json:"swaggerHost"`
+}
+
+// Storage represents storage configuration
+type Storage struct {
+	Type     string   `yaml:"type" json:"type"`
+	Postgres Postgres `yaml:"postgres" json:"postgres"`
+	Mongo    Mongo    `yaml:"mongo" json:"mongo"`
+	Sqlite   Sqlite   `yaml:"sqlite" json:"sqlite"`
+}
+
+// Postgres represents PostgreSQL storage configuration
+type Postgres struct {
+	ConnString string `yaml:"connString" json:"connString"`
+}
+
+// Mongo represents MongoDB storage configuration
+type Mongo struct {
+	URL    string `yaml:"url" json:"url"`
+	DbName string `yaml:"dbName" json:"dbName"`
+}
+
+// Sqlite represents SQLite storage configuration
+type Sqlite struct {
+	Path string `yaml:"path" json:"path"`
+}
+
+// Auth represents authentication configuration
+type Auth struct {
+	Secret string `yaml:"secret" json:"secret"`
+}
+
+// Gmail represents Gmail client configuration
+type Gmail struct {
+	CredentialsPath string `yaml:"credentialsPath" json:"credentialsPath"`
+}
+
+// Sync represents configuration of sync providers
+type Sync struct {
+	Tcs   Provider `yaml:"tcs" json:"tcs"`
+	Moex  Provider `yaml:"moex" json:"moex"`
+	Spbex Provider `yaml:"spbex" json:"spbex"`
+	Cbr   Provider `yaml:"cbr" json:"cbr"`
+}
+
+// Provider represents configuration of single sync provider
+type Provider struct {
+	URL string `yaml:"url" json:"url"`
+}
+
+// Default returns configuration used when no file nor environment variable is set
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:        ":8081",
+			SwaggerHost: "totallink.ru",
+		},
+		Storage: Storage{
+			Type: "postgres",
+			Postgres: Postgres{
+				ConnString: "host=localhost port=5432 dbname=p_manager user=test password=test",
+			},
+			Mongo: Mongo{
+				URL:    "mongodb://localhost:27017",
+				DbName: "p_manager",
+			},
+			Sqlite: Sqlite{
+				Path: "p_manager.db",
+			},
+		},
+		Auth: Auth{
+			Secret: "my_secret_key",
+		},
+		Gmail: Gmail{
+			CredentialsPath: "credentials.json",
+		},
+		Sync: Sync{
+			Tcs:   Provider{URL: "https://api-invest.tinkoff.ru/openapi/sandbox"},
+			Moex:  Provider{URL: "https://iss.moex.com/iss"},
+			Spbex: Provider{URL: "https://investcab.ru/api"},
+			Cbr:   Provider{URL: "https://www.cbr.ru/scripts"},
+		},
+	}
+}
+
+// Load reads configuration from YAML or JSON file and applies environment
+// overrides on top of it. Empty path means defaults with environment overrides
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		if strings.ToLower(filepath.Ext(path)) == ".json" {
+			err = json.Unmarshal(b, &cfg)
+		} else {
+			err = yaml.Unmarshal(b, &cfg)
+		}
+		if err != nil {
+			return cfg, err
+		}
+	}
+	applyEnv(&cfg)
+	return cfg, nil
+}
+
+// environment variables which override values from file
+var env = map[string]func(c *Config) *string{
+	"PM_ADDR":              func(c *Config) *string { return &c.Server.Addr },
+	"PM_SWAGGER_HOST":      func(c *Config) *string { return &c.Server.SwaggerHost },
+	"PM_STORAGE":           func(c *Config) *string { return &c.Storage.Type },
+	"PM_POSTGRES_CONN":     func(c *Config) *string { return &c.Storage.Postgres.ConnString },
+	"PM_MONGO_URL":         func(c *Config) *string { return &c.Storage.Mongo.URL },
+	"PM_MONGO_DB":          func(c *Config) *string { return &c.Storage.Mongo.DbName },
+	"PM_SQLITE_PATH":       func(c *Config) *string { return &c.Storage.Sqlite.Path },
+	"PM_JWT_SECRET":        func(c *Config) *string { return &c.Auth.Secret },
+	"PM_GMAIL_CREDENTIALS": func(c *Config) *string { return &c.Gmail.CredentialsPath },
+	"PM_TCS_URL":           func(c *Config) *string { return &c.Sync.Tcs.URL },
+	"PM_MOEX_URL":          func(c *Config) *string { return &c.Sync.Moex.URL },
+	"PM_SPBEX_URL":         func(c *Config) *string { return &c.Sync.Spbex.URL },
+	"PM_CBR_URL":           func(c *Config) *string { return &c.Sync.Cbr.URL },
+}
+
+func applyEnv(c *Config) {
+	for name, field := range env {
+		if v, ok := os.LookupEnv(name); ok {
+			*field(c) = v
+		}
+	}
+}
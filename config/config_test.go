@@ -0,0 +1,68 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDefault(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Errorf("Fail! Error during load config: %v", err)
+	}
+	if cfg.Server.Addr == ":8081" {
+		t.Logf("Success! Expected %v, got %v", ":8081", cfg.Server.Addr)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", ":8081", cfg.Server.Addr)
+	}
+}
+
+func TestLoadYamlAndJSON(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "config")
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"config.yaml": "server:\n  addr: \":9090\"\nstorage:\n  type: sqlite\n",
+		"config.json": `{"server":{"addr":":9090"},"storage":{"type":"sqlite"}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		ioutil.WriteFile(path, []byte(content), 0600)
+		cfg, err := Load(path)
+		if err != nil {
+			t.Errorf("Fail! Error during load %s: %v", name, err)
+			continue
+		}
+		if cfg.Server.Addr == ":9090" && cfg.Storage.Type == "sqlite" {
+			t.Logf("Success! Expected %v, got %v", ":9090 sqlite", cfg.Server.Addr+" "+cfg.Storage.Type)
+		} else {
+			t.Errorf("Fail! Expected %v, got %v", ":9090 sqlite", cfg.Server.Addr+" "+cfg.Storage.Type)
+		}
+		if cfg.Storage.Sqlite.Path == "p_manager.db" {
+			t.Logf("Success! Expected %v, got %v", "p_manager.db", cfg.Storage.Sqlite.Path)
+		} else {
+			t.Errorf("Fail! Missing values must keep defaults. Expected %v, got %v", "p_manager.db", cfg.Storage.Sqlite.Path)
+		}
+	}
+
+	_, err := Load(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Logf("Success! Expected error, got %v", err)
+	} else {
+		t.Errorf("Fail! Expected error for missing file, got nil")
+	}
+}
+
+func TestEnvOverride(t *testing.T) {
+	os.Setenv("PM_JWT_SECRET", "env_secret")
+	defer os.Unsetenv("PM_JWT_SECRET")
+
+	cfg, _ := Load("")
+	if cfg.Auth.Secret == "env_secret" {
+		t.Logf("Success! Expected %v, got %v", "env_secret", cfg.Auth.Secret)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", "env_secret", cfg.Auth.Secret)
+	}
+}
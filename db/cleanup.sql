@@ -2,6 +2,11 @@ DROP TABLE IF EXISTS operations CASCADE;
 DROP TABLE IF EXISTS operation_types CASCADE;
 DROP TABLE IF EXISTS portfolios CASCADE;
 DROP TABLE IF EXISTS prices CASCADE;
+DROP TABLE IF EXISTS rates CASCADE;
+DROP TABLE IF EXISTS security_events CASCADE;
+DROP TABLE IF EXISTS corporate_actions CASCADE;
+DROP TABLE IF EXISTS jobs CASCADE;
+DROP TABLE IF EXISTS schema_migrations CASCADE;
 DROP TABLE IF EXISTS securities CASCADE;
 DROP TABLE IF EXISTS securities_types CASCADE;
 DROP TABLE IF EXISTS user_sync CASCADE;
@@ -1,4 +1,8 @@
 #!/bin/bash
+# Builds res.sql which DROPS and recreates the whole schema. Use it for
+# a clean install only. Existing databases are upgraded by the server
+# itself on startup or with "pManager migrate [version]", see
+# storage/postgres/migrations.go. Keep these scripts in sync with migrations.
 cat cleanup.sql \
 functions/pseudo_encrypt_24.sql \
 tables/user_roles.sql \
@@ -13,5 +17,9 @@ tables/securities.sql \
 tables/operation_types.sql \
 tables/operations.sql \
 tables/prices.sql \
+tables/rates.sql \
+tables/security_events.sql \
+tables/corporate_actions.sql \
+tables/jobs.sql \
 tables/settings.sql \
 post_deployment.sql > res.sql
\ No newline at end of file
@@ -1,4 +1,4 @@
-INSERT INTO currencies VALUES
+INSERT INTO currencies (code,title) VALUES
     ('EUR','Евро'),
     ('USD','Доллар США'),
     ('RUB','Российский рубль');
@@ -13,7 +13,11 @@ INSERT INTO operation_types VALUES
     (7,'coupon','Выплата купона'),
     (8,'accruedInterestBuy','НКД при покупке'),
     (9,'accruedInterestSell','НКД при продаже'),
-    (10,'buyback','Выкуп ценной бумаги');
+    (10,'buyback','Выкуп ценной бумаги'),
+    (11,'dividend','Выплата дивидендов'),
+    (12,'tax','Налог'),
+    (13,'withholdingTax','Налог, удержанный с дохода'),
+    (14,'amortization','Частичное погашение номинала');
 
 INSERT INTO securities_types VALUES
     (10,'Stock','Акции'),
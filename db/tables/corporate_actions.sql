@@ -0,0 +1,11 @@
+CREATE TABLE corporate_actions (
+	isin varchar(12) NOT NULL,
+	type varchar(20) NOT NULL,
+	date date NOT NULL,
+	ratio_from bigint NOT NULL,
+	ratio_to bigint NOT NULL,
+	new_isin varchar(12) NULL,
+	new_ticker varchar(12) NULL,
+	cost_ratio numeric(10,6) NOT NULL,
+	CONSTRAINT pk_corporate_actions PRIMARY KEY (isin, type, date)
+);
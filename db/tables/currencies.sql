@@ -1,5 +1,6 @@
 CREATE TABLE currencies (
     code char(3) NOT NULL,
     title varchar(150) NULL,
+    rate_upd_time date NULL,
 	CONSTRAINT pk_currencies PRIMARY KEY (code)
 );
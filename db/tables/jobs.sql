@@ -0,0 +1,13 @@
+CREATE TABLE jobs (
+	id serial NOT NULL,
+	type varchar(20) NOT NULL,
+	login varchar(50) NULL,
+	state varchar(20) NOT NULL,
+	started timestamp NOT NULL,
+	finished timestamp NULL,
+	total integer NOT NULL,
+	processed integer NOT NULL,
+	error text NULL,
+	errors jsonb NULL,
+	CONSTRAINT pk_jobs PRIMARY KEY (id)
+);
@@ -8,6 +8,7 @@ CREATE TABLE operations (
     op_id smallint NOT NULL,
 	vol integer NOT NULL,
 	price numeric(20,6) NOT NULL,
+    fingerprint varchar(100) NULL,
 	CONSTRAINT pk_operations PRIMARY KEY (id),
     CONSTRAINT fk_operations_portfolios FOREIGN KEY(pid) REFERENCES portfolios(id),
     CONSTRAINT fk_operations_operation_types FOREIGN KEY(op_id) REFERENCES operation_types(id),
@@ -15,3 +16,4 @@ CREATE TABLE operations (
 );
 
 CREATE INDEX ix_operations ON operations(pid, sid, time);
+CREATE UNIQUE INDEX ux_operations_fingerprint ON operations(pid, fingerprint);
@@ -0,0 +1,7 @@
+CREATE TABLE rates (
+	currency char(3) NOT NULL,
+	date date NOT NULL,
+	rate numeric(20,6) NOT NULL,
+	CONSTRAINT pk_rates PRIMARY KEY (currency, date),
+	CONSTRAINT fk_rates_currency FOREIGN KEY(currency) REFERENCES currencies(code)
+);
@@ -0,0 +1,8 @@
+CREATE TABLE security_events (
+	isin varchar(12) NOT NULL,
+	type varchar(20) NOT NULL,
+	date date NOT NULL,
+	value numeric(20,6) NOT NULL,
+	currency char(3) NOT NULL,
+	CONSTRAINT pk_security_events PRIMARY KEY (isin, type, date)
+);
@@ -1,6 +1,6 @@
 // GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
 // This file was generated by swaggo/swag at
-// 2020-08-17 20:31:41.29183903 +0300 MSK m=+0.273521621
+// 2026-10-18 05:35:00.678055764 +0000 UTC m=+0.117216442
 
 package docs
 
@@ -29,29 +29,37 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/misc/gmail/url": {
+        "/actions": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets url for GMail auth",
+                "description": "Get splits, renames, spin-offs and conversions of securities",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "actions"
+                ],
+                "summary": "Get corporate actions",
+                "operationId": "get-actions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISIN",
+                        "name": "isin",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get GMail auth url",
-                "operationId": "get-gmail-url",
                 "responses": {
                     "200": {
-                        "description": "Returns url for GMail auth",
+                        "description": "Returns corporate actions sorted by date",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.gmailAuthURLSuccess"
+                                "$ref": "#/definitions/models.CorporateAction"
                             }
                         }
                     },
@@ -66,44 +74,15 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/portfolios": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Gets all portfolios avaliable",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "portfolios"
-                ],
-                "summary": "Get all portfolios",
-                "operationId": "portfolio-get-all",
-                "responses": {
-                    "200": {
-                        "description": "Returns portfolio info",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Portfolio"
-                            }
-                        }
                     },
-                    "400": {
-                        "description": "Returns when any processing error occurs",
+                    "404": {
+                        "description": "Returns when requested entity is not found",
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Returns when authentication error occurs",
+                    "500": {
+                        "description": "Returns when storage error occurs",
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
@@ -116,7 +95,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates single portfolio",
+                "description": "Adds corporate actions of securities. Action of the same security, type and date is overwritten.\nPositions, average prices, reports and realized P\u0026L restate operations made before action date. Fraction of resulting unit is sold at cost as cash in lieu",
                 "consumes": [
                     "application/json"
                 ],
@@ -124,26 +103,29 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "portfolios"
+                    "actions"
                 ],
-                "summary": "Add new portfolio",
-                "operationId": "portfolio-add",
+                "summary": "Add corporate actions",
+                "operationId": "add-actions",
                 "parameters": [
                     {
-                        "description": "Portfolio info",
-                        "name": "portfolio",
+                        "description": "Corporate actions",
+                        "name": "actions",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.portfolioRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CorporateAction"
+                            }
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns portfolio Id just created",
+                        "description": "Returns success status",
                         "schema": {
-                            "$ref": "#/definitions/api.addPortfoliioSuccess"
+                            "$ref": "#/definitions/api.commonResponse"
                         }
                     },
                     "400": {
@@ -157,6 +139,18 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
             },
@@ -166,20 +160,29 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes all portfolios an all associated operations",
+                "description": "Deletes all corporate actions of given security",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "portfolios"
+                    "actions"
+                ],
+                "summary": "Delete corporate actions",
+                "operationId": "delete-actions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISIN",
+                        "name": "isin",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Delete all portfolios",
-                "operationId": "portfolio-del-all",
                 "responses": {
                     "200": {
-                        "description": "Returns true if portfolios has deleted",
+                        "description": "Returns number of deleted items",
                         "schema": {
-                            "$ref": "#/definitions/api.delPortfoliioSuccess"
+                            "$ref": "#/definitions/api.delMutileSuccess"
                         }
                     },
                     "400": {
@@ -193,40 +196,66 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
             }
         },
-        "/portfolios/{id}": {
+        "/events": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets portfolio info by Id",
+                "description": "Get scheduled coupons, amortizations, offers and dividends",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "portfolios"
+                    "events"
                 ],
-                "summary": "Get portfolio by Id",
-                "operationId": "portfolio-get-by-id",
+                "summary": "Get security events",
+                "operationId": "get-events",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Portfolio Id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ISIN",
+                        "name": "isin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter events from this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter events till this date",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns portfolio info if any",
+                        "description": "Returns security events",
                         "schema": {
-                            "$ref": "#/definitions/models.Portfolio"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SecurityEvent"
+                            }
                         }
                     },
                     "400": {
@@ -240,50 +269,43 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/events/sync": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates portfolio info by Id",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Sync bond coupons, amortizations, offers and share dividends from MOEX ISS",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "portfolios"
-                ],
-                "summary": "Update portfolio info",
-                "operationId": "portfolio-put-by-id",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Portfolio Id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Portfolio info",
-                        "name": "portfolio",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.portfolioRequest"
-                        }
-                    }
+                    "events"
                 ],
+                "summary": "Sync security events",
+                "operationId": "sync-events",
                 "responses": {
                     "200": {
-                        "description": "Returns portfolio info if any",
+                        "description": "Returns id of started sync job",
                         "schema": {
-                            "$ref": "#/definitions/api.putPortfoliioSuccess"
+                            "$ref": "#/definitions/api.syncJobResponse"
                         }
                     },
                     "400": {
@@ -299,26 +321,28 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes portfolio an all associated operations",
+                "description": "Gets progress, per-item errors and final state of sync job",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "portfolios"
+                    "jobs"
                 ],
-                "summary": "Delete portfolio",
-                "operationId": "portfolio-del-by-id",
+                "summary": "Get sync job",
+                "operationId": "get-job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Portfolio Id",
+                        "description": "Job Id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -326,9 +350,9 @@ var doc = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns true if portfolio has deleted",
+                        "description": "Returns job state",
                         "schema": {
-                            "$ref": "#/definitions/api.delPortfoliioSuccess"
+                            "$ref": "#/definitions/models.Job"
                         }
                     },
                     "400": {
@@ -342,49 +366,45 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Returns when job not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
             }
         },
-        "/portfolios/{id}/average": {
+        "/misc/gmail/url": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets average price of given ticker",
+                "description": "Gets url for GMail auth",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "misc"
-                ],
-                "summary": "Get average",
-                "operationId": "get-average",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Portfolio Id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Ticker",
-                        "name": "ticker",
-                        "in": "query",
-                        "required": true
-                    }
+                    "user"
                 ],
+                "summary": "Get GMail auth url",
+                "operationId": "get-gmail-url",
                 "responses": {
                     "200": {
-                        "description": "Returns average price of given ticker",
+                        "description": "Returns url for GMail auth",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.getAverageSuccess"
+                                "$ref": "#/definitions/api.gmailAuthURLSuccess"
                             }
                         }
                     },
@@ -403,51 +423,29 @@ var doc = `{
                 }
             }
         },
-        "/portfolios/{id}/balance": {
+        "/portfolios": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets balance of given currency",
+                "description": "Gets all portfolios avaliable",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "misc"
-                ],
-                "summary": "Get balance",
-                "operationId": "get-balance",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Portfolio Id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Currency",
-                        "name": "currency",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "On date",
-                        "name": "on",
-                        "in": "query"
-                    }
+                    "portfolios"
                 ],
+                "summary": "Get all portfolios",
+                "operationId": "portfolio-get-all",
                 "responses": {
                     "200": {
-                        "description": "Returns balance of given currency",
+                        "description": "Returns portfolio info",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.getBalanceSuccess"
+                                "$ref": "#/definitions/models.Portfolio"
                             }
                         }
                     },
@@ -462,61 +460,162 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/portfolios/{id}/operations": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets all operations for specified portfolio",
+                "description": "Creates single portfolio",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "operations"
+                    "portfolios"
                 ],
-                "summary": "Get all operations",
-                "operationId": "operation-get-all",
+                "summary": "Add new portfolio",
+                "operationId": "portfolio-add",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Portfolio Id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Portfolio info",
+                        "name": "portfolio",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.portfolioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns portfolio Id just created",
+                        "schema": {
+                            "$ref": "#/definitions/api.addPortfoliioSuccess"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Filter by ticker",
-                        "name": "ticker",
-                        "in": "query"
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Filter operations from this date",
-                        "name": "from",
-                        "in": "query"
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes all portfolios an all associated operations",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Delete all portfolios",
+                "operationId": "portfolio-del-all",
+                "responses": {
+                    "200": {
+                        "description": "Returns true if portfolios has deleted",
+                        "schema": {
+                            "$ref": "#/definitions/api.delPortfoliioSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets portfolio info by Id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get portfolio by Id",
+                "operationId": "portfolio-get-by-id",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter operations till this date",
-                        "name": "to",
-                        "in": "query"
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns operations info",
+                        "description": "Returns portfolio info if any",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Operation"
-                            }
+                            "$ref": "#/definitions/models.Portfolio"
                         }
                     },
                     "400": {
@@ -530,16 +629,28 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Adds operation to specified portfolio",
+                "description": "Updates portfolio info by Id",
                 "consumes": [
                     "application/json"
                 ],
@@ -547,10 +658,10 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "operations"
+                    "portfolios"
                 ],
-                "summary": "Add new operation",
-                "operationId": "operation-add",
+                "summary": "Update portfolio info",
+                "operationId": "portfolio-put-by-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -560,20 +671,20 @@ var doc = `{
                         "required": true
                     },
                     {
-                        "description": "Operation info",
+                        "description": "Portfolio info",
                         "name": "portfolio",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.operationRequest"
+                            "$ref": "#/definitions/api.portfolioRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns portfolio Id just created",
+                        "description": "Returns portfolio info if any",
                         "schema": {
-                            "$ref": "#/definitions/api.addOperationSuccess"
+                            "$ref": "#/definitions/api.putPortfoliioSuccess"
                         }
                     },
                     "400": {
@@ -587,6 +698,18 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
             },
@@ -596,15 +719,15 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes all operations for given portfolio",
+                "description": "Deletes portfolio an all associated operations",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "operations"
+                    "portfolios"
                 ],
-                "summary": "Delete all operations",
-                "operationId": "operation-del-all",
+                "summary": "Delete portfolio",
+                "operationId": "portfolio-del-by-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -616,12 +739,9 @@ var doc = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns number of deleted items",
+                        "description": "Returns true if portfolio has deleted",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/api.delMutileSuccess"
-                            }
+                            "$ref": "#/definitions/api.delPortfoliioSuccess"
                         }
                     },
                     "400": {
@@ -635,26 +755,38 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
             }
         },
-        "/portfolios/{id}/securities": {
+        "/portfolios/{id}/average": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets portfolio info by Id",
+                "description": "Gets average price of given ticker",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "securities"
+                    "misc"
                 ],
-                "summary": "Get securities for given portfolio",
-                "operationId": "get-by-portfolio-securities",
+                "summary": "Get average",
+                "operationId": "get-average",
                 "parameters": [
                     {
                         "type": "string",
@@ -665,16 +797,20 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "Get securities on this date",
-                        "name": "on",
-                        "in": "query"
+                        "description": "Ticker",
+                        "name": "ticker",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns portfolio info if any",
+                        "description": "Returns average price of given ticker",
                         "schema": {
-                            "$ref": "#/definitions/models.Portfolio"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.getAverageSuccess"
+                            }
                         }
                     },
                     "400": {
@@ -688,26 +824,44 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
             }
         },
-        "/portfolios/{id}/sync": {
+        "/portfolios/{id}/balance": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Sync operations for given portfolio",
+                "description": "Gets balance of given currency. With report currency balances of all currencies are consolidated",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "misc"
                 ],
-                "summary": "Sync operations",
-                "operationId": "sync-op",
+                "summary": "Get balance",
+                "operationId": "get-balance",
                 "parameters": [
                     {
                         "type": "string",
@@ -718,24 +872,30 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "Filter operations from this date",
-                        "name": "from",
+                        "description": "Currency, required without report currency",
+                        "name": "currency",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter operations till this date",
-                        "name": "to",
+                        "description": "Convert balance to this currency at the rate of 'on' date",
+                        "name": "reportCurrency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "On date",
+                        "name": "on",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns success status",
+                        "description": "Returns balance of given currency",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.commonResponse"
+                                "$ref": "#/definitions/api.getBalanceSuccess"
                             }
                         }
                     },
@@ -750,53 +910,72 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/prices": {
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/calendar": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get prices",
+                "description": "Projects coupons, amortizations, offers and dividends of current holdings.\nAmount is event value per unit multiplied by currently held volume. Offers are shown at offer price",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "reports"
                 ],
-                "summary": "Get prices",
-                "operationId": "get-price",
+                "summary": "Get income calendar",
+                "operationId": "get-calendar",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ISIN",
-                        "name": "isin",
-                        "in": "query"
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Filter prices from this date",
+                        "description": "Events from this date, today by default",
                         "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter prices till this date",
+                        "description": "Events till this date, one year from today by default",
                         "name": "to",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns success status",
+                        "description": "Returns expected cash inflows sorted by date",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.commonResponse"
+                                "$ref": "#/definitions/models.CalendarEntry"
                             }
                         }
                     },
@@ -811,51 +990,84 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/portfolios/{id}/history": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get prices",
+                "description": "Gets market value (securities plus cash), cash balance and invested capital per currency for every point of given interval",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "reports"
                 ],
-                "summary": "Add prices",
-                "operationId": "add-price",
+                "summary": "Get valuation history",
+                "operationId": "get-history",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ISIN",
-                        "name": "isin",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First point date, first operation date by default",
+                        "name": "from",
                         "in": "query"
                     },
                     {
-                        "description": "Price info",
-                        "name": "price",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/api.priceRequest"
-                            }
-                        }
+                        "type": "string",
+                        "description": "Last point date, today by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Distance between points: day (default), week or month",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Add totals converted to this currency at the rate of point date",
+                        "name": "reportCurrency",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns success status",
+                        "description": "Returns valuation history",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.commonResponse"
+                                "$ref": "#/definitions/models.HistoryPoint"
                             }
                         }
                     },
@@ -870,44 +1082,21 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/prices/sync": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Sync prices",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "prices"
-                ],
-                "summary": "Sync prices",
-                "operationId": "sync-price",
-                "responses": {
-                    "200": {
-                        "description": "Returns success status",
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/api.commonResponse"
-                            }
+                            "$ref": "#/definitions/api.errorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Returns when any processing error occurs",
+                    "404": {
+                        "description": "Returns when requested entity is not found",
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Returns when authentication error occurs",
+                    "500": {
+                        "description": "Returns when storage error occurs",
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
@@ -915,51 +1104,58 @@ var doc = `{
                 }
             }
         },
-        "/securities": {
-            "get": {
+        "/portfolios/{id}/import": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets securities avaliable",
+                "description": "Parses uploaded broker reports (Sberbank HTML, VTB XLS or XLSX) and returns parsed operations.\nFiles are parsed as CSV when column mapping is provided.\nOperations are saved to specified portfolio only when commit flag is set, so the same files can be previewed first.\nOperations already present in portfolio are skipped on commit",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "securities"
+                    "operations"
                 ],
-                "summary": "Get securities",
-                "operationId": "get-securities",
+                "summary": "Import broker reports",
+                "operationId": "import-reports",
                 "parameters": [
                     {
-                        "enum": [
-                            "none",
-                            "ticker",
-                            "isin",
-                            "figi"
-                        ],
                         "type": "string",
-                        "default": "none",
-                        "description": "Filter by",
-                        "name": "filter",
-                        "in": "query"
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Broker report files",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Filter value",
-                        "name": "by",
+                        "description": "CSV column mapping as JSON, see csvfile.Mapping",
+                        "name": "mapping",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Save parsed operations to portfolio",
+                        "name": "commit",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns success status",
+                        "description": "Returns parsed operations",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Instrument"
-                            }
+                            "$ref": "#/definitions/api.importResult"
                         }
                     },
                     "400": {
@@ -973,42 +1169,78 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/portfolios/{id}/income": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Adds securities",
+                "description": "Aggregates coupons and dividends by month, security and currency. Gross income is before withholding tax, net income is after it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "securities"
+                    "reports"
                 ],
-                "summary": "Add securities",
-                "operationId": "add-securities",
+                "summary": "Get income",
+                "operationId": "get-income",
                 "parameters": [
                     {
-                        "description": "Instrument info",
-                        "name": "instrument",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Instrument"
-                        }
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ticker",
+                        "name": "ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter income from this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter income till this date",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns success status",
+                        "description": "Returns income by month and security",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Instrument"
+                                "$ref": "#/definitions/models.Income"
                             }
                         }
                     },
@@ -1023,44 +1255,21 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/securities/sync": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Sync intruments dimension",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "securities"
-                ],
-                "summary": "Sync securities",
-                "operationId": "sync-securities",
-                "responses": {
-                    "200": {
-                        "description": "Returns success status",
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/api.commonResponse"
-                            }
+                            "$ref": "#/definitions/api.errorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Returns when any processing error occurs",
+                    "404": {
+                        "description": "Returns when requested entity is not found",
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Returns when authentication error occurs",
+                    "500": {
+                        "description": "Returns when storage error occurs",
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
@@ -1068,214 +1277,2544 @@ var doc = `{
                 }
             }
         },
-        "/token/validate": {
+        "/portfolios/{id}/operations": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get string by ID",
+                "description": "Gets all operations for specified portfolio",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "security"
+                    "operations"
                 ],
-                "summary": "Validate token",
-                "operationId": "validate-token",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/api.tokenResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                "summary": "Get all operations",
+                "operationId": "operation-get-all",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by operation types, comma separated",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by currencies, comma separated",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tickers, comma separated",
+                        "name": "ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ISINs, comma separated",
+                        "name": "isin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by FIGIs, comma separated",
+                        "name": "figi",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter operations from this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter operations till this date",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort keys, comma separated. Prefix key with minus to sort descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of returned operations",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of operations to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns operations info",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Operation"
+                            }
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of matched operations"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Returns when filter or sort key is unknown",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/user/login": {
+            },
             "post": {
-                "description": "Checks user credentials and returns JWT if ok",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds operation to specified portfolio",
                 "consumes": [
-                    "application/x-www-form-urlencoded"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "operations"
                 ],
-                "summary": "Login",
-                "operationId": "login",
+                "summary": "Add new operation",
+                "operationId": "operation-add",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User name",
-                        "name": "username",
-                        "in": "formData",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Password",
-                        "name": "password",
-                        "in": "formData",
-                        "required": true
+                        "description": "Operation info",
+                        "name": "portfolio",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.operationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Returns portfolio Id just created",
                         "schema": {
-                            "$ref": "#/definitions/api.tokenResponse"
+                            "$ref": "#/definitions/api.addOperationSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Returns every invalid field of input",
+                        "schema": {
+                            "$ref": "#/definitions/api.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/user/signup": {
-            "post": {
-                "description": "Creates new user",
-                "consumes": [
-                    "application/x-www-form-urlencoded"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Deletes all operations for given portfolio",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "operations"
                 ],
-                "summary": "Create new user",
-                "operationId": "sign-up",
+                "summary": "Delete all operations",
+                "operationId": "operation-del-all",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User name",
-                        "name": "username",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Password",
-                        "name": "password",
-                        "in": "formData",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Returns number of deleted items",
                         "schema": {
-                            "$ref": "#/definitions/api.tokenResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.delMutileSuccess"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
                         "schema": {
                             "$ref": "#/definitions/api.errorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.addOperationSuccess": {
-            "type": "object",
-            "properties": {
-                "createdOperationId": {
-                    "type": "string",
-                    "example": "5edbc0a72c857652a0542fab"
-                }
-            }
-        },
-        "api.addPortfoliioSuccess": {
-            "type": "object",
-            "properties": {
-                "createdPortfolioId": {
-                    "type": "string",
-                    "example": "5edb2a0e550dfc5f16392838"
-                }
-            }
         },
-        "api.commonResponse": {
-            "type": "object",
-            "properties": {
-                "status": {
+        "/portfolios/{id}/operations/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Exports operations of specified portfolio to CSV file.\nDefault mapping exports all operation fields so that the file can be imported back without losses",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Export operations",
+                "operationId": "operation-export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ticker",
+                        "name": "ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter operations from this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter operations till this date",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV column mapping as JSON, see csvfile.Mapping",
+                        "name": "mapping",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns CSV file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/operations/{opId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets operation info by Id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Get operation by Id",
+                "operationId": "operation-get-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation Id",
+                        "name": "opId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns operation info",
+                        "schema": {
+                            "$ref": "#/definitions/models.Operation"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces operation info by Id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Update operation",
+                "operationId": "operation-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation Id",
+                        "name": "opId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Operation info",
+                        "name": "operation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.operationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns whether operation was modified",
+                        "schema": {
+                            "$ref": "#/definitions/api.putOperationSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Returns every invalid field of input",
+                        "schema": {
+                            "$ref": "#/definitions/api.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes operation by Id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Delete operation",
+                "operationId": "operation-del",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation Id",
+                        "name": "opId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns whether operation was deleted",
+                        "schema": {
+                            "$ref": "#/definitions/api.delOperationSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/performance": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets money-weighted (XIRR) and time-weighted (TWR) return of given currency part of portfolio or of whole portfolio consolidated in report currency",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get performance",
+                "operationId": "get-performance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency, RUB by default",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Consolidate whole portfolio in this currency",
+                        "name": "reportCurrency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period: ytd, 1y or inception. All periods by default",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns performance for every requested period",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Performance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/realized": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Matches sells with buy lots using FIFO and returns realized result of every sell",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get realized P\u0026L",
+                "operationId": "get-realized",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ticker",
+                        "name": "ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter sells from this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter sells till this date",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns realized trades",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RealizedTrade"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/schedule": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Schedules periodic import of Sberbank broker reports from Gmail of current user into given portfolio",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Set Gmail import schedule",
+                "operationId": "put-gmail-schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule info",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.scheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns saved schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/securities": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets portfolio info by Id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "securities"
+                ],
+                "summary": "Get securities for given portfolio",
+                "operationId": "get-by-portfolio-securities",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Get securities on this date",
+                        "name": "on",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Add values converted to this currency at the rate of 'on' date and their total",
+                        "name": "reportCurrency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns securities, or consolidatedShares object when report currency is set",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Share"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/sync": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sync operations for given portfolio from Sberbank or VTB reports via Gmail or from Tinkoff Invest API",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "misc"
+                ],
+                "summary": "Sync operations",
+                "operationId": "sync-op",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operations provider: sber (default), vtb or tcs",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tinkoff broker account Id. Default account used if not specified",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter operations from this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter operations till this date",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns id of started sync job",
+                        "schema": {
+                            "$ref": "#/definitions/api.syncJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/tax": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Builds personal income tax (NDFL) report for given year. Amounts are converted to rubles at stored CBR rate of operation date",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get NDFL report",
+                "operationId": "get-tax-report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Calendar year",
+                        "name": "year",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Response format: json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns tax report",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaxReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Returns when portfolio belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prices": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get prices",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Get prices",
+                "operationId": "get-price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by ISINs, comma separated",
+                        "name": "isin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tickers, comma separated",
+                        "name": "ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by FIGIs, comma separated",
+                        "name": "figi",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter prices from this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter prices till this date",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort keys, comma separated. Prefix key with minus to sort descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of returned prices",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of prices to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns prices",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Price"
+                            }
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of matched prices"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Returns when filter or sort key is unknown",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get prices",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Add prices",
+                "operationId": "add-price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISIN",
+                        "name": "isin",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Price info",
+                        "name": "price",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.priceRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns success status",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.commonResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prices/sync": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sync prices of all instruments using price provider of instrument exchange",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Sync prices",
+                "operationId": "sync-price",
+                "responses": {
+                    "200": {
+                        "description": "Returns id of started sync job",
+                        "schema": {
+                            "$ref": "#/definitions/api.syncJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get currency rates in rubles",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Get currency rates",
+                "operationId": "get-rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter rates from this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter rates till this date",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns currency rates",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Rate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/sync": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sync currency rates from Central Bank of Russia",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Sync currency rates",
+                "operationId": "sync-rates",
+                "responses": {
+                    "200": {
+                        "description": "Returns id of started sync job",
+                        "schema": {
+                            "$ref": "#/definitions/api.syncJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get schedules of background syncs with results of their last runs.\nReturns common schedules and Gmail import schedules of current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Get schedules",
+                "operationId": "get-schedules",
+                "responses": {
+                    "200": {
+                        "description": "Returns schedules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.scheduleResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates cron expression of background job schedule and enables or disables it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Update schedule",
+                "operationId": "put-schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule info",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.scheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns success status",
+                        "schema": {
+                            "$ref": "#/definitions/api.commonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when schedule not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/securities": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets securities avaliable",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "securities"
+                ],
+                "summary": "Get securities",
+                "operationId": "get-securities",
+                "parameters": [
+                    {
+                        "enum": [
+                            "none",
+                            "ticker",
+                            "isin",
+                            "figi"
+                        ],
+                        "type": "string",
+                        "default": "none",
+                        "description": "Filter by",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter value",
+                        "name": "by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by instrument types, comma separated",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by exchanges, comma separated",
+                        "name": "exchange",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by currencies, comma separated",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ISINs, comma separated",
+                        "name": "isin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tickers, comma separated",
+                        "name": "ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by FIGIs, comma separated",
+                        "name": "figi",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort keys, comma separated. Prefix key with minus to sort descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of returned securities",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of securities to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns securities",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Instrument"
+                            }
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of matched securities"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Returns every invalid field of input",
+                        "schema": {
+                            "$ref": "#/definitions/api.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds securities",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "securities"
+                ],
+                "summary": "Add securities",
+                "operationId": "add-securities",
+                "parameters": [
+                    {
+                        "description": "Instrument info",
+                        "name": "instrument",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Instrument"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns success status",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Instrument"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Returns when requested entity is not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Returns every invalid field of input",
+                        "schema": {
+                            "$ref": "#/definitions/api.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Returns when storage error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/securities/sync": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sync intruments dimension",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "securities"
+                ],
+                "summary": "Sync securities",
+                "operationId": "sync-securities",
+                "responses": {
+                    "200": {
+                        "description": "Returns id of started sync job",
+                        "schema": {
+                            "$ref": "#/definitions/api.syncJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Returns when any processing error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Returns when authentication error occurs",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/validate": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get string by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "Validate token",
+                "operationId": "validate-token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.tokenResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/login": {
+            "post": {
+                "description": "Checks user credentials and returns JWT if ok",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Login",
+                "operationId": "login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User name",
+                        "name": "username",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Password",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.tokenResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/signup": {
+            "post": {
+                "description": "Creates new user",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create new user",
+                "operationId": "sign-up",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User name",
+                        "name": "username",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Password",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.tokenResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.errorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.addOperationSuccess": {
+            "type": "object",
+            "properties": {
+                "createdOperationId": {
+                    "type": "string",
+                    "example": "5edbc0a72c857652a0542fab"
+                }
+            }
+        },
+        "api.addPortfoliioSuccess": {
+            "type": "object",
+            "properties": {
+                "createdPortfolioId": {
+                    "type": "string",
+                    "example": "5edb2a0e550dfc5f16392838"
+                }
+            }
+        },
+        "api.commonResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "api.delMutileSuccess": {
+            "type": "object",
+            "properties": {
+                "DeletedItems": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "api.delOperationSuccess": {
+            "type": "object",
+            "properties": {
+                "hasDeleted": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "api.delPortfoliioSuccess": {
+            "type": "object",
+            "properties": {
+                "hasDeleted": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "api.errorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is one of badRequest, unauthorized, forbidden, notFound, conflict, validation, internal",
+                    "type": "string",
+                    "example": "badRequest"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Something went wrong"
+                }
+            }
+        },
+        "api.getAverageSuccess": {
+            "type": "object",
+            "properties": {
+                "avg": {
+                    "type": "number",
+                    "example": 42
+                }
+            }
+        },
+        "api.getBalanceSuccess": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number",
+                    "example": 42
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                }
+            }
+        },
+        "api.gmailAuthURLSuccess": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string",
+                    "example": "https://google.com"
+                }
+            }
+        },
+        "api.importResult": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "description": "Added and Skipped count committed operations,\noperations already present in portfolio are skipped",
+                    "type": "integer",
+                    "example": 12
+                },
+                "committed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Operation"
+                    }
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "api.operationRequest": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2020-06-06T15:54:05Z"
+                },
+                "figi": {
+                    "type": "string",
+                    "example": "BBG00MVRXDB0"
+                },
+                "isin": {
+                    "type": "string",
+                    "example": "US9229083632"
+                },
+                "price": {
+                    "type": "number",
+                    "example": 293.61
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "VOO"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "sell"
+                },
+                "vol": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "api.portfolioRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Best portfolio ever!!!"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Best portfolio"
+                }
+            }
+        },
+        "api.priceRequest": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number",
+                    "example": 293.61
+                },
+                "time": {
+                    "type": "integer",
+                    "example": 1467590400
+                },
+                "vol": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "api.putOperationSuccess": {
+            "type": "object",
+            "properties": {
+                "hasModified": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "api.putPortfoliioSuccess": {
+            "type": "object",
+            "properties": {
+                "hasModified": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "api.scheduleRequest": {
+            "type": "object",
+            "properties": {
+                "cron": {
+                    "type": "string",
+                    "example": "0 3 * * *"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "api.scheduleResponse": {
+            "type": "object",
+            "properties": {
+                "cron": {
+                    "type": "string",
+                    "example": "0 3 * * *"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "string",
+                    "example": "prices"
+                },
+                "job": {
+                    "type": "string",
+                    "example": "prices"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lastJobId": {
+                    "type": "string",
+                    "example": "42"
+                },
+                "lastRun": {
+                    "type": "string",
+                    "example": "2020-06-06T03:00:00Z"
+                },
+                "lastStatus": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "login": {
+                    "type": "string",
+                    "example": "mark123"
+                },
+                "nextRun": {
+                    "type": "string",
+                    "example": "2020-06-07T03:00:00Z"
+                },
+                "pid": {
+                    "type": "string",
+                    "example": "1"
+                }
+            }
+        },
+        "api.syncJobResponse": {
+            "type": "object",
+            "properties": {
+                "jobId": {
+                    "type": "string",
+                    "example": "42"
+                }
+            }
+        },
+        "api.tokenResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.validationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "validation"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Validation failed"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.FieldError"
+                    }
+                }
+            }
+        },
+        "models.CalendarEntry": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 383.9
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2020-09-16T00:00:00Z"
+                },
+                "isin": {
+                    "type": "string",
+                    "example": "RU000A0JSMA2"
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "SU26209RMFS5"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "coupon"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 38.39
+                },
+                "vol": {
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "models.CorporateAction": {
+            "type": "object",
+            "properties": {
+                "costRatio": {
+                    "type": "number",
+                    "example": 0
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2020-08-31T00:00:00Z"
+                },
+                "isin": {
+                    "type": "string",
+                    "example": "US0378331005"
+                },
+                "newIsin": {
+                    "type": "string"
+                },
+                "newTicker": {
+                    "type": "string"
+                },
+                "ratioFrom": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ratioTo": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "type": {
+                    "type": "string",
+                    "example": "split"
+                }
+            }
+        },
+        "models.HistoryPoint": {
+            "type": "object",
+            "properties": {
+                "cash": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2020-06-06T00:00:00Z"
+                },
+                "invested": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "marketValue": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "reportCurrency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "totalCash": {
+                    "type": "number",
+                    "example": 1200.5
+                },
+                "totalInvested": {
+                    "type": "number",
+                    "example": 200000
+                },
+                "totalMarketValue": {
+                    "type": "number",
+                    "example": 250000
+                }
+            }
+        },
+        "models.Income": {
+            "type": "object",
+            "properties": {
+                "coupons": {
+                    "type": "number",
+                    "example": 0
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "dividends": {
+                    "type": "number",
+                    "example": 1860
+                },
+                "gross": {
+                    "type": "number",
+                    "example": 1860
+                },
+                "isin": {
+                    "type": "string",
+                    "example": "RU0009029540"
+                },
+                "month": {
+                    "type": "string",
+                    "example": "2020-06"
+                },
+                "net": {
+                    "type": "number",
+                    "example": 1618
+                },
+                "tax": {
+                    "type": "number",
+                    "example": 242
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "SBER"
+                }
+            }
+        },
+        "models.Instrument": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "exchange": {
+                    "type": "string",
+                    "example": "SPBEX"
+                },
+                "figi": {
+                    "type": "string",
+                    "example": "BBG000HLJ7M4"
+                },
+                "isin": {
+                    "type": "string",
+                    "example": "US45867G1013"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "InterDigItal Inc"
+                },
+                "priceUptdTime": {
+                    "type": "string",
+                    "example": "2020-06-06T15:54:05Z"
+                },
+                "secID": {
+                    "type": "integer"
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "IDCC"
+                },
+                "type": {
                     "type": "string",
-                    "example": "ok"
+                    "example": "Stock"
                 }
             }
         },
-        "api.delMutileSuccess": {
+        "models.Job": {
             "type": "object",
             "properties": {
-                "DeletedItems": {
+                "error": {
+                    "type": "string",
+                    "example": "No TCS token found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JobError"
+                    }
+                },
+                "finished": {
+                    "type": "string",
+                    "example": "2020-06-06T03:05:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "42"
+                },
+                "login": {
+                    "type": "string",
+                    "example": "mark123"
+                },
+                "processed": {
                     "type": "integer",
                     "example": 42
+                },
+                "started": {
+                    "type": "string",
+                    "example": "2020-06-06T03:00:00Z"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "type": {
+                    "type": "string",
+                    "example": "prices"
                 }
             }
         },
-        "api.delPortfoliioSuccess": {
-            "type": "object",
-            "properties": {
-                "hasDeleted": {
-                    "type": "boolean",
-                    "example": true
-                }
-            }
-        },
-        "api.errorResponse": {
+        "models.JobError": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "string",
-                    "example": "Something went wrong"
+                    "example": "no price provider for SPBEX exchange"
+                },
+                "item": {
+                    "type": "string",
+                    "example": "IDCC"
                 }
             }
         },
-        "api.getAverageSuccess": {
+        "models.Lot": {
             "type": "object",
             "properties": {
-                "avg": {
+                "buyDate": {
+                    "type": "string",
+                    "example": "2020-06-06T15:54:05Z"
+                },
+                "buyId": {
+                    "type": "string",
+                    "example": "5edbc0a72c857652a0542fab"
+                },
+                "costBasis": {
                     "type": "number",
-                    "example": 42
-                }
-            }
-        },
-        "api.getBalanceSuccess": {
-            "type": "object",
-            "properties": {
-                "balance": {
+                    "example": 29375.68
+                },
+                "fee": {
                     "type": "number",
-                    "example": 42
-                }
-            }
-        },
-        "api.gmailAuthURLSuccess": {
-            "type": "object",
-            "properties": {
-                "url": {
-                    "type": "string",
-                    "example": "https://google.com"
+                    "example": 14.68
+                },
+                "holdingDays": {
+                    "type": "integer",
+                    "example": 365
+                },
+                "price": {
+                    "type": "number",
+                    "example": 293.61
+                },
+                "vol": {
+                    "type": "integer",
+                    "example": 100
                 }
             }
         },
-        "api.operationRequest": {
+        "models.Operation": {
             "type": "object",
             "properties": {
                 "currency": {
@@ -1290,10 +3829,17 @@ var doc = `{
                     "type": "string",
                     "example": "BBG00MVRXDB0"
                 },
+                "id": {
+                    "type": "string",
+                    "example": "5edbc0a72c857652a0542fab"
+                },
                 "isin": {
                     "type": "string",
                     "example": "US9229083632"
                 },
+                "pid": {
+                    "type": "string"
+                },
                 "price": {
                     "type": "number",
                     "example": 293.61
@@ -1312,29 +3858,69 @@ var doc = `{
                 }
             }
         },
-        "api.portfolioRequest": {
+        "models.Performance": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2020-01-01T00:00:00Z"
+                },
+                "period": {
+                    "type": "string",
+                    "example": "ytd"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2020-06-06T00:00:00Z"
+                },
+                "twr": {
+                    "type": "number",
+                    "example": 0.0812
+                },
+                "xirr": {
+                    "type": "number",
+                    "example": 0.1234
+                }
+            }
+        },
+        "models.Portfolio": {
             "type": "object",
             "properties": {
                 "description": {
                     "type": "string",
                     "example": "Best portfolio ever!!!"
                 },
+                "id": {
+                    "type": "string",
+                    "example": "5edb2a0e550dfc5f16392838"
+                },
                 "name": {
                     "type": "string",
                     "example": "Best portfolio"
                 }
             }
         },
-        "api.priceRequest": {
+        "models.Price": {
             "type": "object",
             "properties": {
+                "isin": {
+                    "type": "string",
+                    "example": "US9229083632"
+                },
                 "price": {
                     "type": "number",
                     "example": 293.61
                 },
+                "secID": {
+                    "type": "integer"
+                },
                 "time": {
-                    "type": "integer",
-                    "example": 1467590400
+                    "type": "string",
+                    "example": "2020-06-06T15:54:05Z"
                 },
                 "vol": {
                     "type": "integer",
@@ -1342,84 +3928,153 @@ var doc = `{
                 }
             }
         },
-        "api.putPortfoliioSuccess": {
+        "models.Rate": {
             "type": "object",
             "properties": {
-                "hasModified": {
-                    "type": "boolean",
-                    "example": true
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2020-06-06T00:00:00Z"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 68.6319
                 }
             }
         },
-        "api.tokenResponse": {
+        "models.RealizedTrade": {
             "type": "object",
             "properties": {
-                "status": {
-                    "type": "string"
+                "costBasis": {
+                    "type": "number",
+                    "example": 29375.68
                 },
-                "token": {
-                    "type": "string"
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "fee": {
+                    "type": "number",
+                    "example": 15.51
+                },
+                "gain": {
+                    "type": "number",
+                    "example": 1623.81
+                },
+                "isin": {
+                    "type": "string",
+                    "example": "US9229083632"
+                },
+                "lots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Lot"
+                    }
+                },
+                "price": {
+                    "type": "number",
+                    "example": 310.15
+                },
+                "proceeds": {
+                    "type": "number",
+                    "example": 30999.49
+                },
+                "sellDate": {
+                    "type": "string",
+                    "example": "2020-06-06T15:54:05Z"
+                },
+                "sellId": {
+                    "type": "string",
+                    "example": "5edbc0a72c857652a0542fab"
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "VOO"
+                },
+                "vol": {
+                    "type": "integer",
+                    "example": 100
                 }
             }
         },
-        "models.Instrument": {
+        "models.Schedule": {
             "type": "object",
             "properties": {
-                "currency": {
+                "cron": {
                     "type": "string",
-                    "example": "USD"
+                    "example": "0 3 * * *"
                 },
-                "figi": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
                     "type": "string",
-                    "example": "BBG000HLJ7M4"
+                    "example": "prices"
                 },
-                "isin": {
+                "job": {
                     "type": "string",
-                    "example": "US45867G1013"
+                    "example": "prices"
                 },
-                "name": {
+                "lastError": {
+                    "type": "string"
+                },
+                "lastJobId": {
                     "type": "string",
-                    "example": "InterDigItal Inc"
+                    "example": "42"
                 },
-                "priceUptdTime": {
+                "lastRun": {
                     "type": "string",
-                    "example": "2020-06-06T15:54:05Z"
+                    "example": "2020-06-06T03:00:00Z"
                 },
-                "ticker": {
+                "lastStatus": {
                     "type": "string",
-                    "example": "IDCC"
+                    "example": "ok"
                 },
-                "type": {
+                "login": {
                     "type": "string",
-                    "example": "Stock"
+                    "example": "mark123"
+                },
+                "pid": {
+                    "type": "string",
+                    "example": "1"
                 }
             }
         },
-        "models.Operation": {
+        "models.SecurityEvent": {
             "type": "object",
             "properties": {
                 "currency": {
                     "type": "string",
-                    "example": "USD"
+                    "example": "RUB"
                 },
                 "date": {
                     "type": "string",
-                    "example": "2020-06-06T15:54:05Z"
+                    "example": "2020-09-16T00:00:00Z"
                 },
-                "figi": {
+                "isin": {
                     "type": "string",
-                    "example": "BBG00MVRXDB0"
+                    "example": "RU000A0JSMA2"
                 },
-                "id": {
+                "type": {
                     "type": "string",
-                    "example": "5edbc0a72c857652a0542fab"
+                    "example": "coupon"
                 },
+                "value": {
+                    "type": "number",
+                    "example": 38.39
+                }
+            }
+        },
+        "models.Share": {
+            "type": "object",
+            "properties": {
                 "isin": {
                     "type": "string",
-                    "example": "US9229083632"
-                },
-                "pid": {
-                    "type": "string"
+                    "example": "US45867G1013"
                 },
                 "price": {
                     "type": "number",
@@ -1427,11 +4082,11 @@ var doc = `{
                 },
                 "ticker": {
                     "type": "string",
-                    "example": "VOO"
+                    "example": "IDCC"
                 },
-                "type": {
+                "time": {
                     "type": "string",
-                    "example": "sell"
+                    "example": "2020-06-06T15:54:05Z"
                 },
                 "vol": {
                     "type": "integer",
@@ -1439,20 +4094,71 @@ var doc = `{
                 }
             }
         },
-        "models.Portfolio": {
+        "models.TaxReport": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string",
-                    "example": "Best portfolio ever!!!"
+                "accruedInterestPaid": {
+                    "type": "number",
+                    "example": 25.7
                 },
-                "id": {
+                "accruedInterestReceived": {
+                    "type": "number",
+                    "example": 12.3
+                },
+                "costBasis": {
+                    "type": "number",
+                    "example": 29375.68
+                },
+                "coupons": {
+                    "type": "number",
+                    "example": 412.5
+                },
+                "fees": {
+                    "type": "number",
+                    "example": 30.19
+                },
+                "proceeds": {
+                    "type": "number",
+                    "example": 30999.49
+                },
+                "realizedGain": {
+                    "type": "number",
+                    "example": 1623.81
+                },
+                "tax": {
+                    "type": "number",
+                    "example": 263
+                },
+                "taxBase": {
+                    "type": "number",
+                    "example": 2022.91
+                },
+                "taxRate": {
+                    "type": "number",
+                    "example": 0.13
+                },
+                "trades": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RealizedTrade"
+                    }
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2020
+                }
+            }
+        },
+        "validation.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
                     "type": "string",
-                    "example": "5edb2a0e550dfc5f16392838"
+                    "example": "isin"
                 },
-                "name": {
+                "message": {
                     "type": "string",
-                    "example": "Best portfolio"
+                    "example": "Invalid ISIN checksum"
                 }
             }
         }
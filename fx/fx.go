@@ -0,0 +1,68 @@
+package fx
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/storage"
+)
+
+// Converter converts amounts between currencies at historical rates.
+// Rates are rubles per currency unit, the last known rate not later
+// than requested date is used
+type Converter struct {
+	rates map[currency.Type][]models.Rate
+}
+
+// NewConverter creates converter from given rates
+func NewConverter(rates []models.Rate) Converter {
+	c := Converter{rates: make(map[currency.Type][]models.Rate)}
+	for _, r := range rates {
+		c.rates[r.Currency] = append(c.rates[r.Currency], r)
+	}
+	for _, r := range c.rates {
+		sort.SliceStable(r, func(i, j int) bool { return r[i].Date.Before(r[j].Date) })
+	}
+	return c
+}
+
+// Load creates converter from all rates stored up to given date
+func Load(s storage.Db, to time.Time) (Converter, error) {
+	rates, err := s.GetRates("", "", to.Format("2006-01-02T15:04:05Z07:00"))
+	if err != nil {
+		return Converter{}, err
+	}
+	return NewConverter(rates), nil
+}
+
+// GetRate returns rate of given currency in rubles on given date
+func (c Converter) GetRate(curr currency.Type, date time.Time) (float64, error) {
+	if curr == currency.RUB || curr == "" {
+		return 1, nil
+	}
+	rates := c.rates[curr]
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].Date.After(date) })
+	if i == 0 {
+		return 0, fmt.Errorf("no %s rate on %s", curr, date.Format("2006-01-02"))
+	}
+	return rates[i-1].Rate, nil
+}
+
+// Convert converts amount between currencies on given date
+func (c Converter) Convert(amount float64, from, to currency.Type, date time.Time) (float64, error) {
+	if from == to {
+		return amount, nil
+	}
+	fromRate, err := c.GetRate(from, date)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := c.GetRate(to, date)
+	if err != nil {
+		return 0, err
+	}
+	return amount * fromRate / toRate, nil
+}
@@ -0,0 +1,40 @@
+package fx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+)
+
+func TestConvert(t *testing.T) {
+	d := func(day int) time.Time { return time.Date(2020, 1, day, 0, 0, 0, 0, time.UTC) }
+	c := NewConverter([]models.Rate{
+		{Currency: currency.USD, Date: d(16), Rate: 62},
+		{Currency: currency.USD, Date: d(15), Rate: 60},
+		{Currency: currency.EUR, Date: d(15), Rate: 66},
+	})
+
+	// weekend uses last known rate
+	usd, err := c.Convert(10, currency.USD, currency.RUB, d(18))
+	if err == nil && usd == 620 {
+		t.Logf("Success! Expected %v, got %v", 620, usd)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v (%v)", 620, usd, err)
+	}
+
+	eur, err := c.Convert(60, currency.USD, currency.EUR, d(15))
+	if err == nil && eur == 60*60/66.0 {
+		t.Logf("Success! Expected %v, got %v", 60*60/66.0, eur)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v (%v)", 60*60/66.0, eur, err)
+	}
+
+	_, err = c.Convert(1, currency.USD, currency.RUB, d(14))
+	if err != nil {
+		t.Logf("Success! Expected error, got %v", err)
+	} else {
+		t.Errorf("Fail! Expected error for missing rate, got nil")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"io/ioutil"
 
+	"github.com/kaseat/pManager/config"
 	"github.com/kaseat/pManager/storage"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -18,6 +19,12 @@ type client struct {
 }
 
 var cl *client
+var credentialsPath = config.Default().Gmail.CredentialsPath
+
+// Configure sets Gmail client settings. Must be called before first GetClient call
+func Configure(c config.Gmail) {
+	credentialsPath = c.CredentialsPath
+}
 
 // Client is Gmail client
 type Client interface {
@@ -76,7 +83,7 @@ func (c client) HandleResponse(state string, code string) error {
 }
 
 func getServiceFromFile() (*oauth2.Config, error) {
-	b, err := ioutil.ReadFile("credentials.json")
+	b, err := ioutil.ReadFile(credentialsPath)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage"
+)
+
+// Tracker records progress of background job run into a storage.
+// All methods are safe to call on nil tracker, they do nothing then
+type Tracker struct {
+	mu  sync.Mutex
+	job models.Job
+}
+
+// Start saves new running job of given type and returns its tracker.
+// Empty login means job started by the system
+func Start(t job.Type, login string) (*Tracker, error) {
+	j := models.Job{
+		Type:    t,
+		Login:   login,
+		State:   job.Running,
+		Started: time.Now().UTC(),
+	}
+	id, err := storage.GetStorage().AddJob(j)
+	if err != nil {
+		return nil, err
+	}
+	j.ID = id
+	return &Tracker{job: j}, nil
+}
+
+// Run starts job in background and returns its id immediately.
+// Job is finished with an error returned by fn
+func Run(t job.Type, login string, fn func(tr *Tracker) error) (string, error) {
+	tr, err := Start(t, login)
+	if err != nil {
+		return "", err
+	}
+	go func() {
+		tr.Finish(fn(tr))
+	}()
+	return tr.ID(), nil
+}
+
+// ID returns job id
+func (t *Tracker) ID() string {
+	if t == nil {
+		return ""
+	}
+	return t.job.ID
+}
+
+// Job returns current state of the job
+func (t *Tracker) Job() models.Job {
+	if t == nil {
+		return models.Job{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return copyJob(t.job)
+}
+
+// SetTotal sets number of items job is going to process
+func (t *Tracker) SetTotal(n int) {
+	t.update(func(j *models.Job) { j.Total = n })
+}
+
+// Advance increases number of processed items
+func (t *Tracker) Advance(n int) {
+	t.update(func(j *models.Job) { j.Processed += n })
+}
+
+// Done marks single item as processed. Not nil error is recorded as item failure
+func (t *Tracker) Done(item string, err error) {
+	t.update(func(j *models.Job) {
+		j.Processed++
+		if err != nil {
+			j.Errors = append(j.Errors, models.JobError{Item: item, Error: err.Error()})
+		}
+	})
+}
+
+// Error records item failure without advancing progress
+func (t *Tracker) Error(item string, err error) {
+	t.update(func(j *models.Job) {
+		j.Errors = append(j.Errors, models.JobError{Item: item, Error: err.Error()})
+	})
+}
+
+// Finish marks job completed, or failed if error provided, and returns its final state
+func (t *Tracker) Finish(err error) models.Job {
+	t.update(func(j *models.Job) {
+		j.Finished = time.Now().UTC()
+		if err != nil {
+			j.State = job.Failed
+			j.Error = err.Error()
+		} else {
+			j.State = job.Completed
+		}
+	})
+	return t.Job()
+}
+
+func (t *Tracker) update(fn func(j *models.Job)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.job)
+	if _, err := storage.GetStorage().UpdateJob(t.job); err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error save job", t.job.ID, "state:", err)
+	}
+}
+
+func copyJob(j models.Job) models.Job {
+	if j.Errors != nil {
+		j.Errors = append([]models.JobError{}, j.Errors...)
+	}
+	return j
+}
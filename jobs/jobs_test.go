@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage"
+)
+
+func TestTracker(t *testing.T) {
+	storage.SwitchStorage(storage.Memory)
+	s := storage.GetStorage()
+
+	tr, err := Start(job.Prices, "test")
+	if err != nil {
+		t.Errorf("Fail! Unexpected error: %v", err)
+	}
+	tr.SetTotal(3)
+	tr.Done("SBER", nil)
+	tr.Done("IDCC", errors.New("no price provider"))
+
+	j, _ := s.GetJob(tr.ID())
+	if j.State == job.Running && j.Login == "test" && j.Total == 3 && j.Processed == 2 && len(j.Errors) == 1 && j.Finished.IsZero() {
+		t.Logf("Success! Got expected progress %v", j)
+	} else {
+		t.Errorf("Fail! Unexpected progress %v", j)
+	}
+
+	tr.Advance(1)
+	tr.Finish(nil)
+	j, _ = s.GetJob(tr.ID())
+	if j.State == job.Completed && j.Processed == 3 && !j.Finished.IsZero() {
+		t.Logf("Success! Got expected job %v", j)
+	} else {
+		t.Errorf("Fail! Unexpected job %v", j)
+	}
+}
+
+func TestRun(t *testing.T) {
+	storage.SwitchStorage(storage.Memory)
+	s := storage.GetStorage()
+
+	done := make(chan struct{})
+	id, err := Run(job.Rates, "", func(tr *Tracker) error {
+		defer close(done)
+		tr.Error("https://cbr.ru", errors.New("timeout"))
+		return errors.New("CBR sync already in process")
+	})
+	if err != nil {
+		t.Errorf("Fail! Unexpected error: %v", err)
+	}
+	<-done
+	// job is saved right after fn returns
+	j, _ := s.GetJob(id)
+	for i := 0; i < 100 && j.State == job.Running; i++ {
+		time.Sleep(time.Millisecond)
+		j, _ = s.GetJob(id)
+	}
+	if j.State == job.Failed && j.Error == "CBR sync already in process" && j.Processed == 0 && len(j.Errors) == 1 {
+		t.Logf("Success! Got expected job %v", j)
+	} else {
+		t.Errorf("Fail! Unexpected job %v", j)
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	var tr *Tracker
+	tr.SetTotal(1)
+	tr.Done("SBER", errors.New("test"))
+	if j := tr.Finish(nil); tr.ID() == "" && j.ID == "" {
+		t.Logf("Success! Nil tracker does nothing")
+	} else {
+		t.Errorf("Fail! Unexpected job %v", j)
+	}
+}
@@ -1,14 +1,25 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 
-	_ "github.com/kaseat/pManager/docs"
+	"github.com/kaseat/pManager/docs"
 
 	"github.com/gorilla/mux"
 	"github.com/kaseat/pManager/api"
+	"github.com/kaseat/pManager/config"
+	"github.com/kaseat/pManager/gmail"
+	"github.com/kaseat/pManager/scheduler"
+	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/sync/cbr"
+	"github.com/kaseat/pManager/sync/moex"
+	"github.com/kaseat/pManager/sync/spbex"
+	"github.com/kaseat/pManager/sync/tcs"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -22,13 +33,49 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	configPath := flag.String("config", os.Getenv("PM_CONFIG"), "path to YAML or JSON config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = storage.Configure(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+	api.Configure(cfg.Auth)
+	gmail.Configure(cfg.Gmail)
+	tcs.Configure(cfg.Sync.Tcs)
+	moex.Configure(cfg.Sync.Moex)
+	spbex.Configure(cfg.Sync.Spbex)
+	cbr.Configure(cfg.Sync.Cbr)
+	docs.SwaggerInfo.Host = cfg.Server.SwaggerHost
+
+	// pManager [-config path] migrate [version] - migrate schema and exit
+	if flag.Arg(0) == "migrate" {
+		err := migrate(flag.Args()[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	err = storage.Migrate()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = scheduler.Start()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	fmt.Println("Started!")
 
 	router := mux.NewRouter()
 
 	router.PathPrefix("/api/swagger/").Handler(httpSwagger.Handler(
-		httpSwagger.URL("https://totallink.ru/api/swagger/doc.json"), //The url pointing to API definition
+		httpSwagger.URL(fmt.Sprintf("https://%s/api/swagger/doc.json", cfg.Server.SwaggerHost)), //The url pointing to API definition
 		httpSwagger.DeepLinking(true),
 		httpSwagger.DocExpansion("none"),
 		httpSwagger.DomID("#swagger-ui"),
@@ -52,6 +99,31 @@ func main() {
 	prices.HandleFunc("", api.AddPrices).Methods("POST")
 	prices.HandleFunc("/sync", api.SyncPrices).Methods("GET")
 
+	rates := router.PathPrefix("/api/rates").Subrouter().StrictSlash(true)
+	rates.Use(api.VerifyTokenMiddleware)
+	rates.HandleFunc("", api.GetRates).Methods("GET")
+	rates.HandleFunc("/sync", api.SyncRates).Methods("GET")
+
+	events := router.PathPrefix("/api/events").Subrouter().StrictSlash(true)
+	events.Use(api.VerifyTokenMiddleware)
+	events.HandleFunc("", api.GetEvents).Methods("GET")
+	events.HandleFunc("/sync", api.SyncEvents).Methods("GET")
+
+	actions := router.PathPrefix("/api/actions").Subrouter().StrictSlash(true)
+	actions.Use(api.VerifyTokenMiddleware)
+	actions.HandleFunc("", api.GetCorporateActions).Methods("GET")
+	actions.HandleFunc("", api.AddCorporateActions).Methods("POST")
+	actions.HandleFunc("", api.DeleteCorporateActions).Methods("DELETE")
+
+	schedules := router.PathPrefix("/api/schedules").Subrouter().StrictSlash(true)
+	schedules.Use(api.VerifyTokenMiddleware)
+	schedules.HandleFunc("", api.GetSchedules).Methods("GET")
+	schedules.HandleFunc("/{id}", api.UpdateSchedule).Methods("PUT")
+
+	jobs := router.PathPrefix("/api/jobs").Subrouter().StrictSlash(true)
+	jobs.Use(api.VerifyTokenMiddleware)
+	jobs.HandleFunc("/{id}", api.GetJob).Methods("GET")
+
 	portfolios := router.PathPrefix("/api/portfolios").Subrouter().StrictSlash(true)
 	portfolios.Use(api.VerifyTokenMiddleware)
 	portfolios.HandleFunc("", api.CreateSinglePortfolio).Methods("POST")
@@ -63,12 +135,35 @@ func main() {
 	portfolios.HandleFunc("/{id}/operations", api.ReadOperations).Methods("GET")
 	portfolios.HandleFunc("/{id}/operations", api.CreateSingleOperation).Methods("POST")
 	portfolios.HandleFunc("/{id}/operations", api.DeleteAllOperations).Methods("DELETE")
+	portfolios.HandleFunc("/{id}/operations/export", api.ExportOperations).Methods("GET")
+	portfolios.HandleFunc("/{id}/operations/{opId}", api.ReadSingleOperation).Methods("GET")
+	portfolios.HandleFunc("/{id}/operations/{opId}", api.UpdateSingleOperation).Methods("PUT")
+	portfolios.HandleFunc("/{id}/operations/{opId}", api.DeleteSingleOperation).Methods("DELETE")
 	portfolios.HandleFunc("/{id}/securities", api.GetSecuritiesForPortfolio).Methods("GET")
 	portfolios.HandleFunc("/{id}/average", api.GetAveragePrice).Methods("GET")
 	portfolios.HandleFunc("/{id}/balance", api.GetBalance).Methods("GET")
+	portfolios.HandleFunc("/{id}/realized", api.GetRealized).Methods("GET")
+	portfolios.HandleFunc("/{id}/tax", api.GetTaxReport).Methods("GET")
+	portfolios.HandleFunc("/{id}/income", api.GetIncome).Methods("GET")
+	portfolios.HandleFunc("/{id}/calendar", api.GetCalendar).Methods("GET")
+	portfolios.HandleFunc("/{id}/history", api.GetHistory).Methods("GET")
+	portfolios.HandleFunc("/{id}/performance", api.GetPerformance).Methods("GET")
 	portfolios.HandleFunc("/{id}/sync", api.SyncOperations).Methods("GET")
+	portfolios.HandleFunc("/{id}/import", api.ImportReports).Methods("POST")
+	portfolios.HandleFunc("/{id}/schedule", api.SetGmailSchedule).Methods("PUT")
 	router.HandleFunc("/api/user/login", api.Login).Methods("POST")
 	router.HandleFunc("/api/user/signup", api.SignUp).Methods("POST")
 	router.HandleFunc("/api/google/callback", api.AppCallback).Methods("GET")
-	log.Fatal(http.ListenAndServe(":8081", router))
+	log.Fatal(http.ListenAndServe(cfg.Server.Addr, router))
+}
+
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return storage.Migrate()
+	}
+	version, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid schema version %s. Expected non-negative number", args[0])
+	}
+	return storage.MigrateTo(version)
 }
@@ -0,0 +1,17 @@
+package action
+
+// Type is corporate action type
+type Type string
+
+const (
+	// Split - every RatioFrom units become RatioTo units,
+	// reverse split has RatioFrom greater than RatioTo
+	Split Type = "split"
+	// Rename - ISIN or ticker of security changes
+	Rename Type = "rename"
+	// SpinOff - holders receive RatioTo units of new security
+	// for every RatioFrom units held
+	SpinOff Type = "spinOff"
+	// Conversion - every RatioFrom units are exchanged to RatioTo units of new security
+	Conversion Type = "conversion"
+)
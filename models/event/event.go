@@ -0,0 +1,15 @@
+package event
+
+// Type is security event type
+type Type string
+
+const (
+	// Coupon - bond coupon payment
+	Coupon Type = "coupon"
+	// Amortization - repayment of bond face value, including final redemption
+	Amortization Type = "amortization"
+	// Offer - bond put offer, holder may sell bonds back to issuer
+	Offer Type = "offer"
+	// Dividend - dividend of shares holders on record date
+	Dividend Type = "dividend"
+)
@@ -0,0 +1,31 @@
+package job
+
+// Type represents background job type
+type Type string
+
+const (
+	// Prices - sync of daily instrument prices
+	Prices Type = "prices"
+	// Rates - sync of CBR currency rates
+	Rates Type = "rates"
+	// Instruments - refresh of instruments dimension from TCS
+	Instruments Type = "instruments"
+	// Gmail - import of broker reports from Gmail
+	Gmail Type = "gmail"
+	// Tcs - import of operations from Tinkoff Invest API
+	Tcs Type = "tcs"
+	// Calendar - sync of bond payments and dividends from MOEX ISS
+	Calendar Type = "calendar"
+)
+
+// State represents background job state
+type State string
+
+const (
+	// Running - job is in process
+	Running State = "running"
+	// Completed - job finished, some items may have failed
+	Completed State = "completed"
+	// Failed - job aborted with error
+	Failed State = "failed"
+)
@@ -1,12 +1,18 @@
 package models
 
 import (
+	"crypto/md5"
+	"fmt"
 	"time"
 
+	"github.com/kaseat/pManager/models/action"
 	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/event"
 	"github.com/kaseat/pManager/models/exchange"
 	"github.com/kaseat/pManager/models/instrument"
+	"github.com/kaseat/pManager/models/job"
 	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/models/provider"
 )
 
 // Price represents price element
@@ -52,6 +58,9 @@ type Operation struct {
 	Ticker        string         `json:"ticker" example:"VOO"`
 	DateTime      time.Time      `json:"date" example:"2020-06-06T15:54:05Z"`
 	OperationType operation.Type `json:"type" example:"sell"`
+	// Source and ExternalID identify imported operation at broker side
+	Source     provider.Type `json:"-"`
+	ExternalID string        `json:"-"`
 }
 
 // Portfolio represets a range of investments
@@ -76,3 +85,213 @@ type OperationSorter []Operation
 func (a OperationSorter) Len() int           { return len(a) }
 func (a OperationSorter) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a OperationSorter) Less(i, j int) bool { return a[i].DateTime.Unix() < a[j].DateTime.Unix() }
+
+// Fingerprint returns natural key of operation which prevents importing it twice.
+// Broker operation id is used when known, otherwise operation is identified
+// by md5 hash of its time, security, type, volume and price
+func (op Operation) Fingerprint() string {
+	if op.Source != "" && op.ExternalID != "" {
+		return fmt.Sprintf("%s:%s:%s", op.Source, op.ExternalID, op.OperationType)
+	}
+	security := op.ISIN
+	if security == "" {
+		security = op.Ticker
+	}
+	// keep in sync with fingerprint backfill of postgres migrations 6 and 11
+	key := fmt.Sprintf("%s|%s|%s|%d|%.6f", op.DateTime.UTC().Format("2006-01-02T15:04:05"), security, op.OperationType, op.Volume, op.Price)
+	return fmt.Sprintf("%x", md5.Sum([]byte(key)))
+}
+
+// Fingerprints returns fingerprints of operations imported in one batch.
+// Identical operations without broker id (e.g. several fills of one order)
+// get their ordinal within the batch appended, so they are all kept,
+// while repeated import of the same report gives the same fingerprints
+func Fingerprints(ops []Operation) []string {
+	result := make([]string, len(ops))
+	seen := make(map[string]int, len(ops))
+	for i, op := range ops {
+		fp := op.Fingerprint()
+		result[i] = fp
+		if op.Source != "" && op.ExternalID != "" {
+			continue
+		}
+		if n := seen[fp]; n > 0 {
+			result[i] = fmt.Sprintf("%s#%d", fp, n)
+		}
+		seen[fp]++
+	}
+	return result
+}
+
+// Lot represents part of buy operation closed by sell
+type Lot struct {
+	OperationID string    `json:"buyId" example:"5edbc0a72c857652a0542fab"`
+	Date        time.Time `json:"buyDate" example:"2020-06-06T15:54:05Z"`
+	Price       float64   `json:"price" example:"293.61"`
+	Volume      int64     `json:"vol" example:"100"`
+	Fee         float64   `json:"fee" example:"14.68"`
+	CostBasis   float64   `json:"costBasis" example:"29375.68"`
+	HoldingDays int       `json:"holdingDays" example:"365"`
+}
+
+// RealizedTrade represents realized result of single sell operation
+type RealizedTrade struct {
+	OperationID string        `json:"sellId" example:"5edbc0a72c857652a0542fab"`
+	ISIN        string        `json:"isin" example:"US9229083632"`
+	Ticker      string        `json:"ticker" example:"VOO"`
+	Currency    currency.Type `json:"currency" example:"USD"`
+	Date        time.Time     `json:"sellDate" example:"2020-06-06T15:54:05Z"`
+	Price       float64       `json:"price" example:"310.15"`
+	Volume      int64         `json:"vol" example:"100"`
+	Fee         float64       `json:"fee" example:"15.51"`
+	Proceeds    float64       `json:"proceeds" example:"30999.49"`
+	CostBasis   float64       `json:"costBasis" example:"29375.68"`
+	Gain        float64       `json:"gain" example:"1623.81"`
+	Lots        []Lot         `json:"lots"`
+}
+
+// TaxReport represents personal income tax (NDFL) report for calendar year.
+// All amounts are in rubles
+type TaxReport struct {
+	Year                    int             `json:"year" example:"2020"`
+	Trades                  []RealizedTrade `json:"trades"`
+	Proceeds                float64         `json:"proceeds" example:"30999.49"`
+	CostBasis               float64         `json:"costBasis" example:"29375.68"`
+	Fees                    float64         `json:"fees" example:"30.19"`
+	RealizedGain            float64         `json:"realizedGain" example:"1623.81"`
+	Coupons                 float64         `json:"coupons" example:"412.5"`
+	AccruedInterestReceived float64         `json:"accruedInterestReceived" example:"12.3"`
+	AccruedInterestPaid     float64         `json:"accruedInterestPaid" example:"25.7"`
+	TaxBase                 float64         `json:"taxBase" example:"2022.91"`
+	TaxRate                 float64         `json:"taxRate" example:"0.13"`
+	Tax                     float64         `json:"tax" example:"263"`
+}
+
+// Income represents coupons and dividends received from single security in given month.
+// Gross is income before withholding tax, Net is income after it
+type Income struct {
+	Month     string        `json:"month" example:"2020-06"`
+	ISIN      string        `json:"isin" example:"RU0009029540"`
+	Ticker    string        `json:"ticker" example:"SBER"`
+	Currency  currency.Type `json:"currency" example:"RUB"`
+	Coupons   float64       `json:"coupons" example:"0"`
+	Dividends float64       `json:"dividends" example:"1860"`
+	Gross     float64       `json:"gross" example:"1860"`
+	Tax       float64       `json:"tax" example:"242"`
+	Net       float64       `json:"net" example:"1618"`
+}
+
+// HistoryPoint represents portfolio valuation on given date
+type HistoryPoint struct {
+	Date        time.Time                 `json:"date" example:"2020-06-06T00:00:00Z"`
+	MarketValue map[currency.Type]float64 `json:"marketValue"`
+	Cash        map[currency.Type]float64 `json:"cash"`
+	Invested    map[currency.Type]float64 `json:"invested"`
+
+	ReportCurrency   currency.Type `json:"reportCurrency,omitempty" example:"RUB"`
+	TotalMarketValue float64       `json:"totalMarketValue,omitempty" example:"250000"`
+	TotalCash        float64       `json:"totalCash,omitempty" example:"1200.5"`
+	TotalInvested    float64       `json:"totalInvested,omitempty" example:"200000"`
+}
+
+// Performance represents portfolio return for given period.
+// XIRR is annualized, TWR is cumulative for the period
+type Performance struct {
+	Period   string        `json:"period" example:"ytd"`
+	Currency currency.Type `json:"currency" example:"RUB"`
+	From     time.Time     `json:"from" example:"2020-01-01T00:00:00Z"`
+	To       time.Time     `json:"to" example:"2020-06-06T00:00:00Z"`
+	XIRR     *float64      `json:"xirr,omitempty" example:"0.1234"`
+	TWR      float64       `json:"twr" example:"0.0812"`
+}
+
+// SecurityEvent represents scheduled payment or offer of security.
+// Value is amount per security unit, for offers it is offer price
+type SecurityEvent struct {
+	ISIN     string        `json:"isin" example:"RU000A0JSMA2"`
+	Type     event.Type    `json:"type" example:"coupon"`
+	Date     time.Time     `json:"date" example:"2020-09-16T00:00:00Z"`
+	Value    float64       `json:"value" example:"38.39"`
+	Currency currency.Type `json:"currency" example:"RUB"`
+}
+
+// CorporateAction represents split, rename, spin-off or conversion of security.
+// Operations made before action date are restated in terms of resulting security.
+// CostRatio is part of cost basis moved to new security on spin-off
+type CorporateAction struct {
+	ISIN      string      `json:"isin" example:"US0378331005"`
+	Type      action.Type `json:"type" example:"split"`
+	Date      time.Time   `json:"date" example:"2020-08-31T00:00:00Z"`
+	RatioFrom int64       `json:"ratioFrom" example:"1"`
+	RatioTo   int64       `json:"ratioTo" example:"4"`
+	NewISIN   string      `json:"newIsin,omitempty" example:""`
+	NewTicker string      `json:"newTicker,omitempty" example:""`
+	CostRatio float64     `json:"costRatio,omitempty" example:"0"`
+}
+
+// CalendarEntry represents expected cash inflow from current holding
+type CalendarEntry struct {
+	Date     time.Time     `json:"date" example:"2020-09-16T00:00:00Z"`
+	ISIN     string        `json:"isin" example:"RU000A0JSMA2"`
+	Ticker   string        `json:"ticker" example:"SU26209RMFS5"`
+	Type     event.Type    `json:"type" example:"coupon"`
+	Currency currency.Type `json:"currency" example:"RUB"`
+	Value    float64       `json:"value" example:"38.39"`
+	Volume   int64         `json:"vol" example:"10"`
+	Amount   float64       `json:"amount" example:"383.9"`
+}
+
+// Rate represents official rate of currency in rubles
+type Rate struct {
+	Currency currency.Type `json:"currency" example:"USD"`
+	Date     time.Time     `json:"date" example:"2020-06-06T00:00:00Z"`
+	Rate     float64       `json:"rate" example:"68.6319"`
+}
+
+// Schedule represents periodic background job and result of its last run
+type Schedule struct {
+	ID          string    `json:"id" example:"prices"`
+	Job         job.Type  `json:"job" example:"prices"`
+	Cron        string    `json:"cron" example:"0 3 * * *"`
+	Enabled     bool      `json:"enabled" example:"true"`
+	Login       string    `json:"login,omitempty" example:"mark123"`
+	PortfolioID string    `json:"pid,omitempty" example:"1"`
+	LastRun     time.Time `json:"lastRun,omitempty" example:"2020-06-06T03:00:00Z"`
+	LastJobID   string    `json:"lastJobId,omitempty" example:"42"`
+	LastStatus  string    `json:"lastStatus,omitempty" example:"ok"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+// Job represents single run of background sync
+type Job struct {
+	ID        string     `json:"id" example:"42"`
+	Type      job.Type   `json:"type" example:"prices"`
+	Login     string     `json:"login,omitempty" example:"mark123"`
+	State     job.State  `json:"state" example:"running"`
+	Started   time.Time  `json:"started" example:"2020-06-06T03:00:00Z"`
+	Finished  time.Time  `json:"finished,omitempty" example:"2020-06-06T03:05:00Z"`
+	Total     int        `json:"total" example:"100"`
+	Processed int        `json:"processed" example:"42"`
+	Error     string     `json:"error,omitempty" example:"No TCS token found"`
+	Errors    []JobError `json:"errors,omitempty"`
+}
+
+// JobError represents failure of single item processed by job
+type JobError struct {
+	Item  string `json:"item" example:"IDCC"`
+	Error string `json:"error" example:"no price provider for SPBEX exchange"`
+}
+
+// ListQuery describes filtering, sorting and pagination of list requests.
+// Values of a single filter are combined with OR, different filters with AND
+type ListQuery struct {
+	Filters map[string][]string
+	// From and To bound item date in RFC3339 format, empty means unbounded
+	From string
+	To   string
+	// Sort lists field names, descending ones are prefixed with minus
+	Sort []string
+	// Limit is max number of returned items, zero means no limit
+	Limit  int
+	Offset int
+}
@@ -24,6 +24,15 @@ const (
 	AccInterestSell Type = "accruedInterestSell"
 	// Buyback operation
 	Buyback Type = "buyback"
+	// Dividend operation
+	Dividend Type = "dividend"
+	// Tax operation
+	Tax Type = "tax"
+	// WithholdingTax operation is tax withheld from coupon or dividend
+	WithholdingTax Type = "withholdingTax"
+	// Amortization operation is partial repayment of bond nominal.
+	// It brings cash but keeps number of bonds
+	Amortization Type = "amortization"
 	// Unknown operation
 	Unknown Type = "unknown"
 )
@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron represents parsed cron expression with five fields:
+// minute, hour, day of month, month and day of week
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// day fields restriction, see matchDay
+	domAny, dowAny bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var fields = [...]cronField{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week, 0 and 7 are both Sunday
+}
+
+var macros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseCron parses cron expression like "0 3 * * 1-5". Each field supports
+// '*', single values, ranges, steps and comma separated lists of them.
+// Macros @hourly, @daily, @weekly and @monthly are also supported
+func ParseCron(spec string) (Cron, error) {
+	if m, ok := macros[strings.TrimSpace(spec)]; ok {
+		spec = m
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return Cron{}, fmt.Errorf("invalid cron expression '%s'. Expected 5 fields, got %d", spec, len(parts))
+	}
+
+	var bits [len(fields)]uint64
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return Cron{}, fmt.Errorf("invalid cron expression '%s': %v", spec, err)
+		}
+		bits[i] = b
+	}
+	// Sunday may be set either as 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return Cron{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+func parseField(value string, f cronField) (uint64, error) {
+	var result uint64
+	for _, item := range strings.Split(value, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step in '%s'", item)
+			}
+			rng, step = item[:i], s
+		}
+
+		from, to := f.min, f.max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value '%s'", item)
+			}
+			to = from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value '%s'", item)
+				}
+			} else if step > 1 {
+				to = f.max
+			}
+		}
+		if from < f.min || to > f.max || from > to {
+			return 0, fmt.Errorf("value '%s' out of range %d-%d", item, f.min, f.max)
+		}
+
+		for v := from; v <= to; v += step {
+			result |= 1 << uint(v)
+		}
+	}
+	return result, nil
+}
+
+// Match checks whether cron fires at given minute
+func (c Cron) Match(t time.Time) bool {
+	return c.minute&(1<<uint(t.Minute())) != 0 &&
+		c.hour&(1<<uint(t.Hour())) != 0 &&
+		c.month&(1<<uint(t.Month())) != 0 &&
+		c.matchDay(t)
+}
+
+// Next returns first time after given one cron fires at.
+// Zero time returned if cron never fires, e.g. "0 0 31 2 *"
+func (c Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// any valid expression fires at least once in 4 years (Feb 29)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 || !c.matchDay(t) {
+			y, m, d := t.Date()
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			y, m, d := t.Date()
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay follows classic cron rule: when both day of month and
+// day of week are restricted, either of them has to match
+func (c Cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
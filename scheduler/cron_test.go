@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	invalid := []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"}
+	for _, spec := range invalid {
+		if _, err := ParseCron(spec); err != nil {
+			t.Logf("Success! Got expected error for '%s': %v", spec, err)
+		} else {
+			t.Errorf("Fail! Expected error for '%s'", spec)
+		}
+	}
+}
+
+func TestCronMatch(t *testing.T) {
+	tests := []struct {
+		spec     string
+		time     time.Time
+		expected bool
+	}{
+		{"0 3 * * *", time.Date(2020, 6, 6, 3, 0, 0, 0, time.UTC), true},
+		{"0 3 * * *", time.Date(2020, 6, 6, 3, 1, 0, 0, time.UTC), false},
+		{"*/15 * * * *", time.Date(2020, 6, 6, 10, 45, 0, 0, time.UTC), true},
+		{"*/15 * * * *", time.Date(2020, 6, 6, 10, 50, 0, 0, time.UTC), false},
+		{"0 9-18/3 * * *", time.Date(2020, 6, 6, 15, 0, 0, 0, time.UTC), true},
+		{"0 9-18/3 * * *", time.Date(2020, 6, 6, 16, 0, 0, 0, time.UTC), false},
+		{"0 4 * * 0", time.Date(2020, 6, 7, 4, 0, 0, 0, time.UTC), true},
+		{"0 4 * * 7", time.Date(2020, 6, 7, 4, 0, 0, 0, time.UTC), true},
+		{"0 4 * * 1-5", time.Date(2020, 6, 7, 4, 0, 0, 0, time.UTC), false},
+		{"0 0 1,15 * *", time.Date(2020, 6, 15, 0, 0, 0, 0, time.UTC), true},
+		// day of month or day of week when both are restricted
+		{"0 0 1 * 1", time.Date(2020, 6, 8, 0, 0, 0, 0, time.UTC), true},
+		{"0 0 1 * 1", time.Date(2020, 6, 9, 0, 0, 0, 0, time.UTC), false},
+		{"@weekly", time.Date(2020, 6, 7, 0, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, test := range tests {
+		c, err := ParseCron(test.spec)
+		if err != nil {
+			t.Errorf("Fail! Unexpected error for '%s': %v", test.spec, err)
+			continue
+		}
+		if res := c.Match(test.time); res == test.expected {
+			t.Logf("Success! '%s' at %v: expected %v, got %v", test.spec, test.time, test.expected, res)
+		} else {
+			t.Errorf("Fail! '%s' at %v: expected %v, got %v", test.spec, test.time, test.expected, res)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	tests := []struct {
+		spec     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"0 3 * * *", time.Date(2020, 6, 6, 3, 0, 0, 0, time.UTC), time.Date(2020, 6, 7, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2020, 6, 6, 2, 59, 30, 0, time.UTC), time.Date(2020, 6, 6, 3, 0, 0, 0, time.UTC)},
+		{"0 4 * * 0", time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 6, 7, 4, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), time.Time{}},
+	}
+
+	for _, test := range tests {
+		c, _ := ParseCron(test.spec)
+		if res := c.Next(test.from); res.Equal(test.expected) {
+			t.Logf("Success! '%s' after %v: expected %v, got %v", test.spec, test.from, test.expected, res)
+		} else {
+			t.Errorf("Fail! '%s' after %v: expected %v, got %v", test.spec, test.from, test.expected, res)
+		}
+	}
+}
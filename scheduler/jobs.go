@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/sync/cbr"
+	"github.com/kaseat/pManager/sync/moex"
+	"github.com/kaseat/pManager/sync/prices"
+	"github.com/kaseat/pManager/sync/sberbank"
+	"github.com/kaseat/pManager/sync/tcs"
+)
+
+// Runner runs background job described by schedule
+type Runner func(tr *jobs.Tracker, sch models.Schedule) error
+
+var runners = map[job.Type]Runner{
+	job.Prices:      syncPrices,
+	job.Rates:       syncRates,
+	job.Instruments: syncInstruments,
+	job.Gmail:       syncGmail,
+	job.Calendar:    syncCalendar,
+}
+
+// schedules created on first start, can be changed via API afterwards
+var defaults = []models.Schedule{
+	{ID: string(job.Rates), Job: job.Rates, Cron: "30 2 * * *", Enabled: true},
+	{ID: string(job.Prices), Job: job.Prices, Cron: "0 3 * * *", Enabled: true},
+	{ID: string(job.Instruments), Job: job.Instruments, Cron: "0 4 * * 0", Enabled: true},
+	{ID: string(job.Calendar), Job: job.Calendar, Cron: "0 5 * * 0", Enabled: true},
+}
+
+// GmailScheduleID returns id of Gmail import schedule of given user
+func GmailScheduleID(login string) string {
+	return string(job.Gmail) + ":" + login
+}
+
+func newClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+func syncPrices(tr *jobs.Tracker, sch models.Schedule) error {
+	return prices.Sync(tr, newClient())
+}
+
+func syncRates(tr *jobs.Tracker, sch models.Schedule) error {
+	return cbr.Sync(tr, newClient())
+}
+
+func syncInstruments(tr *jobs.Tracker, sch models.Schedule) error {
+	return tcs.SyncInstruments(tr)
+}
+
+func syncCalendar(tr *jobs.Tracker, sch models.Schedule) error {
+	return moex.SyncCalendar(tr, newClient())
+}
+
+func syncGmail(tr *jobs.Tracker, sch models.Schedule) error {
+	return sberbank.SyncGmail(tr, sch.Login, sch.PortfolioID, "", "")
+}
@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage"
+)
+
+var (
+	mu      sync.Mutex
+	running = map[job.Type]bool{}
+	wg      sync.WaitGroup
+	stop    chan struct{}
+)
+
+// Start creates default schedules if missing and starts checking
+// schedules stored in settings at the beginning of every minute
+func Start() error {
+	if err := seed(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		return nil
+	}
+	stop = make(chan struct{})
+	go loop(stop)
+	return nil
+}
+
+// Stop stops scheduler and waits for running jobs to finish
+func Stop() {
+	mu.Lock()
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+	mu.Unlock()
+	wg.Wait()
+}
+
+func seed() error {
+	s := storage.GetStorage()
+	schedules, err := s.GetSchedules()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(schedules))
+	for _, sch := range schedules {
+		existing[sch.ID] = true
+	}
+	for _, sch := range defaults {
+		if existing[sch.ID] {
+			continue
+		}
+		if err = s.SaveSchedule(sch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loop(stop chan struct{}) {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-stop:
+			return
+		case <-time.After(next.Sub(now)):
+			tick(next)
+		}
+	}
+}
+
+// tick starts jobs due at given minute. Schedules of the same job type
+// run one after another, and if previous run of the type is not finished
+// yet they are skipped so syncs never overlap
+func tick(t time.Time) {
+	schedules, err := storage.GetStorage().GetSchedules()
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error get schedules:", err)
+		return
+	}
+
+	due := map[job.Type][]models.Schedule{}
+	for _, sch := range schedules {
+		if !sch.Enabled {
+			continue
+		}
+		c, err := ParseCron(sch.Cron)
+		if err != nil {
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error parse schedule", sch.ID+":", err)
+			continue
+		}
+		if c.Match(t) {
+			due[sch.Job] = append(due[sch.Job], sch)
+		}
+	}
+
+	for jobType, list := range due {
+		runner, ok := runners[jobType]
+		if !ok {
+			for _, sch := range list {
+				record(sch.ID, t, "", fmt.Errorf("unknown job type '%s'", jobType))
+			}
+			continue
+		}
+
+		mu.Lock()
+		if running[jobType] {
+			mu.Unlock()
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Skip", jobType, "job: previous run is not finished")
+			continue
+		}
+		running[jobType] = true
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(jobType job.Type, runner Runner, list []models.Schedule) {
+			defer func() {
+				mu.Lock()
+				delete(running, jobType)
+				mu.Unlock()
+				wg.Done()
+			}()
+			for _, sch := range list {
+				fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Run scheduled job", sch.ID)
+				tr, err := jobs.Start(sch.Job, sch.Login)
+				if err != nil {
+					record(sch.ID, t, "", err)
+					continue
+				}
+				j := tr.Finish(runner(tr, sch))
+				record(sch.ID, t, j.ID, result(j))
+			}
+		}(jobType, runner, list)
+	}
+}
+
+// result converts final job state to the run error
+func result(j models.Job) error {
+	if j.State == job.Failed {
+		return errors.New(j.Error)
+	}
+	if len(j.Errors) != 0 {
+		return fmt.Errorf("%d items failed, see job %s for details", len(j.Errors), j.ID)
+	}
+	return nil
+}
+
+// record saves result of the run to the schedule. Schedule is re-read
+// since it might be changed while the job was running
+func record(id string, t time.Time, jobID string, runErr error) {
+	s := storage.GetStorage()
+	schedules, err := s.GetSchedules()
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error get schedules:", err)
+		return
+	}
+	for _, sch := range schedules {
+		if sch.ID != id {
+			continue
+		}
+		sch.LastRun = t
+		sch.LastJobID = jobID
+		if runErr != nil {
+			sch.LastStatus = "error"
+			sch.LastError = runErr.Error()
+		} else {
+			sch.LastStatus = "ok"
+			sch.LastError = ""
+		}
+		if err = s.SaveSchedule(sch); err != nil {
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error save schedule", id+":", err)
+		}
+		return
+	}
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage"
+)
+
+func TestTick(t *testing.T) {
+	storage.SwitchStorage(storage.Memory)
+	s := storage.GetStorage()
+	if err := seed(); err != nil {
+		t.Errorf("Fail! Unexpected error: %v", err)
+	}
+	schedules, _ := s.GetSchedules()
+	if len(schedules) == len(defaults) {
+		t.Logf("Success! Expected %v schedules, got %v", len(defaults), len(schedules))
+	} else {
+		t.Errorf("Fail! Expected %v schedules, got %v", len(defaults), schedules)
+	}
+
+	defer func(r map[job.Type]Runner) { runners = r }(runners)
+	calls := []string{}
+	release := make(chan struct{})
+	runners = map[job.Type]Runner{
+		job.Prices: func(tr *jobs.Tracker, sch models.Schedule) error {
+			<-release
+			calls = append(calls, sch.ID)
+			tr.SetTotal(2)
+			tr.Done("SBER", nil)
+			tr.Done("IDCC", errors.New("no price provider"))
+			return nil
+		},
+		job.Gmail: func(tr *jobs.Tracker, sch models.Schedule) error {
+			calls = append(calls, sch.ID)
+			return errors.New("no token")
+		},
+	}
+	s.SaveSchedule(models.Schedule{ID: GmailScheduleID("test"), Job: job.Gmail, Cron: "0 5 * * *", Enabled: true, Login: "test", PortfolioID: "1"})
+	s.SaveSchedule(models.Schedule{ID: GmailScheduleID("off"), Job: job.Gmail, Cron: "0 5 * * *", Enabled: false})
+
+	gmailTime := time.Date(2020, 6, 6, 5, 0, 0, 0, time.UTC)
+	tick(gmailTime)
+	wg.Wait()
+
+	pricesTime := time.Date(2020, 6, 6, 3, 0, 0, 0, time.UTC)
+	tick(pricesTime)
+	// previous run is not finished, so this one is skipped
+	tick(pricesTime)
+	close(release)
+	wg.Wait()
+
+	if len(calls) == 2 && calls[0] == GmailScheduleID("test") && calls[1] == string(job.Prices) {
+		t.Logf("Success! Got expected calls %v", calls)
+	} else {
+		t.Errorf("Fail! Unexpected calls %v", calls)
+	}
+
+	schedules, _ = s.GetSchedules()
+	for _, sch := range schedules {
+		switch sch.ID {
+		case GmailScheduleID("test"):
+			j, _ := s.GetJob(sch.LastJobID)
+			if sch.LastRun.Equal(gmailTime) && sch.LastStatus == "error" && sch.LastError == "no token" &&
+				j.State == job.Failed && j.Login == "test" {
+				t.Logf("Success! Got expected last run %v", sch)
+			} else {
+				t.Errorf("Fail! Unexpected last run %v", sch)
+			}
+		case string(job.Prices):
+			j, _ := s.GetJob(sch.LastJobID)
+			if sch.LastRun.Equal(pricesTime) && sch.LastStatus == "error" &&
+				j.State == job.Completed && j.Processed == 2 && len(j.Errors) == 1 {
+				t.Logf("Success! Got expected last run %v", sch)
+			} else {
+				t.Errorf("Fail! Unexpected last run %v", sch)
+			}
+		default:
+			if sch.LastRun.IsZero() {
+				t.Logf("Success! Schedule %v was not run", sch.ID)
+			} else {
+				t.Errorf("Fail! Schedule %v unexpectedly run at %v", sch.ID, sch.LastRun)
+			}
+		}
+	}
+}
@@ -1,12 +1,17 @@
 package storage
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/kaseat/pManager/config"
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
 	"github.com/kaseat/pManager/models/provider"
+	"github.com/kaseat/pManager/storage/memory"
 	"github.com/kaseat/pManager/storage/mongo"
 	"github.com/kaseat/pManager/storage/postgres"
+	"github.com/kaseat/pManager/storage/sqlite"
 	"golang.org/x/oauth2"
 )
 
@@ -18,6 +23,10 @@ const (
 	Mongo Type = "mongo"
 	// Postgres - PostgreSQL storage
 	Postgres Type = "postgres"
+	// Memory - in-memory storage, data is lost on restart
+	Memory Type = "memory"
+	// Sqlite - SQLite file storage for single-user deployments
+	Sqlite Type = "sqlite"
 )
 
 // Db represents data storage
@@ -46,6 +55,9 @@ type Db interface {
 	AddOperation(portfolioID string, op models.Operation) (string, error)
 	AddOperations(portfolioID string, ops []models.Operation) ([]string, error)
 	GetOperations(portfolioID string, key string, value string, from string, to string) ([]models.Operation, error)
+	FindOperations(portfolioID string, q models.ListQuery) ([]models.Operation, int64, error)
+	GetOperation(portfolioID string, operationID string) (models.Operation, error)
+	UpdateOperation(portfolioID string, operationID string, op models.Operation) (bool, error)
 	DeleteOperation(portfolioID string, operationID string) (bool, error)
 	DeleteOperations(portfolioID string) (int64, error)
 
@@ -55,25 +67,66 @@ type Db interface {
 	ClearAllInstrumentPriceUptdTime() (bool, error)
 	GetInstruments(key string, value string) ([]models.Instrument, error)
 	GetAllInstruments() ([]models.Instrument, error)
+	FindInstruments(q models.ListQuery) ([]models.Instrument, int64, error)
 	DeleteInstruments(key string, value string) (int64, error)
 	DeleteAllInstruments() (int64, error)
 
 	AddPrices(prices []models.Price) error
 	GetPrices(key, value, from, to string) ([]models.Price, error)
 	GetPricesByIsin(isin, from, to string) ([]models.Price, error)
+	FindPrices(q models.ListQuery) ([]models.Price, int64, error)
 	DeletePrices(key string, value string) (int64, error)
 	DeleteAllPrices() (int64, error)
 
+	AddRates(rates []models.Rate) error
+	GetRates(curr currency.Type, from, to string) ([]models.Rate, error)
+	DeleteRates(curr currency.Type) (int64, error)
+	SetRateUptdTime(curr currency.Type, updTime time.Time) (bool, error)
+	GetRateUptdTime(curr currency.Type) (time.Time, error)
+
+	AddSecurityEvents(events []models.SecurityEvent) error
+	GetSecurityEvents(isin, from, to string) ([]models.SecurityEvent, error)
+	DeleteSecurityEvents(isin string) (int64, error)
+
+	AddCorporateActions(actions []models.CorporateAction) error
+	GetCorporateActions(isin string) ([]models.CorporateAction, error)
+	DeleteCorporateActions(isin string) (int64, error)
+
 	GetShares(pid string, onDate string) ([]models.Share, error)
 
 	AddTcsToken(token string) error
 	DeleteTcsToken() error
 	GetTcsToken() (string, error)
+
+	GetSchedules() ([]models.Schedule, error)
+	SaveSchedule(sch models.Schedule) error
+	DeleteSchedule(id string) (bool, error)
+
+	AddJob(j models.Job) (string, error)
+	UpdateJob(j models.Job) (bool, error)
+	GetJob(id string) (models.Job, error)
 }
 
 var dbMongo mongo.Db
 var dbPostgres postgres.Db
+var dbMemory memory.Db
+var dbSqlite sqlite.Db
 var currentStorage Type = Postgres
+var storageConfig = config.Default().Storage
+
+// Configure sets storage type and connection settings and opens the storage.
+// Must be called before first GetStorage call
+func Configure(c config.Storage) error {
+	t := Type(c.Type)
+	switch t {
+	case Postgres, Mongo, Memory, Sqlite:
+	default:
+		return fmt.Errorf("unknown storage type '%s'. Expected '%s', '%s', '%s' or '%s'", c.Type, Postgres, Mongo, Memory, Sqlite)
+	}
+	storageConfig = c
+	currentStorage = t
+	return initStorage(t)
+}
 
 // SwitchStorage switches storage
 func SwitchStorage(t Type) {
@@ -82,25 +135,67 @@ func SwitchStorage(t Type) {
 
 // GetStorage gets storage
 func GetStorage() Db {
+	if err := initStorage(currentStorage); err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error init storage:", err)
+	}
 	switch currentStorage {
+	case Postgres:
+		return dbPostgres
+	case Mongo:
+		return dbMongo
+	case Memory:
+		return dbMemory
+	case Sqlite:
+		return dbSqlite
+	default:
+		return nil
+	}
+}
+
+// initStorage opens storage of given type unless it is already open
+func initStorage(t Type) error {
+	switch t {
 	case Postgres:
 		if !dbPostgres.IsInitialized() {
-			dbPostgres = postgres.Db{}
-			dbPostgres.Init(postgres.Config{
-				ConnString: "host=localhost port=5432 dbname=p_manager user=test password=test",
+			db := postgres.Db{}
+			err := db.Init(postgres.Config{
+				ConnString: storageConfig.Postgres.ConnString,
 			})
+			if err != nil {
+				return err
+			}
+			dbPostgres = db
 		}
-		return dbPostgres
 	case Mongo:
 		if !dbMongo.IsInitialized() {
-			dbMongo = mongo.Db{}
-			dbMongo.Init(mongo.Config{
-				MongoURL: "mongodb://localhost:27017",
-				DbName:   "p_manager",
+			db := mongo.Db{}
+			err := db.Init(mongo.Config{
+				MongoURL: storageConfig.Mongo.URL,
+				DbName:   storageConfig.Mongo.DbName,
 			})
+			if err != nil {
+				return err
+			}
+			dbMongo = db
+		}
+	case Memory:
+		if !dbMemory.IsInitialized() {
+			dbMemory = memory.Db{}
+			dbMemory.Init()
+		}
+	case Sqlite:
+		if !dbSqlite.IsInitialized() {
+			db := sqlite.Db{}
+			err := db.Init(sqlite.Config{
+				Path: storageConfig.Sqlite.Path,
+			})
+			if err != nil {
+				return err
+			}
+			dbSqlite = db
 		}
-		return dbMongo
 	default:
-		return nil
+		return fmt.Errorf("unknown storage type '%s'", t)
 	}
+	return nil
 }
@@ -0,0 +1,26 @@
+package storage
+
+import "github.com/kaseat/pManager/storage/errs"
+
+// ErrorKind classifies storage errors returned by all backends
+type ErrorKind = errs.Kind
+
+// Error is storage error of known kind
+type Error = errs.Error
+
+const (
+	// NotFound means requested entity or its parent does not exist
+	NotFound = errs.NotFound
+	// Conflict means entity with the same key already exists
+	Conflict = errs.Conflict
+	// Forbidden means entity belongs to other user
+	Forbidden = errs.Forbidden
+	// Validation means input values cannot be stored
+	Validation = errs.Validation
+)
+
+// KindOf returns kind of given storage error.
+// Empty kind means error is not classified
+func KindOf(err error) ErrorKind {
+	return errs.KindOf(err)
+}
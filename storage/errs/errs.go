@@ -0,0 +1,46 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies storage errors, so that callers can react on them
+// without parsing messages. Values are stable and exposed to API clients
+type Kind string
+
+const (
+	// NotFound means requested entity or its parent does not exist
+	NotFound Kind = "notFound"
+	// Conflict means entity with the same key already exists
+	Conflict Kind = "conflict"
+	// Forbidden means entity belongs to other user
+	Forbidden Kind = "forbidden"
+	// Validation means input values cannot be stored
+	Validation Kind = "validation"
+)
+
+// Error is storage error of known kind. Message is safe to show to clients
+type Error struct {
+	Kind    Kind
+	Message string
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+// New makes error of given kind with formatted message
+func New(kind Kind, format string, a ...interface{}) error {
+	return Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
+}
+
+// KindOf returns kind of given error. Empty kind means error is
+// not classified, e.g. it comes from database driver
+func KindOf(err error) Kind {
+	var e Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+)
+
+// AddCorporateActions saves corporate actions into a storage. Existing actions are overwritten
+func (db Db) AddCorporateActions(actions []models.CorporateAction) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	for _, a := range actions {
+		a.Date = day(a.Date)
+		db.data.actions[actionKey{ISIN: a.ISIN, Type: a.Type, Date: a.Date}] = a
+	}
+	return nil
+}
+
+// GetCorporateActions finds corporate actions of given security sorted by date.
+// Empty ISIN means all securities
+func (db Db) GetCorporateActions(isin string) ([]models.CorporateAction, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	result := []models.CorporateAction{}
+	for _, a := range db.data.actions {
+		if isin != "" && a.ISIN != isin {
+			continue
+		}
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Date.Equal(result[j].Date) {
+			return result[i].Date.Before(result[j].Date)
+		}
+		if result[i].ISIN != result[j].ISIN {
+			return result[i].ISIN < result[j].ISIN
+		}
+		return result[i].Type < result[j].Type
+	})
+	return result, nil
+}
+
+// DeleteCorporateActions removes corporate actions of given security. Empty ISIN means all securities
+func (db Db) DeleteCorporateActions(isin string) (int64, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	count := int64(0)
+	for k := range db.data.actions {
+		if isin != "" && k.ISIN != isin {
+			continue
+		}
+		delete(db.data.actions, k)
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/provider"
+)
+
+// Init in-memory storage module
+func (db *Db) Init() {
+	db.data = &store{
+		users:        make(map[string]*user),
+		portfolios:   make(map[string]models.Portfolio),
+		operations:   make(map[string]models.Operation),
+		fingerprints: make(map[string]string),
+		instruments:  make(map[int]models.Instrument),
+		prices:       make(map[int]map[time.Time]models.Price),
+		rates:        make(map[currency.Type]map[time.Time]float64),
+		rateUpdTime:  make(map[currency.Type]time.Time),
+		events:       make(map[eventKey]models.SecurityEvent),
+		actions:      make(map[actionKey]models.CorporateAction),
+		schedules:    make(map[string]models.Schedule),
+		jobs:         make(map[string]models.Job),
+	}
+}
+
+// IsInitialized checks if db initialized
+func (db *Db) IsInitialized() bool {
+	return db.data != nil
+}
+
+// nextID generates new identifier. Must be called under write lock
+func (s *store) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *store) findUserByID(userID string) *user {
+	for _, u := range s.users {
+		if u.ID == userID {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *store) findInstrumentByISIN(isin string) (models.Instrument, bool) {
+	for _, ins := range s.instruments {
+		if ins.ISIN == isin {
+			return ins, true
+		}
+	}
+	return models.Instrument{}, false
+}
+
+func newUser(id int, login, email, hash string) *user {
+	return &user{
+		ID:       strconv.Itoa(id),
+		Login:    login,
+		Email:    email,
+		Hash:     hash,
+		LastSync: make(map[provider.Type]time.Time),
+	}
+}
+
+func parseTime(t string) (time.Time, bool) {
+	dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", t)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return dtime, true
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
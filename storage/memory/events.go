@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+)
+
+// AddSecurityEvents saves security events into a storage. Existing events are overwritten
+func (db Db) AddSecurityEvents(events []models.SecurityEvent) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	for _, e := range events {
+		e.Date = day(e.Date)
+		db.data.events[eventKey{ISIN: e.ISIN, Type: e.Type, Date: e.Date}] = e
+	}
+	return nil
+}
+
+// GetSecurityEvents finds events of given security between dates. Empty ISIN means all securities
+func (db Db) GetSecurityEvents(isin, from, to string) ([]models.SecurityEvent, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	fromTime, hasFrom := parseTime(from)
+	toTime, hasTo := parseTime(to)
+	result := []models.SecurityEvent{}
+	for _, e := range db.data.events {
+		if isin != "" && e.ISIN != isin {
+			continue
+		}
+		if hasFrom && e.Date.Before(day(fromTime)) {
+			continue
+		}
+		if hasTo && e.Date.After(toTime) {
+			continue
+		}
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Date.Equal(result[j].Date) {
+			return result[i].Date.Before(result[j].Date)
+		}
+		if result[i].ISIN != result[j].ISIN {
+			return result[i].ISIN < result[j].ISIN
+		}
+		return result[i].Type < result[j].Type
+	})
+	return result, nil
+}
+
+// DeleteSecurityEvents removes events of given security. Empty ISIN means all securities
+func (db Db) DeleteSecurityEvents(isin string) (int64, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	count := int64(0)
+	for k := range db.data.events {
+		if isin != "" && k.ISIN != isin {
+			continue
+		}
+		delete(db.data.events, k)
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddInstruments saves instruments info into a storage
+func (db Db) AddInstruments(instr []models.Instrument) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	for i, ins := range instr {
+		for _, existing := range db.data.instruments {
+			if existing.ISIN == ins.ISIN && existing.Currency == ins.Currency {
+				return errs.New(errs.Conflict, "could not add instrument: %s (%s) already exists", ins.ISIN, ins.Currency)
+			}
+		}
+		for _, other := range instr[:i] {
+			if other.ISIN == ins.ISIN && other.Currency == ins.Currency {
+				return errs.New(errs.Conflict, "could not add instrument: %s (%s) is duplicated", ins.ISIN, ins.Currency)
+			}
+		}
+	}
+
+	for _, ins := range instr {
+		ins.SecID = db.data.nextID()
+		db.data.instruments[ins.SecID] = ins
+	}
+	return nil
+}
+
+// SetInstrumentPriceUptdTime sets time instrument prise was updated
+func (db Db) SetInstrumentPriceUptdTime(sid int, updTime time.Time) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	ins, ok := db.data.instruments[sid]
+	if !ok {
+		return false, nil
+	}
+	ins.PriceUptdTime = day(updTime)
+	db.data.instruments[sid] = ins
+	return true, nil
+}
+
+// ClearInstrumentPriceUptdTime clears time instrument prise was updated
+func (db Db) ClearInstrumentPriceUptdTime(isin string) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	cleared := false
+	for sid, ins := range db.data.instruments {
+		if ins.ISIN == isin && !ins.PriceUptdTime.IsZero() {
+			ins.PriceUptdTime = time.Time{}
+			db.data.instruments[sid] = ins
+			cleared = true
+		}
+	}
+	return cleared, nil
+}
+
+// ClearAllInstrumentPriceUptdTime clears time instrument prise was updated (for all)
+func (db Db) ClearAllInstrumentPriceUptdTime() (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	cleared := false
+	for sid, ins := range db.data.instruments {
+		if !ins.PriceUptdTime.IsZero() {
+			ins.PriceUptdTime = time.Time{}
+			db.data.instruments[sid] = ins
+			cleared = true
+		}
+	}
+	return cleared, nil
+}
+
+// GetInstruments finds instruments depending on input prameters
+func (db Db) GetInstruments(key string, value string) ([]models.Instrument, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	filterByKey := key != "" && value != ""
+	if filterByKey {
+		if _, err := instrumentField(models.Instrument{}, key); err != nil {
+			return nil, err
+		}
+	}
+
+	result := []models.Instrument{}
+	for _, ins := range db.data.instruments {
+		if filterByKey {
+			if field, _ := instrumentField(ins, key); field != value {
+				continue
+			}
+		}
+		result = append(result, ins)
+	}
+	sortInstruments(result)
+	return result, nil
+}
+
+// GetAllInstruments finds all instruments
+func (db Db) GetAllInstruments() ([]models.Instrument, error) {
+	return db.GetInstruments("", "")
+}
+
+// FindInstruments finds instruments matching given query.
+// Returns requested page of instruments and total number of matched ones
+func (db Db) FindInstruments(q models.ListQuery) ([]models.Instrument, int64, error) {
+	instr, err := db.GetAllInstruments()
+	if err != nil {
+		return nil, 0, err
+	}
+	item := func(i int) models.Instrument {
+		if i < 0 {
+			return models.Instrument{}
+		}
+		return instr[i]
+	}
+	filter := func(i int, key string) (string, error) { return instrumentField(item(i), key) }
+	value := func(i int, key string) (interface{}, error) { return instrumentSortValue(item(i), key) }
+
+	page, total, err := applyQuery(len(instr), q, filter, value)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]models.Instrument, len(page))
+	for i, j := range page {
+		result[i] = instr[j]
+	}
+	return result, total, nil
+}
+
+// DeleteInstruments removes instruments depending on input prameters
+func (db Db) DeleteInstruments(key string, value string) (int64, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	filterByKey := key != "" && value != ""
+	if filterByKey {
+		if _, err := instrumentField(models.Instrument{}, key); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int64
+	for sid, ins := range db.data.instruments {
+		if filterByKey {
+			if field, _ := instrumentField(ins, key); field != value {
+				continue
+			}
+		}
+		delete(db.data.instruments, sid)
+		delete(db.data.prices, sid)
+		n++
+	}
+	return n, nil
+}
+
+// DeleteAllInstruments removes all instruments from storage
+func (db Db) DeleteAllInstruments() (int64, error) {
+	return db.DeleteInstruments("", "")
+}
+
+func instrumentField(ins models.Instrument, key string) (string, error) {
+	switch key {
+	case "id", "sid":
+		return strconv.Itoa(ins.SecID), nil
+	case "isin":
+		return ins.ISIN, nil
+	case "ticker":
+		return ins.Ticker, nil
+	case "figi":
+		return ins.FIGI, nil
+	case "currency":
+		return string(ins.Currency), nil
+	case "code", "exchange":
+		return string(ins.Exchange), nil
+	case "id_name", "type":
+		return string(ins.Type), nil
+	case "title", "name":
+		return ins.Name, nil
+	default:
+		return "", errs.New(errs.Validation, "unknown instrument filter: %s", key)
+	}
+}
+
+func instrumentSortValue(ins models.Instrument, key string) (interface{}, error) {
+	if key == "id" || key == "sid" {
+		return int64(ins.SecID), nil
+	}
+	field, err := instrumentField(ins, key)
+	if err != nil {
+		return nil, errs.New(errs.Validation, "unknown instrument sort key: %s", key)
+	}
+	return field, nil
+}
+
+func sortInstruments(instr []models.Instrument) {
+	sort.Slice(instr, func(i, j int) bool { return instr[i].SecID < instr[j].SecID })
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"strconv"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddJob saves new background job run and returns its id
+func (db Db) AddJob(j models.Job) (string, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	j.ID = strconv.Itoa(db.data.nextID())
+	j.Errors = copyJobErrors(j.Errors)
+	db.data.jobs[j.ID] = j
+	return j.ID, nil
+}
+
+// UpdateJob replaces state of background job run
+func (db Db) UpdateJob(j models.Job) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	if _, ok := db.data.jobs[j.ID]; !ok {
+		return false, nil
+	}
+	j.Errors = copyJobErrors(j.Errors)
+	db.data.jobs[j.ID] = j
+	return true, nil
+}
+
+// GetJob finds background job run by id
+func (db Db) GetJob(id string) (models.Job, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	j, ok := db.data.jobs[id]
+	if !ok {
+		return models.Job{}, errs.New(errs.NotFound, "No job found with %s Id", id)
+	}
+	j.Errors = copyJobErrors(j.Errors)
+	return j, nil
+}
+
+func copyJobErrors(errs []models.JobError) []models.JobError {
+	if errs == nil {
+		return nil
+	}
+	return append([]models.JobError{}, errs...)
+}
@@ -0,0 +1,235 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddOperation saves single opertion into a storage.
+// Operation with the same fingerprint must not exist in portfolio
+func (db Db) AddOperation(portfolioID string, op models.Operation) (string, error) {
+	ids, err := db.AddOperations(portfolioID, []models.Operation{op})
+	if err != nil {
+		return "", err
+	}
+	if ids[0] == "" {
+		return "", errs.New(errs.Conflict, "operation already exists")
+	}
+	return ids[0], nil
+}
+
+// AddOperations saves multiple opertions into a storage.
+// Operations which fingerprint already exists in portfolio, or repeats
+// within the batch, are skipped. Fingerprints are taken from models.Fingerprints,
+// so identical fills are kept. Returned Ids follow input order,
+// skipped operations get empty Id
+func (db Db) AddOperations(portfolioID string, ops []models.Operation) ([]string, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	if _, ok := db.data.portfolios[portfolioID]; !ok {
+		return nil, errs.New(errs.NotFound, "could not add operation to unknown portfolio")
+	}
+
+	existing := make(map[string]bool)
+	for id, op := range db.data.operations {
+		if op.PortfolioID == portfolioID {
+			existing[db.data.fingerprints[id]] = true
+		}
+	}
+
+	ids := make([]string, len(ops))
+	for i, fp := range models.Fingerprints(ops) {
+		if existing[fp] {
+			continue
+		}
+		existing[fp] = true
+		op := ops[i]
+		op.PortfolioID = portfolioID
+		op.OperationID = strconv.Itoa(db.data.nextID())
+		db.data.operations[op.OperationID] = op
+		db.data.fingerprints[op.OperationID] = fp
+		ids[i] = op.OperationID
+	}
+	return ids, nil
+}
+
+// GetOperations finds operations depending on input prameters
+func (db Db) GetOperations(portfolioID string, key string, value string, from string, to string) ([]models.Operation, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	filterByKey := key != "" && value != ""
+	if filterByKey {
+		if _, err := operationField(models.Operation{}, key); err != nil {
+			return nil, err
+		}
+	}
+	fromTime, hasFrom := parseTime(from)
+	toTime, hasTo := parseTime(to)
+
+	result := []models.Operation{}
+	for _, op := range db.data.operations {
+		if op.PortfolioID != portfolioID {
+			continue
+		}
+		if hasFrom && op.DateTime.Before(fromTime) {
+			continue
+		}
+		if hasTo && op.DateTime.After(toTime) {
+			continue
+		}
+		if ins, ok := db.data.findInstrumentByISIN(op.ISIN); ok {
+			if op.Ticker == "" {
+				op.Ticker = ins.Ticker
+			}
+			if op.FIGI == "" {
+				op.FIGI = ins.FIGI
+			}
+		}
+		if filterByKey {
+			if field, _ := operationField(op, key); field != value {
+				continue
+			}
+		}
+		result = append(result, op)
+	}
+	sort.Stable(models.OperationSorter(result))
+	return result, nil
+}
+
+// FindOperations finds portfolio operations matching given query.
+// Returns requested page of operations and total number of matched ones
+func (db Db) FindOperations(portfolioID string, q models.ListQuery) ([]models.Operation, int64, error) {
+	ops, err := db.GetOperations(portfolioID, "", "", q.From, q.To)
+	if err != nil {
+		return nil, 0, err
+	}
+	item := func(i int) models.Operation {
+		if i < 0 {
+			return models.Operation{}
+		}
+		return ops[i]
+	}
+	filter := func(i int, key string) (string, error) { return operationField(item(i), key) }
+	value := func(i int, key string) (interface{}, error) { return operationSortValue(item(i), key) }
+
+	page, total, err := applyQuery(len(ops), q, filter, value)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]models.Operation, len(page))
+	for i, j := range page {
+		result[i] = ops[j]
+	}
+	return result, total, nil
+}
+
+// GetOperation finds operation by Id
+func (db Db) GetOperation(portfolioID string, operationID string) (models.Operation, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	op, ok := db.data.operations[operationID]
+	if !ok || op.PortfolioID != portfolioID {
+		return models.Operation{}, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
+	}
+	if ins, ok := db.data.findInstrumentByISIN(op.ISIN); ok {
+		if op.Ticker == "" {
+			op.Ticker = ins.Ticker
+		}
+		if op.FIGI == "" {
+			op.FIGI = ins.FIGI
+		}
+	}
+	return op, nil
+}
+
+// UpdateOperation replaces operation data. Fingerprint is kept, so that
+// edited operation is still recognized when its report is imported again
+func (db Db) UpdateOperation(portfolioID string, operationID string, op models.Operation) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	old, ok := db.data.operations[operationID]
+	if !ok || old.PortfolioID != portfolioID {
+		return false, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
+	}
+	op.PortfolioID = portfolioID
+	op.OperationID = operationID
+	op.Source = old.Source
+	op.ExternalID = old.ExternalID
+	db.data.operations[operationID] = op
+	return true, nil
+}
+
+// DeleteOperation removes operation by Id
+func (db Db) DeleteOperation(portfolioID string, operationID string) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	op, ok := db.data.operations[operationID]
+	if !ok || op.PortfolioID != portfolioID {
+		return false, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
+	}
+	delete(db.data.operations, operationID)
+	delete(db.data.fingerprints, operationID)
+	return true, nil
+}
+
+// DeleteOperations removes all operations for provided portfolio Id
+func (db Db) DeleteOperations(portfolioID string) (int64, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	return db.data.deleteOperations(portfolioID), nil
+}
+
+// deleteOperations removes portfolio operations. Must be called under write lock
+func (s *store) deleteOperations(portfolioID string) int64 {
+	var n int64
+	for id, op := range s.operations {
+		if op.PortfolioID == portfolioID {
+			delete(s.operations, id)
+			delete(s.fingerprints, id)
+			n++
+		}
+	}
+	return n
+}
+
+func operationField(op models.Operation, key string) (string, error) {
+	switch key {
+	case "ticker":
+		return op.Ticker, nil
+	case "isin":
+		return op.ISIN, nil
+	case "figi":
+		return op.FIGI, nil
+	case "currency":
+		return string(op.Currency), nil
+	case "type":
+		return string(op.OperationType), nil
+	default:
+		return "", errs.New(errs.Validation, "unknown operation filter: %s", key)
+	}
+}
+
+func operationSortValue(op models.Operation, key string) (interface{}, error) {
+	switch key {
+	case "date":
+		return op.DateTime, nil
+	case "price":
+		return op.Price, nil
+	case "vol":
+		return op.Volume, nil
+	}
+	field, err := operationField(op, key)
+	if err != nil {
+		return nil, errs.New(errs.Validation, "unknown operation sort key: %s", key)
+	}
+	return field, nil
+}
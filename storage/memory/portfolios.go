@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"strconv"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddPortfolio adds new potrfolio
+func (db Db) AddPortfolio(userID string, p models.Portfolio) (string, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	if db.data.findUserByID(userID) == nil {
+		return "", errs.New(errs.NotFound, "No user found with %s Id", userID)
+	}
+	p.PortfolioID = strconv.Itoa(db.data.nextID())
+	p.UserID = userID
+	db.data.portfolios[p.PortfolioID] = p
+	return p.PortfolioID, nil
+}
+
+// GetPortfolio gets operation by id
+func (db Db) GetPortfolio(userID string, portfolioID string) (models.Portfolio, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	p, ok := db.data.portfolios[portfolioID]
+	if !ok || p.UserID != userID {
+		return models.Portfolio{}, errs.New(errs.NotFound, "No portfolio found with %s Id", portfolioID)
+	}
+	return p, nil
+}
+
+// GetPortfolios gets all portfolio fpvie user Id
+func (db Db) GetPortfolios(userID string) ([]models.Portfolio, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	result := []models.Portfolio{}
+	for _, p := range db.data.portfolios {
+		if p.UserID == userID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// UpdatePortfolio updates portfolio with provided uid and pid
+func (db Db) UpdatePortfolio(userID string, portfolioID string, p models.Portfolio) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	old, ok := db.data.portfolios[portfolioID]
+	if !ok || old.UserID != userID {
+		return false, nil
+	}
+	old.Name = p.Name
+	old.Description = p.Description
+	db.data.portfolios[portfolioID] = old
+	return true, nil
+}
+
+// DeletePortfolio removes portfolio by Id
+// Also removes all operations associated with this portfolio
+func (db Db) DeletePortfolio(userID string, portfolioID string) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	p, ok := db.data.portfolios[portfolioID]
+	if !ok || p.UserID != userID {
+		return false, nil
+	}
+	db.data.deleteOperations(portfolioID)
+	delete(db.data.portfolios, portfolioID)
+	return true, nil
+}
+
+// DeletePortfolios removes all portfolios for provided user
+// Also removes all operations associated with this portfolios
+func (db Db) DeletePortfolios(userID string) (int64, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	return db.data.deletePortfolios(userID), nil
+}
+
+// deletePortfolios removes portfolios with operations. Must be called under write lock
+func (s *store) deletePortfolios(userID string) int64 {
+	var n int64
+	for id, p := range s.portfolios {
+		if p.UserID == userID {
+			s.deleteOperations(id)
+			delete(s.portfolios, id)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddPrices saves prices series into a storage
+func (db Db) AddPrices(prices []models.Price) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	resolved := make([]models.Price, len(prices))
+	batch := make(map[int]map[time.Time]bool)
+	for i, pr := range prices {
+		ins, ok := db.data.instruments[pr.SecID]
+		if !ok {
+			ins, ok = db.data.findInstrumentByISIN(pr.ISIN)
+		}
+		if !ok {
+			return errs.New(errs.Validation, "could not add prices: unknown instrument %s", pr.ISIN)
+		}
+		pr.SecID = ins.SecID
+		pr.ISIN = ins.ISIN
+
+		d := day(pr.Date)
+		if _, ok := db.data.prices[pr.SecID][d]; ok || batch[pr.SecID][d] {
+			return errs.New(errs.Conflict, "could not add prices: price for %s on %s already exists", pr.ISIN, d.Format("2006-01-02"))
+		}
+		if batch[pr.SecID] == nil {
+			batch[pr.SecID] = make(map[time.Time]bool)
+		}
+		batch[pr.SecID][d] = true
+		resolved[i] = pr
+	}
+
+	for _, pr := range resolved {
+		if db.data.prices[pr.SecID] == nil {
+			db.data.prices[pr.SecID] = make(map[time.Time]models.Price)
+		}
+		db.data.prices[pr.SecID][day(pr.Date)] = pr
+	}
+	return nil
+}
+
+// GetPrices finds prices depending on input prameters
+func (db Db) GetPrices(key, value, from, to string) ([]models.Price, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	filterByKey := key != "" && value != ""
+	if filterByKey {
+		if _, err := instrumentField(models.Instrument{}, key); err != nil {
+			return nil, err
+		}
+	}
+	fromTime, hasFrom := parseTime(from)
+	toTime, hasTo := parseTime(to)
+
+	result := []models.Price{}
+	for sid, series := range db.data.prices {
+		if filterByKey {
+			if field, _ := instrumentField(db.data.instruments[sid], key); field != value {
+				continue
+			}
+		}
+		for _, pr := range series {
+			if hasFrom && pr.Date.Before(fromTime) {
+				continue
+			}
+			if hasTo && pr.Date.After(toTime) {
+				continue
+			}
+			result = append(result, pr)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date.Equal(result[j].Date) {
+			return result[i].SecID < result[j].SecID
+		}
+		return result[i].Date.Before(result[j].Date)
+	})
+	return result, nil
+}
+
+// FindPrices finds prices matching given query. Prices are filtered
+// by their instrument fields. Returns requested page of prices and total number of matched ones
+func (db Db) FindPrices(q models.ListQuery) ([]models.Price, int64, error) {
+	prices, err := db.GetPrices("", "", q.From, q.To)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	db.data.RLock()
+	instr := make([]models.Instrument, len(prices))
+	for i, pr := range prices {
+		instr[i] = db.data.instruments[pr.SecID]
+	}
+	db.data.RUnlock()
+
+	filter := func(i int, key string) (string, error) {
+		switch key {
+		case "isin", "ticker", "figi":
+			if i < 0 {
+				return "", nil
+			}
+			return instrumentField(instr[i], key)
+		default:
+			return "", errs.New(errs.Validation, "unknown price filter: %s", key)
+		}
+	}
+	value := func(i int, key string) (interface{}, error) {
+		pr := models.Price{}
+		if i >= 0 {
+			pr = prices[i]
+		}
+		switch key {
+		case "date":
+			return pr.Date, nil
+		case "isin":
+			return pr.ISIN, nil
+		case "price":
+			return pr.Price, nil
+		case "vol":
+			return int64(pr.Volume), nil
+		default:
+			return nil, errs.New(errs.Validation, "unknown price sort key: %s", key)
+		}
+	}
+
+	page, total, err := applyQuery(len(prices), q, filter, value)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]models.Price, len(page))
+	for i, j := range page {
+		result[i] = prices[j]
+	}
+	return result, total, nil
+}
+
+// GetPricesByIsin finds prices for given ISIN and dates
+func (db Db) GetPricesByIsin(isin, from, to string) ([]models.Price, error) {
+	return db.GetPrices("isin", isin, from, to)
+}
+
+// DeletePrices removes prices depending on input prameters
+func (db Db) DeletePrices(key string, value string) (int64, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	filterByKey := key != "" && value != ""
+	if filterByKey {
+		if _, err := instrumentField(models.Instrument{}, key); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int64
+	for sid, series := range db.data.prices {
+		if filterByKey {
+			if field, _ := instrumentField(db.data.instruments[sid], key); field != value {
+				continue
+			}
+		}
+		n += int64(len(series))
+		delete(db.data.prices, sid)
+	}
+	return n, nil
+}
+
+// DeleteAllPrices removes all prices from storage
+func (db Db) DeleteAllPrices() (int64, error) {
+	return db.DeletePrices("", "")
+}
+
+// lastPrice finds latest price not after given date. Must be called under read lock
+func (s *store) lastPrice(sid int, onDate time.Time) (models.Price, bool) {
+	var result models.Price
+	found := false
+	for _, pr := range s.prices[sid] {
+		if pr.Date.After(onDate) {
+			continue
+		}
+		if !found || pr.Date.After(result.Date) {
+			result = pr
+			found = true
+		}
+	}
+	return result, found
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+)
+
+// filterFunc returns string value of i-th item field used for filtering.
+// Negative index stands for zero item, it is used to validate keys
+type filterFunc func(i int, key string) (string, error)
+
+// sortFunc returns value of i-th item field used for sorting.
+// Negative index stands for zero item, it is used to validate keys
+type sortFunc func(i int, key string) (interface{}, error)
+
+// applyQuery filters, sorts and pages n items already ordered by default.
+// Returns indices of items of requested page and total number of matched items
+func applyQuery(n int, q models.ListQuery, filter filterFunc, value sortFunc) ([]int, int64, error) {
+	for key := range q.Filters {
+		if _, err := filter(-1, key); err != nil {
+			return nil, 0, err
+		}
+	}
+	for _, key := range q.Sort {
+		if _, err := value(-1, strings.TrimPrefix(key, "-")); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	matched := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if matchFilters(i, q.Filters, filter) {
+			matched = append(matched, i)
+		}
+	}
+
+	// stable sort keeps default order of items with equal keys
+	sort.SliceStable(matched, func(a, b int) bool {
+		for _, key := range q.Sort {
+			field := strings.TrimPrefix(key, "-")
+			va, _ := value(matched[a], field)
+			vb, _ := value(matched[b], field)
+			c := compareValues(va, vb)
+			if c == 0 {
+				continue
+			}
+			if field != key {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	total := int64(len(matched))
+	offset := q.Offset
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	matched = matched[offset:]
+	if q.Limit > 0 && q.Limit < len(matched) {
+		matched = matched[:q.Limit]
+	}
+	return matched, total, nil
+}
+
+// matchFilters checks whether i-th item matches any value of every filter
+func matchFilters(i int, filters map[string][]string, filter filterFunc) bool {
+	for key, values := range filters {
+		if len(values) == 0 {
+			continue
+		}
+		field, _ := filter(i, key)
+		found := false
+		for _, v := range values {
+			if field == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func compareValues(a, b interface{}) int {
+	switch va := a.(type) {
+	case float64:
+		vb, _ := b.(float64)
+		if va < vb {
+			return -1
+		} else if va > vb {
+			return 1
+		}
+	case int64:
+		vb, _ := b.(int64)
+		if va < vb {
+			return -1
+		} else if va > vb {
+			return 1
+		}
+	case time.Time:
+		vb, _ := b.(time.Time)
+		if va.Before(vb) {
+			return -1
+		} else if va.After(vb) {
+			return 1
+		}
+	case string:
+		vb, _ := b.(string)
+		return strings.Compare(va, vb)
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddRates saves currency rates into a storage. Existing rates are overwritten
+func (db Db) AddRates(rates []models.Rate) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	for _, r := range rates {
+		if r.Currency != currency.RUB && r.Currency != currency.USD && r.Currency != currency.EUR {
+			return errs.New(errs.Validation, "could not add rates: unknown currency %s", r.Currency)
+		}
+	}
+	for _, r := range rates {
+		if db.data.rates[r.Currency] == nil {
+			db.data.rates[r.Currency] = make(map[time.Time]float64)
+		}
+		db.data.rates[r.Currency][day(r.Date)] = r.Rate
+	}
+	return nil
+}
+
+// GetRates finds rates of given currency between dates. Empty currency means all currencies
+func (db Db) GetRates(curr currency.Type, from, to string) ([]models.Rate, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	fromTime, hasFrom := parseTime(from)
+	toTime, hasTo := parseTime(to)
+	result := []models.Rate{}
+	for c, rates := range db.data.rates {
+		if curr != "" && c != curr {
+			continue
+		}
+		for d, rate := range rates {
+			if hasFrom && d.Before(day(fromTime)) {
+				continue
+			}
+			if hasTo && d.After(toTime) {
+				continue
+			}
+			result = append(result, models.Rate{Currency: c, Date: d, Rate: rate})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date.Equal(result[j].Date) {
+			return result[i].Currency < result[j].Currency
+		}
+		return result[i].Date.Before(result[j].Date)
+	})
+	return result, nil
+}
+
+// DeleteRates removes rates of given currency. Empty currency means all currencies
+func (db Db) DeleteRates(curr currency.Type) (int64, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	count := int64(0)
+	for c, rates := range db.data.rates {
+		if curr != "" && c != curr {
+			continue
+		}
+		count += int64(len(rates))
+		delete(db.data.rates, c)
+	}
+	return count, nil
+}
+
+// SetRateUptdTime sets time currency rates were updated
+func (db Db) SetRateUptdTime(curr currency.Type, updTime time.Time) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	if curr != currency.RUB && curr != currency.USD && curr != currency.EUR {
+		return false, nil
+	}
+	db.data.rateUpdTime[curr] = day(updTime)
+	return true, nil
+}
+
+// GetRateUptdTime gets time currency rates were updated. Zero time means never
+func (db Db) GetRateUptdTime(curr currency.Type) (time.Time, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	return db.data.rateUpdTime[curr], nil
+}
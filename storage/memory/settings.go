@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+)
+
+// AddTcsToken adds token to access tcs API
+func (db Db) AddTcsToken(token string) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	db.data.tcsToken = token
+	return nil
+}
+
+// DeleteTcsToken deletes token to access tcs API
+func (db Db) DeleteTcsToken() error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	db.data.tcsToken = ""
+	return nil
+}
+
+// GetTcsToken finds token to access tcs API
+func (db Db) GetTcsToken() (string, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	return db.data.tcsToken, nil
+}
+
+// GetSchedules returns all background job schedules sorted by id
+func (db Db) GetSchedules() ([]models.Schedule, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	result := make([]models.Schedule, 0, len(db.data.schedules))
+	for _, sch := range db.data.schedules {
+		result = append(result, sch)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// SaveSchedule adds background job schedule or replaces existing one with the same id
+func (db Db) SaveSchedule(sch models.Schedule) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	db.data.schedules[sch.ID] = sch
+	return nil
+}
+
+// DeleteSchedule removes background job schedule
+func (db Db) DeleteSchedule(id string) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	_, ok := db.data.schedules[id]
+	delete(db.data.schedules, id)
+	return ok, nil
+}
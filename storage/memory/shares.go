@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+// GetShares gets shares
+func (db Db) GetShares(pid string, onDate string) ([]models.Share, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	dtime := time.Now()
+	if t, ok := parseTime(onDate); ok {
+		dtime = t
+	}
+
+	volumes := make(map[string]int64)
+	tickers := make(map[string]string)
+	balance := int64(0)
+	hasOperations := false
+	for _, op := range db.data.operations {
+		if op.PortfolioID != pid || op.DateTime.After(dtime) {
+			continue
+		}
+		hasOperations = true
+
+		amount := int64(math.Round(op.Price*1e6)) * op.Volume
+		switch op.OperationType {
+		case operation.Buy:
+			volumes[op.ISIN] += op.Volume
+			balance -= amount
+		case operation.Sell, operation.Buyback:
+			volumes[op.ISIN] -= op.Volume
+			balance += amount
+		// incomes match the ones of valuation history
+		case operation.PayIn, operation.AccInterestSell, operation.Coupon, operation.Dividend, operation.Amortization:
+			balance += amount
+		default:
+			balance -= amount
+		}
+		if op.Ticker != "" {
+			tickers[op.ISIN] = op.Ticker
+		}
+	}
+
+	result := []models.Share{}
+	for isin, vol := range volumes {
+		if vol == 0 || isin == "RUB" {
+			continue
+		}
+		share := models.Share{
+			ISIN:   isin,
+			Ticker: tickers[isin],
+			Volume: vol,
+			Date:   dtime,
+		}
+		if ins, ok := db.data.findInstrumentByISIN(isin); ok {
+			share.Ticker = ins.Ticker
+			if pr, ok := db.data.lastPrice(ins.SecID, dtime); ok {
+				share.Price = pr.Price
+			}
+		}
+		result = append(result, share)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ISIN < result[j].ISIN })
+
+	if hasOperations {
+		result = append(result, models.Share{
+			ISIN:   "RUB",
+			Ticker: "RUB",
+			Price:  math.Round(float64(balance)/1e4) / 100,
+			Volume: 0,
+			Date:   dtime,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/kaseat/pManager/models/provider"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddUserLastUpdateTime saves last date when specified provider made sync
+func (db Db) AddUserLastUpdateTime(login string, provider provider.Type, date time.Time) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	u, ok := db.data.users[login]
+	if !ok {
+		return errs.New(errs.NotFound, "could not fint user with login: %s", login)
+	}
+	u.LastSync[provider] = date
+	return nil
+}
+
+// GetUserLastUpdateTime receives last date when specified provider made sync
+func (db Db) GetUserLastUpdateTime(login string, provider provider.Type) (time.Time, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	u, ok := db.data.users[login]
+	if !ok {
+		return time.Time{}, nil
+	}
+	return u.LastSync[provider], nil
+}
+
+// DeleteUserLastUpdateTime removes last date when specified provider made sync
+func (db Db) DeleteUserLastUpdateTime(login string, provider provider.Type) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	if u, ok := db.data.users[login]; ok {
+		delete(u.LastSync, provider)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/action"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/event"
+	"github.com/kaseat/pManager/models/provider"
+	"golang.org/x/oauth2"
+)
+
+// Db represents storage
+type Db struct {
+	data *store
+}
+
+type store struct {
+	sync.RWMutex
+	lastID     int
+	users      map[string]*user
+	portfolios map[string]models.Portfolio
+	operations map[string]models.Operation
+	// fingerprints of stored operations by operation Id
+	fingerprints map[string]string
+	instruments  map[int]models.Instrument
+	prices       map[int]map[time.Time]models.Price
+	rates        map[currency.Type]map[time.Time]float64
+	rateUpdTime  map[currency.Type]time.Time
+	events       map[eventKey]models.SecurityEvent
+	actions      map[actionKey]models.CorporateAction
+	tcsToken     string
+	schedules    map[string]models.Schedule
+	jobs         map[string]models.Job
+}
+
+type eventKey struct {
+	ISIN string
+	Type event.Type
+	Date time.Time
+}
+
+type actionKey struct {
+	ISIN string
+	Type action.Type
+	Date time.Time
+}
+
+type user struct {
+	ID       string
+	Login    string
+	Email    string
+	Hash     string
+	IsAdmin  bool
+	State    string
+	Token    *oauth2.Token
+	LastSync map[provider.Type]time.Time
+}
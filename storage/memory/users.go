@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+	"golang.org/x/oauth2"
+)
+
+// AddUser saves user and password hash to storage
+func (db Db) AddUser(login, email, hash string) (string, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	if _, ok := db.data.users[login]; ok {
+		return "", errs.New(errs.Conflict, "User with this login already exists")
+	}
+	u := newUser(db.data.nextID(), login, email, hash)
+	db.data.users[login] = u
+	return u.ID, nil
+}
+
+// GetUserByLogin gets user by login
+func (db Db) GetUserByLogin(login string) (models.User, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	u, ok := db.data.users[login]
+	if !ok {
+		return models.User{}, errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
+	return models.User{
+		UserID:  u.ID,
+		Login:   u.Login,
+		Email:   u.Email,
+		IsAdmin: u.IsAdmin,
+	}, nil
+}
+
+// AddUserState adds state to user
+func (db Db) AddUserState(login string, state string) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	u, ok := db.data.users[login]
+	if !ok {
+		return errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
+	u.State = state
+	return nil
+}
+
+// GetUserState gets user's state
+func (db Db) GetUserState(login string) (string, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	u, ok := db.data.users[login]
+	if !ok {
+		return "", errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
+	return u.State, nil
+}
+
+// AddUserToken adds oauth2 token to user
+func (db Db) AddUserToken(state string, token *oauth2.Token) error {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	for _, u := range db.data.users {
+		if u.State != "" && u.State == state {
+			tok := *token
+			u.Token = &tok
+			return nil
+		}
+	}
+	return errs.New(errs.NotFound, "could not find user with state: %s", state)
+}
+
+// GetUserToken gets user's oauth2 token
+func (db Db) GetUserToken(login string) (oauth2.Token, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	u, ok := db.data.users[login]
+	if !ok {
+		return oauth2.Token{}, errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
+	if u.Token == nil {
+		return oauth2.Token{}, errs.New(errs.NotFound, "no token found for user with login: %s", login)
+	}
+	return *u.Token, nil
+}
+
+// GetUserPassword gets password hash from storage
+func (db Db) GetUserPassword(login string) (string, error) {
+	db.data.RLock()
+	defer db.data.RUnlock()
+
+	u, ok := db.data.users[login]
+	if !ok {
+		return "", nil
+	}
+	return u.Hash, nil
+}
+
+// UpdateUserPassword updates user password
+func (db Db) UpdateUserPassword(login, hash string) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	u, ok := db.data.users[login]
+	if !ok {
+		return false, nil
+	}
+	u.Hash = hash
+	return true, nil
+}
+
+// DeleteUser removes password hash from storage
+// Also removes all portfolios associated with this user
+// Also removes all operations associated with portfolios of this user
+func (db Db) DeleteUser(login string) (bool, error) {
+	db.data.Lock()
+	defer db.data.Unlock()
+
+	u, ok := db.data.users[login]
+	if !ok {
+		return false, nil
+	}
+	db.data.deletePortfolios(u.ID)
+	delete(db.data.users, login)
+	return true, nil
+}
@@ -0,0 +1,29 @@
+package storage
+
+import "fmt"
+
+// Migrator represents storage which supports versioned schema migrations
+type Migrator interface {
+	Migrate() error
+	MigrateTo(version int) error
+	SchemaVersion() (int, error)
+}
+
+// Migrate brings current storage schema to the latest version.
+// Storages without migrations support are left untouched
+func Migrate() error {
+	m, ok := GetStorage().(Migrator)
+	if !ok {
+		return nil
+	}
+	return m.Migrate()
+}
+
+// MigrateTo brings current storage schema to given version
+func MigrateTo(version int) error {
+	m, ok := GetStorage().(Migrator)
+	if !ok {
+		return fmt.Errorf("storage %s does not support migrations", currentStorage)
+	}
+	return m.MigrateTo(version)
+}
@@ -0,0 +1,102 @@
+package mongo
+
+import (
+	"math"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/action"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddCorporateActions saves corporate actions into a storage. Existing actions are overwritten
+func (db Db) AddCorporateActions(actions []models.CorporateAction) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, len(actions))
+	for i, a := range actions {
+		filter := bson.M{"isin": a.ISIN, "type": string(a.Type), "time": a.Date}
+		doc := bson.M{
+			"isin":      a.ISIN,
+			"type":      string(a.Type),
+			"time":      a.Date,
+			"from":      a.RatioFrom,
+			"to":        a.RatioTo,
+			"newIsin":   a.NewISIN,
+			"newTicker": a.NewTicker,
+			"cost":      int64(math.Round(a.CostRatio * 1e6)),
+		}
+		writes[i] = mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(doc).SetUpsert(true)
+	}
+
+	ctx := db.context()
+	_, err := db.actions.BulkWrite(ctx, writes, options.BulkWrite())
+	return err
+}
+
+// GetCorporateActions finds corporate actions of given security sorted by date.
+// Empty ISIN means all securities
+func (db Db) GetCorporateActions(isin string) ([]models.CorporateAction, error) {
+	filter := bson.M{}
+	if isin != "" {
+		filter["isin"] = isin
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "time", Value: 1}, {Key: "isin", Value: 1}, {Key: "type", Value: 1}})
+
+	ctx := db.context()
+	cur, err := db.actions.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var raw []struct {
+		ISIN      string    `bson:"isin"`
+		Type      string    `bson:"type"`
+		Date      time.Time `bson:"time"`
+		From      int64     `bson:"from"`
+		To        int64     `bson:"to"`
+		NewISIN   string    `bson:"newIsin"`
+		NewTicker string    `bson:"newTicker"`
+		Cost      int64     `bson:"cost"`
+	}
+	err = cur.All(ctx, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.CorporateAction, len(raw))
+	for i, a := range raw {
+		result[i] = models.CorporateAction{
+			ISIN:      a.ISIN,
+			Type:      action.Type(a.Type),
+			Date:      a.Date,
+			RatioFrom: a.From,
+			RatioTo:   a.To,
+			NewISIN:   a.NewISIN,
+			NewTicker: a.NewTicker,
+			CostRatio: float64(a.Cost) / 1e6,
+		}
+	}
+	return result, nil
+}
+
+// DeleteCorporateActions removes corporate actions of given security. Empty ISIN means all securities
+func (db Db) DeleteCorporateActions(isin string) (int64, error) {
+	filter := bson.M{}
+	if isin != "" {
+		filter["isin"] = isin
+	}
+	ctx := db.context()
+	del, err := db.actions.DeleteMany(ctx, filter, options.Delete())
+	if err != nil {
+		return 0, err
+	}
+	return del.DeletedCount, nil
+}
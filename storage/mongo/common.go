@@ -2,7 +2,10 @@ package mongo
 
 import (
 	"context"
+	"fmt"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -25,8 +28,79 @@ func (db *Db) Init(config Config) error {
 	db.portfolios = client.Database(cfg.DbName).Collection("portfolios")
 	db.users = client.Database(cfg.DbName).Collection("users")
 	db.prices = client.Database(cfg.DbName).Collection("prices")
+	db.rates = client.Database(cfg.DbName).Collection("rates")
+	db.events = client.Database(cfg.DbName).Collection("security_events")
+	db.actions = client.Database(cfg.DbName).Collection("corporate_actions")
+	db.currencies = client.Database(cfg.DbName).Collection("currencies")
+	db.jobs = client.Database(cfg.DbName).Collection("jobs")
 	db.instruments = client.Database(cfg.DbName).Collection("instruments")
 	db.settings = client.Database(cfg.DbName).Collection("settings")
+	err = db.createIndexes()
+	if err != nil {
+		return err
+	}
+	return db.backfillFingerprints()
+}
+
+// duplicateKeyCode is mongodb error code of unique index violation
+const duplicateKeyCode = 11000
+
+func (db *Db) createIndexes() error {
+	// operations added before fingerprints were introduced have no fp field
+	_, err := db.operations.Indexes().CreateOne(db.context(), mongo.IndexModel{
+		Keys: bson.D{{Key: "pid", Value: 1}, {Key: "fp", Value: 1}},
+		Options: options.Index().
+			SetName("ux_operations_fingerprint").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"fp": bson.M{"$exists": true}}),
+	})
+	return err
+}
+
+// backfillFingerprints sets fingerprints of operations added before they were
+// introduced. Fingerprints are computed by models.Operation.Fingerprint, and identical
+// operations get their ordinal appended the same way models.Fingerprints does
+func (db *Db) backfillFingerprints() error {
+	ctx := db.context()
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	pending, err := db.getOperations(bson.M{"fp": bson.M{"$exists": false}}, opts)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	cur, err := db.operations.Find(ctx, bson.M{"fp": bson.M{"$exists": true}}, options.Find().SetProjection(bson.M{"pid": 1, "fp": 1}))
+	if err != nil {
+		return err
+	}
+	var fps []struct {
+		PortfolioID primitive.ObjectID `bson:"pid"`
+		Fingerprint string             `bson:"fp"`
+	}
+	err = cur.All(ctx, &fps)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(fps))
+	for _, fp := range fps {
+		existing[fp.PortfolioID.Hex()+":"+fp.Fingerprint] = true
+	}
+
+	for _, op := range pending {
+		oid, err := primitive.ObjectIDFromHex(op.OperationID)
+		if err != nil {
+			return err
+		}
+		base := op.Fingerprint()
+		fp := base
+		for n := 1; existing[op.PortfolioID+":"+fp]; n++ {
+			fp = fmt.Sprintf("%s#%d", base, n)
+		}
+		existing[op.PortfolioID+":"+fp] = true
+		_, err = db.operations.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": bson.M{"fp": fp}})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,103 @@
+package mongo
+
+import (
+	"math"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/event"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddSecurityEvents saves security events into a storage. Existing events are overwritten
+func (db Db) AddSecurityEvents(events []models.SecurityEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, len(events))
+	for i, e := range events {
+		filter := bson.M{"isin": e.ISIN, "type": string(e.Type), "time": e.Date}
+		doc := bson.M{
+			"isin":     e.ISIN,
+			"type":     string(e.Type),
+			"time":     e.Date,
+			"value":    int64(math.Round(e.Value * 1e6)),
+			"currency": string(e.Currency),
+		}
+		writes[i] = mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(doc).SetUpsert(true)
+	}
+
+	ctx := db.context()
+	_, err := db.events.BulkWrite(ctx, writes, options.BulkWrite())
+	return err
+}
+
+// GetSecurityEvents finds events of given security between dates. Empty ISIN means all securities
+func (db Db) GetSecurityEvents(isin, from, to string) ([]models.SecurityEvent, error) {
+	filter := bson.M{}
+	if isin != "" {
+		filter["isin"] = isin
+	}
+	bounds := bson.M{}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", from); err == nil {
+		bounds["$gte"] = dtime
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", to); err == nil {
+		bounds["$lte"] = dtime
+	}
+	if len(bounds) != 0 {
+		filter["time"] = bounds
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "time", Value: 1}, {Key: "isin", Value: 1}, {Key: "type", Value: 1}})
+
+	ctx := db.context()
+	cur, err := db.events.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var raw []struct {
+		ISIN     string    `bson:"isin"`
+		Type     string    `bson:"type"`
+		Date     time.Time `bson:"time"`
+		Value    int64     `bson:"value"`
+		Currency string    `bson:"currency"`
+	}
+	err = cur.All(ctx, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.SecurityEvent, len(raw))
+	for i, e := range raw {
+		result[i] = models.SecurityEvent{
+			ISIN:     e.ISIN,
+			Type:     event.Type(e.Type),
+			Date:     e.Date,
+			Value:    float64(e.Value) / 1e6,
+			Currency: currency.Type(e.Currency),
+		}
+	}
+	return result, nil
+}
+
+// DeleteSecurityEvents removes events of given security. Empty ISIN means all securities
+func (db Db) DeleteSecurityEvents(isin string) (int64, error) {
+	filter := bson.M{}
+	if isin != "" {
+		filter["isin"] = isin
+	}
+	ctx := db.context()
+	del, err := db.events.DeleteMany(ctx, filter, options.Delete())
+	if err != nil {
+		return 0, err
+	}
+	return del.DeletedCount, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/kaseat/pManager/models"
 	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/exchange"
 	"github.com/kaseat/pManager/models/instrument"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -33,6 +34,9 @@ func (db Db) AddInstruments(instr []models.Instrument) error {
 		if p.FIGI != "" {
 			doc["figi"] = p.FIGI
 		}
+		if p.Exchange != "" {
+			doc["exch"] = p.Exchange
+		}
 		docs[i] = doc
 	}
 
@@ -132,6 +136,44 @@ func (db Db) GetAllInstruments() ([]models.Instrument, error) {
 	return db.getInstruments(filter, findOptions)
 }
 
+// FindInstruments finds instruments matching given query.
+// Returns requested page of instruments and total number of matched ones
+func (db Db) FindInstruments(q models.ListQuery) ([]models.Instrument, int64, error) {
+	filter, findOptions, err := instrumentsQuery.build(bson.M{}, q)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := db.instruments.CountDocuments(db.context(), filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	result, err := db.getInstruments(filter, findOptions)
+	return result, total, err
+}
+
+var instrumentsQuery = listQuery{
+	entity: "instrument",
+	filters: map[string]string{
+		"isin":     "isin",
+		"ticker":   "ticker",
+		"figi":     "figi",
+		"name":     "name",
+		"type":     "type",
+		"exchange": "exch",
+		"currency": "curr",
+	},
+	sorts: map[string]string{
+		"isin":     "isin",
+		"ticker":   "ticker",
+		"figi":     "figi",
+		"name":     "name",
+		"type":     "type",
+		"exchange": "exch",
+		"currency": "curr",
+	},
+	defaultSort: bson.D{{Key: "_id", Value: 1}},
+}
+
 // DeleteInstruments removes instruments depending on input prameters
 func (db Db) DeleteInstruments(key string, value string) (int64, error) {
 	filter := bson.M{key: value}
@@ -171,6 +213,7 @@ func (db Db) getInstruments(filter primitive.M, findOptions *options.FindOptions
 		Name          string    `bson:"name"`
 		Currency      string    `bson:"curr"`
 		Type          string    `bson:"type"`
+		Exchange      string    `bson:"exch"`
 		PriceUptdTime time.Time `bson:"lut"`
 	}
 
@@ -192,6 +235,7 @@ func (db Db) getInstruments(filter primitive.M, findOptions *options.FindOptions
 			Name:          item.Name,
 			Currency:      currency.Type(item.Currency),
 			Type:          instrument.Type(item.Type),
+			Exchange:      exchange.Type(item.Exchange),
 			PriceUptdTime: item.PriceUptdTime,
 		}
 		results[i] = data
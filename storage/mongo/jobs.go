@@ -0,0 +1,108 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type jobMongo struct {
+	Type      string          `bson:"type"`
+	Login     string          `bson:"login,omitempty"`
+	State     string          `bson:"state"`
+	Started   time.Time       `bson:"started"`
+	Finished  *time.Time      `bson:"finished,omitempty"`
+	Total     int             `bson:"total"`
+	Processed int             `bson:"processed"`
+	Error     string          `bson:"error,omitempty"`
+	Errors    []jobErrorMongo `bson:"errors,omitempty"`
+}
+
+type jobErrorMongo struct {
+	Item  string `bson:"item"`
+	Error string `bson:"error"`
+}
+
+// AddJob saves new background job run and returns its id
+func (db Db) AddJob(j models.Job) (string, error) {
+	ctx := db.context()
+	res, err := db.jobs.InsertOne(ctx, toJobMongo(j), options.InsertOne())
+	if err != nil {
+		return "", err
+	}
+	return res.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+// UpdateJob replaces state of background job run
+func (db Db) UpdateJob(j models.Job) (bool, error) {
+	id, err := primitive.ObjectIDFromHex(j.ID)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Could not decode job Id (%s). Internal error : %s", j.ID, err)
+	}
+	ctx := db.context()
+	res, err := db.jobs.ReplaceOne(ctx, bson.M{"_id": id}, toJobMongo(j), options.Replace())
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount != 0, nil
+}
+
+// GetJob finds background job run by id
+func (db Db) GetJob(id string) (models.Job, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Job{}, errs.New(errs.Validation, "Could not decode job Id (%s). Internal error : %s", id, err)
+	}
+	ctx := db.context()
+	var raw jobMongo
+	err = db.jobs.FindOne(ctx, bson.M{"_id": oid}, options.FindOne()).Decode(&raw)
+	if err == mongo.ErrNoDocuments {
+		return models.Job{}, errs.New(errs.NotFound, "No job found with %s Id", id)
+	}
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	result := models.Job{
+		ID:        id,
+		Type:      job.Type(raw.Type),
+		Login:     raw.Login,
+		State:     job.State(raw.State),
+		Started:   raw.Started,
+		Total:     raw.Total,
+		Processed: raw.Processed,
+		Error:     raw.Error,
+	}
+	if raw.Finished != nil {
+		result.Finished = *raw.Finished
+	}
+	for _, e := range raw.Errors {
+		result.Errors = append(result.Errors, models.JobError{Item: e.Item, Error: e.Error})
+	}
+	return result, nil
+}
+
+func toJobMongo(j models.Job) jobMongo {
+	result := jobMongo{
+		Type:      string(j.Type),
+		Login:     j.Login,
+		State:     string(j.State),
+		Started:   j.Started,
+		Total:     j.Total,
+		Processed: j.Processed,
+		Error:     j.Error,
+	}
+	if !j.Finished.IsZero() {
+		result.Finished = &j.Finished
+	}
+	for _, e := range j.Errors {
+		result.Errors = append(result.Errors, jobErrorMongo{Item: e.Item, Error: e.Error})
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+)
+
+func TestJobs(t *testing.T) {
+	started := time.Date(2020, 6, 6, 3, 0, 0, 0, time.UTC)
+	j := models.Job{Type: job.Prices, Login: "test", State: job.Running, Started: started, Total: 2}
+	id, err := db.AddJob(j)
+	if err != nil || id == "" {
+		t.Errorf("Fail! Error during add job: %v", err)
+	}
+
+	j.ID = id
+	j.Processed = 2
+	j.State = job.Completed
+	j.Finished = started.Add(time.Minute)
+	j.Errors = []models.JobError{{Item: "IDCC", Error: "no price provider"}}
+	ok, err := db.UpdateJob(j)
+	if !ok || err != nil {
+		t.Errorf("Fail! Error during update job: %v", err)
+	}
+
+	res, err := db.GetJob(id)
+	if err == nil && res.ID == id && res.Type == job.Prices && res.Login == "test" && res.State == job.Completed &&
+		res.Started.Equal(started) && res.Finished.Equal(j.Finished) && res.Total == 2 && res.Processed == 2 &&
+		len(res.Errors) == 1 && res.Errors[0] == j.Errors[0] {
+		t.Logf("Success! Expected %v, got %v", j, res)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v (%v)", j, res, err)
+	}
+
+	// system jobs have no user and running jobs have no finish time
+	id, _ = db.AddJob(models.Job{Type: job.Rates, State: job.Running, Started: started})
+	res, err = db.GetJob(id)
+	if err == nil && res.Login == "" && res.Finished.IsZero() && len(res.Errors) == 0 {
+		t.Logf("Success! Got expected job %v", res)
+	} else {
+		t.Errorf("Fail! Unexpected job %v (%v)", res, err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/kaseat/pManager/models/currency"
 	"github.com/kaseat/pManager/models/operation"
 	"github.com/kaseat/pManager/models/provider"
+	"github.com/kaseat/pManager/storage/errs"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -773,26 +774,20 @@ func TestMultipleOperations(t *testing.T) {
 		t.Logf("Success! Expected '0' got '%d'", resInt)
 	}
 
-	// throws no error when provided pid is not found
+	// throws not found error when provided pid is not found
 	resBool, err := db.DeleteOperation(unknownID, res[0].OperationID)
-	if err != nil {
-		t.Errorf("Fail! Unknown error: '%s'", err)
-	}
-	if resBool != false {
-		t.Errorf("Fail! Expected 'false' got '%v'", resBool)
+	if errs.KindOf(err) != errs.NotFound || resBool != false {
+		t.Errorf("Fail! Expected not found error got '%v', '%v'", resBool, err)
 	} else {
-		t.Logf("Success! Expected 'false' got '%v'", resBool)
+		t.Logf("Success! Got expected error: %s", err)
 	}
 
-	// throws no error when provided oid is not found
+	// throws not found error when provided oid is not found
 	resBool, err = db.DeleteOperation(pid.Hex(), unknownID)
-	if err != nil {
-		t.Errorf("Fail! Unknown error: '%s'", err)
-	}
-	if resBool != false {
-		t.Errorf("Fail! Expected 'false' got '%v'", resBool)
+	if errs.KindOf(err) != errs.NotFound || resBool != false {
+		t.Errorf("Fail! Expected not found error got '%v', '%v'", resBool, err)
 	} else {
-		t.Logf("Success! Expected 'false' got '%v'", resBool)
+		t.Logf("Success! Got expected error: %s", err)
 	}
 
 	remOp := res[0].OperationID
@@ -881,6 +876,48 @@ func TestSaveSingleOperation(t *testing.T) {
 	removeTestOperations(pid)
 }
 
+func TestFingerprintBackfill(t *testing.T) {
+	pid := addTestPortfolio()
+	ops := getOperations(getTime())
+	ops = append(ops, ops[0])
+	ids, err := db.AddOperations(pid.Hex(), ops)
+	if err != nil || ids[len(ids)-1] == "" {
+		t.Fatalf("Fail! Could not add operations %v: %v", ids, err)
+	}
+
+	// operations stored before fingerprints were introduced have none
+	ctx := db.context()
+	db.operations.UpdateMany(ctx, bson.M{"pid": pid}, bson.M{"$unset": bson.M{"fp": ""}})
+	err = db.backfillFingerprints()
+	if err != nil {
+		t.Fatalf("Fail! Could not backfill fingerprints: %v", err)
+	}
+
+	expected := models.Fingerprints(ops)
+	for i, id := range ids {
+		oid, _ := primitive.ObjectIDFromHex(id)
+		var doc struct {
+			Fingerprint string `bson:"fp"`
+		}
+		db.operations.FindOne(ctx, bson.M{"_id": oid}).Decode(&doc)
+		if doc.Fingerprint == expected[i] {
+			t.Logf("Success! Expected %v got %v", expected[i], doc.Fingerprint)
+		} else {
+			t.Errorf("Fail! Expected %v got %v", expected[i], doc.Fingerprint)
+		}
+	}
+
+	ids, _ = db.AddOperations(pid.Hex(), ops)
+	if ids[0] == "" && ids[len(ids)-1] == "" {
+		t.Logf("Success! Expected backfilled operations to be skipped, got %v", ids)
+	} else {
+		t.Errorf("Fail! Expected backfilled operations to be skipped, got %v", ids)
+	}
+
+	removeTestPortfolio(pid)
+	removeTestOperations(pid)
+}
+
 func addTestPortfolio() primitive.ObjectID {
 	ctx := db.context()
 	opts := options.InsertOne()
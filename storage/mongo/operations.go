@@ -1,25 +1,30 @@
 package mongo
 
 import (
-	"fmt"
 	"math"
 	"time"
 
 	"github.com/kaseat/pManager/models"
 	"github.com/kaseat/pManager/models/currency"
 	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/storage/errs"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// AddOperation saves single opertion into a storage
+// AddOperation saves single opertion into a storage.
+// Operation with the same fingerprint must not exist in portfolio
 func (db Db) AddOperation(pid string, op models.Operation) (string, error) {
 	ops := []models.Operation{op}
 	ids, err := db.AddOperations(pid, ops)
 	if err != nil {
 		return "", err
 	}
+	if ids[0] == "" {
+		return "", errs.New(errs.Conflict, "operation already exists")
+	}
 	return ids[0], nil
 }
 
@@ -27,11 +32,11 @@ func (db Db) AddOperation(pid string, op models.Operation) (string, error) {
 func (db Db) DeleteOperation(portfolioID string, operationID string) (bool, error) {
 	pid, err := primitive.ObjectIDFromHex(portfolioID)
 	if err != nil {
-		return false, fmt.Errorf("Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
+		return false, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
 	}
 	oid, err := primitive.ObjectIDFromHex(operationID)
 	if err != nil {
-		return false, fmt.Errorf("Could not decode operation Id (%s). Internal error : %s", operationID, err)
+		return false, errs.New(errs.Validation, "Could not decode operation Id (%s). Internal error : %s", operationID, err)
 	}
 	ctx := db.context()
 	filter := bson.M{"$and": []interface{}{bson.M{"_id": oid}, bson.M{"pid": pid}}}
@@ -41,17 +46,85 @@ func (db Db) DeleteOperation(portfolioID string, operationID string) (bool, erro
 	if err != nil {
 		return false, err
 	}
-	if res.DeletedCount >= 1 {
-		return true, nil
+	if res.DeletedCount == 0 {
+		return false, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
+	}
+	return true, nil
+}
+
+// GetOperation finds operation by Id
+func (db Db) GetOperation(portfolioID string, operationID string) (models.Operation, error) {
+	pid, err := primitive.ObjectIDFromHex(portfolioID)
+	if err != nil {
+		return models.Operation{}, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
+	}
+	oid, err := primitive.ObjectIDFromHex(operationID)
+	if err != nil {
+		return models.Operation{}, errs.New(errs.Validation, "Could not decode operation Id (%s). Internal error : %s", operationID, err)
+	}
+	filter := bson.M{"$and": []interface{}{bson.M{"_id": oid}, bson.M{"pid": pid}}}
+	ops, err := db.getOperations(filter, options.Find())
+	if err != nil {
+		return models.Operation{}, err
+	}
+	if len(ops) == 0 {
+		return models.Operation{}, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
 	}
-	return false, nil
+	return ops[0], nil
+}
+
+// UpdateOperation replaces operation data. Fingerprint is kept, so that
+// edited operation is still recognized when its report is imported again
+func (db Db) UpdateOperation(portfolioID string, operationID string, op models.Operation) (bool, error) {
+	pid, err := primitive.ObjectIDFromHex(portfolioID)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
+	}
+	oid, err := primitive.ObjectIDFromHex(operationID)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Could not decode operation Id (%s). Internal error : %s", operationID, err)
+	}
+	ctx := db.context()
+	filter := bson.M{"$and": []interface{}{bson.M{"_id": oid}, bson.M{"pid": pid}}}
+	set := bson.M{
+		"curr":   op.Currency,
+		"price":  int64(math.Round(op.Price * 1e6)),
+		"vol":    op.Volume,
+		"ticker": op.Ticker,
+		"time":   op.DateTime,
+		"type":   op.OperationType,
+	}
+	unset := bson.M{}
+	if op.FIGI != "" {
+		set["figi"] = op.FIGI
+	} else {
+		unset["figi"] = ""
+	}
+	if op.ISIN != "" {
+		set["isin"] = op.ISIN
+	} else {
+		unset["isin"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) != 0 {
+		update["$unset"] = unset
+	}
+
+	res, err := db.operations.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	if res.MatchedCount == 0 {
+		return false, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
+	}
+	return true, nil
 }
 
 // DeleteOperations removes all operations for provided portfolio Id
 func (db Db) DeleteOperations(portfolioID string) (int64, error) {
 	pid, err := primitive.ObjectIDFromHex(portfolioID)
 	if err != nil {
-		return 0, fmt.Errorf("Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
+		return 0, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
 	}
 
 	ctx := db.context()
@@ -65,20 +138,31 @@ func (db Db) DeleteOperations(portfolioID string) (int64, error) {
 	return res.DeletedCount, nil
 }
 
-// AddOperations saves multiple opertions into a storage
+// AddOperations saves multiple opertions into a storage.
+// Operations which fingerprint already exists in portfolio, or repeats
+// within the batch, are skipped. Fingerprints are taken from models.Fingerprints,
+// so identical fills are kept. Returned Ids follow input order,
+// skipped operations get empty Id
 func (db Db) AddOperations(portfolioID string, ops []models.Operation) ([]string, error) {
 	pid, err := db.findPortfolio(portfolioID)
 	if err != nil {
 		return nil, err
 	}
 	if pid.IsZero() {
-		return nil, fmt.Errorf("No portfolio found with %s Id", portfolioID)
+		return nil, errs.New(errs.NotFound, "No portfolio found with %s Id", portfolioID)
 	}
 
-	docs := make([]interface{}, len(ops))
-
-	for i, op := range ops {
+	docs := make([]interface{}, 0, len(ops))
+	indices := make([]int, 0, len(ops))
+	fingerprints := make(map[string]bool, len(ops))
+	for i, fp := range models.Fingerprints(ops) {
+		if fingerprints[fp] {
+			continue
+		}
+		fingerprints[fp] = true
+		op := ops[i]
 		doc := bson.M{
+			"_id":    primitive.NewObjectID(),
 			"pid":    pid,
 			"curr":   op.Currency,
 			"price":  int64(math.Round(op.Price * 1e6)),
@@ -86,6 +170,7 @@ func (db Db) AddOperations(portfolioID string, ops []models.Operation) ([]string
 			"ticker": op.Ticker,
 			"time":   op.DateTime,
 			"type":   op.OperationType,
+			"fp":     fp,
 		}
 		if op.FIGI != "" {
 			doc["figi"] = op.FIGI
@@ -93,21 +178,32 @@ func (db Db) AddOperations(portfolioID string, ops []models.Operation) ([]string
 		if op.ISIN != "" {
 			doc["isin"] = op.ISIN
 		}
-		docs[i] = doc
+		docs = append(docs, doc)
+		indices = append(indices, i)
 	}
 
+	// unordered insert keeps going after duplicate key errors
 	ctx := db.context()
-	opts := options.InsertMany()
-	res, err := db.operations.InsertMany(ctx, docs, opts)
-	if err != nil {
+	opts := options.InsertMany().SetOrdered(false)
+	_, err = db.operations.InsertMany(ctx, docs, opts)
+	duplicates := make(map[int]bool)
+	if bwe, ok := err.(mongo.BulkWriteException); ok && bwe.WriteConcernError == nil {
+		for _, we := range bwe.WriteErrors {
+			if we.Code != duplicateKeyCode {
+				return nil, err
+			}
+			duplicates[we.Index] = true
+		}
+	} else if err != nil {
 		return nil, err
 	}
 
-	ids := make([]string, len(res.InsertedIDs))
-	for i, id := range res.InsertedIDs {
-		ids[i] = id.(primitive.ObjectID).Hex()
+	ids := make([]string, len(ops))
+	for n, doc := range docs {
+		if !duplicates[n] {
+			ids[indices[n]] = doc.(bson.M)["_id"].(primitive.ObjectID).Hex()
+		}
 	}
-
 	return ids, nil
 }
 
@@ -115,7 +211,7 @@ func (db Db) AddOperations(portfolioID string, ops []models.Operation) ([]string
 func (db Db) GetOperations(portfolioID string, key string, value string, from string, to string) ([]models.Operation, error) {
 	pid, err := primitive.ObjectIDFromHex(portfolioID)
 	if err != nil {
-		return nil, fmt.Errorf("Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
+		return nil, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
 	}
 
 	filter := bson.M{"pid": pid}
@@ -145,6 +241,48 @@ func (db Db) GetOperations(portfolioID string, key string, value string, from st
 	return db.getOperations(filter, findOptions)
 }
 
+// FindOperations finds portfolio operations matching given query.
+// Returns requested page of operations and total number of matched ones
+func (db Db) FindOperations(portfolioID string, q models.ListQuery) ([]models.Operation, int64, error) {
+	pid, err := primitive.ObjectIDFromHex(portfolioID)
+	if err != nil {
+		return nil, 0, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
+	}
+	filter, findOptions, err := operationsQuery.build(bson.M{"pid": pid}, q)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := db.operations.CountDocuments(db.context(), filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	result, err := db.getOperations(filter, findOptions)
+	return result, total, err
+}
+
+var operationsQuery = listQuery{
+	entity: "operation",
+	filters: map[string]string{
+		"isin":     "isin",
+		"ticker":   "ticker",
+		"figi":     "figi",
+		"currency": "curr",
+		"type":     "type",
+	},
+	sorts: map[string]string{
+		"date":     "time",
+		"price":    "price",
+		"vol":      "vol",
+		"type":     "type",
+		"ticker":   "ticker",
+		"isin":     "isin",
+		"figi":     "figi",
+		"currency": "curr",
+	},
+	timeField:   "time",
+	defaultSort: bson.D{{Key: "time", Value: 1}, {Key: "_id", Value: 1}},
+}
+
 // Checks if portfolio with specified _id exists. Then needs to be checked on .IsZero()
 func (db Db) findPortfolio(pid string) (primitive.ObjectID, error) {
 	id, err := primitive.ObjectIDFromHex(pid)
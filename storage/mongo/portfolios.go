@@ -1,11 +1,11 @@
 package mongo
 
 import (
-	"fmt"
-
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -17,7 +17,7 @@ func (db Db) AddPortfolio(userID string, p models.Portfolio) (string, error) {
 		return "", err
 	}
 	if uid.IsZero() {
-		return "", fmt.Errorf("No user found with %s Id", userID)
+		return "", errs.New(errs.NotFound, "No user found with %s Id", userID)
 	}
 
 	doc := bson.M{
@@ -42,11 +42,11 @@ func (db Db) GetPortfolio(userID string, portfolioID string) (models.Portfolio,
 
 	uid, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return result, fmt.Errorf("Could not decode user Id (%s). Internal error : %s", userID, err)
+		return result, errs.New(errs.Validation, "Could not decode user Id (%s). Internal error : %s", userID, err)
 	}
 	pid, err := primitive.ObjectIDFromHex(portfolioID)
 	if err != nil {
-		return result, fmt.Errorf("Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
+		return result, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
 	}
 
 	filter := bson.M{"$and": []interface{}{bson.M{"_id": pid}, bson.M{"uid": uid}}}
@@ -55,6 +55,9 @@ func (db Db) GetPortfolio(userID string, portfolioID string) (models.Portfolio,
 
 	r := db.portfolios.FindOne(ctx, filter, findOptions)
 
+	if r.Err() == mongo.ErrNoDocuments {
+		return result, errs.New(errs.NotFound, "No portfolio found with %s Id", portfolioID)
+	}
 	if r.Err() != nil {
 		return result, r.Err()
 	}
@@ -78,7 +81,7 @@ func (db Db) GetPortfolio(userID string, portfolioID string) (models.Portfolio,
 func (db Db) GetPortfolios(userID string) ([]models.Portfolio, error) {
 	uid, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return nil, fmt.Errorf("Could not decode user Id (%s). Internal error : %s", userID, err)
+		return nil, errs.New(errs.Validation, "Could not decode user Id (%s). Internal error : %s", userID, err)
 	}
 
 	filter := bson.M{"uid": uid}
@@ -112,11 +115,11 @@ func (db Db) GetPortfolios(userID string) ([]models.Portfolio, error) {
 func (db Db) UpdatePortfolio(userID string, portfolioID string, p models.Portfolio) (bool, error) {
 	uid, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return false, fmt.Errorf("Could not decode user Id (%s). Internal error : %s", userID, err)
+		return false, errs.New(errs.Validation, "Could not decode user Id (%s). Internal error : %s", userID, err)
 	}
 	pid, err := primitive.ObjectIDFromHex(portfolioID)
 	if err != nil {
-		return false, fmt.Errorf("Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
+		return false, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
 	}
 	ctx := db.context()
 	filter := bson.M{"$and": []interface{}{bson.M{"_id": pid}, bson.M{"uid": uid}}}
@@ -142,11 +145,11 @@ func (db Db) UpdatePortfolio(userID string, portfolioID string, p models.Portfol
 func (db Db) DeletePortfolio(userID string, portfolioID string) (bool, error) {
 	uid, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return false, fmt.Errorf("Could not decode user Id (%s). Internal error : %s", userID, err)
+		return false, errs.New(errs.Validation, "Could not decode user Id (%s). Internal error : %s", userID, err)
 	}
 	pid, err := primitive.ObjectIDFromHex(portfolioID)
 	if err != nil {
-		return false, fmt.Errorf("Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
+		return false, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", portfolioID, err)
 	}
 
 	ctx := db.context()
@@ -175,7 +178,7 @@ func (db Db) DeletePortfolio(userID string, portfolioID string) (bool, error) {
 func (db Db) DeletePortfolios(userID string) (int64, error) {
 	uid, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return 0, fmt.Errorf("Could not decode portfolio Id (%s). Internal error : %s", userID, err)
+		return 0, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", userID, err)
 	}
 
 	ctx := db.context()
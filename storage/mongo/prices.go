@@ -74,6 +74,37 @@ func (db Db) GetPricesByIsin(isin, from, to string) ([]models.Price, error) {
 	return db.getPrices(filter, findOptions)
 }
 
+// FindPrices finds prices matching given query. Prices are stored with ISIN
+// only, so they can't be filtered by other instrument fields.
+// Returns requested page of prices and total number of matched ones
+func (db Db) FindPrices(q models.ListQuery) ([]models.Price, int64, error) {
+	filter, findOptions, err := pricesQuery.build(bson.M{}, q)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := db.prices.CountDocuments(db.context(), filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	result, err := db.getPrices(filter, findOptions)
+	return result, total, err
+}
+
+var pricesQuery = listQuery{
+	entity: "price",
+	filters: map[string]string{
+		"isin": "isin",
+	},
+	sorts: map[string]string{
+		"date":  "time",
+		"isin":  "isin",
+		"price": "price",
+		"vol":   "vol",
+	},
+	timeField:   "time",
+	defaultSort: bson.D{{Key: "time", Value: 1}, {Key: "_id", Value: 1}},
+}
+
 // DeletePrices removes prices depending on input prameters
 func (db Db) DeletePrices(key string, value string) (int64, error) {
 	filter := bson.M{key: value}
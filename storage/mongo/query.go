@@ -0,0 +1,73 @@
+package mongo
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// listQuery describes how list query keys map to document fields of a collection
+type listQuery struct {
+	entity      string
+	filters     map[string]string
+	sorts       map[string]string
+	timeField   string
+	defaultSort bson.D
+}
+
+// build makes find filter and options for given query. Values of a filter
+// are combined with $in, different filters with $and
+func (l listQuery) build(base primitive.M, q models.ListQuery) (primitive.M, *options.FindOptions, error) {
+	keys := make([]string, 0, len(q.Filters))
+	for key := range q.Filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	and := []interface{}{base}
+	for _, key := range keys {
+		field, ok := l.filters[key]
+		if !ok {
+			return nil, nil, errs.New(errs.Validation, "unknown %s filter: %s", l.entity, key)
+		}
+		if len(q.Filters[key]) != 0 {
+			and = append(and, bson.M{field: bson.M{"$in": q.Filters[key]}})
+		}
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.From); err == nil && l.timeField != "" {
+		and = append(and, bson.M{l.timeField: bson.M{"$gte": dtime}})
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.To); err == nil && l.timeField != "" {
+		and = append(and, bson.M{l.timeField: bson.M{"$lte": dtime}})
+	}
+
+	order := bson.D{}
+	for _, key := range q.Sort {
+		field, ok := l.sorts[strings.TrimPrefix(key, "-")]
+		if !ok {
+			return nil, nil, errs.New(errs.Validation, "unknown %s sort key: %s", l.entity, strings.TrimPrefix(key, "-"))
+		}
+		dir := 1
+		if strings.HasPrefix(key, "-") {
+			dir = -1
+		}
+		order = append(order, bson.E{Key: field, Value: dir})
+	}
+	order = append(order, l.defaultSort...)
+
+	findOptions := options.Find()
+	findOptions.SetSort(order)
+	if q.Offset > 0 {
+		findOptions.SetSkip(int64(q.Offset))
+	}
+	if q.Limit > 0 {
+		findOptions.SetLimit(int64(q.Limit))
+	}
+	return bson.M{"$and": and}, findOptions, nil
+}
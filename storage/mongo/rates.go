@@ -0,0 +1,125 @@
+package mongo
+
+import (
+	"math"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddRates saves currency rates into a storage. Existing rates are overwritten
+func (db Db) AddRates(rates []models.Rate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, len(rates))
+	for i, r := range rates {
+		filter := bson.M{"currency": string(r.Currency), "time": r.Date}
+		doc := bson.M{
+			"currency": string(r.Currency),
+			"time":     r.Date,
+			"rate":     int64(math.Round(r.Rate * 1e6)),
+		}
+		writes[i] = mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(doc).SetUpsert(true)
+	}
+
+	ctx := db.context()
+	_, err := db.rates.BulkWrite(ctx, writes, options.BulkWrite())
+	return err
+}
+
+// GetRates finds rates of given currency between dates. Empty currency means all currencies
+func (db Db) GetRates(curr currency.Type, from, to string) ([]models.Rate, error) {
+	filter := bson.M{}
+	if curr != "" {
+		filter["currency"] = string(curr)
+	}
+	bounds := bson.M{}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", from); err == nil {
+		bounds["$gte"] = dtime
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", to); err == nil {
+		bounds["$lte"] = dtime
+	}
+	if len(bounds) != 0 {
+		filter["time"] = bounds
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "time", Value: 1}, {Key: "currency", Value: 1}})
+
+	ctx := db.context()
+	cur, err := db.rates.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var raw []struct {
+		Currency string    `bson:"currency"`
+		Date     time.Time `bson:"time"`
+		Rate     int64     `bson:"rate"`
+	}
+	err = cur.All(ctx, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Rate, len(raw))
+	for i, r := range raw {
+		result[i] = models.Rate{
+			Currency: currency.Type(r.Currency),
+			Date:     r.Date,
+			Rate:     float64(r.Rate) / 1e6,
+		}
+	}
+	return result, nil
+}
+
+// DeleteRates removes rates of given currency. Empty currency means all currencies
+func (db Db) DeleteRates(curr currency.Type) (int64, error) {
+	filter := bson.M{}
+	if curr != "" {
+		filter["currency"] = string(curr)
+	}
+	ctx := db.context()
+	del, err := db.rates.DeleteMany(ctx, filter, options.Delete())
+	if err != nil {
+		return 0, err
+	}
+	return del.DeletedCount, nil
+}
+
+// SetRateUptdTime sets time currency rates were updated
+func (db Db) SetRateUptdTime(curr currency.Type, updTime time.Time) (bool, error) {
+	ctx := db.context()
+	filter := bson.M{"code": string(curr)}
+	update := bson.M{"$set": bson.M{"lut": updTime}}
+	u, err := db.currencies.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return u.ModifiedCount == 1 || u.UpsertedCount == 1, nil
+}
+
+// GetRateUptdTime gets time currency rates were updated. Zero time means never
+func (db Db) GetRateUptdTime(curr currency.Type) (time.Time, error) {
+	ctx := db.context()
+	filter := bson.M{"code": string(curr)}
+	var raw struct {
+		UpdTime time.Time `bson:"lut"`
+	}
+	err := db.currencies.FindOne(ctx, filter).Decode(&raw)
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return raw.UpdTime, nil
+}
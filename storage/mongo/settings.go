@@ -1,7 +1,13 @@
 package mongo
 
 import (
+	"sort"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -30,8 +36,12 @@ func (db Db) AddTcsToken(token string) error {
 func (db Db) DeleteTcsToken() error {
 	ctx := db.context()
 	filter := bson.M{}
-	delOptions := options.Delete()
-	_, err := db.settings.DeleteMany(ctx, filter, delOptions)
+	update := bson.M{
+		"$unset": bson.M{
+			"token": "",
+		},
+	}
+	_, err := db.settings.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
@@ -53,3 +63,117 @@ func (db Db) GetTcsToken() (string, error) {
 	}
 	return raw.Token, nil
 }
+
+type scheduleMongo struct {
+	ID          string    `bson:"id"`
+	Job         string    `bson:"job"`
+	Cron        string    `bson:"cron"`
+	Enabled     bool      `bson:"enabled"`
+	Login       string    `bson:"login,omitempty"`
+	PortfolioID string    `bson:"pid,omitempty"`
+	LastRun     time.Time `bson:"last_run,omitempty"`
+	LastJobID   string    `bson:"last_job_id,omitempty"`
+	LastStatus  string    `bson:"last_status,omitempty"`
+	LastError   string    `bson:"last_error,omitempty"`
+}
+
+// GetSchedules returns all background job schedules sorted by id
+func (db Db) GetSchedules() ([]models.Schedule, error) {
+	schedules, err := db.getSchedules()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.Schedule, len(schedules))
+	for i, sch := range schedules {
+		result[i] = models.Schedule{
+			ID:          sch.ID,
+			Job:         job.Type(sch.Job),
+			Cron:        sch.Cron,
+			Enabled:     sch.Enabled,
+			Login:       sch.Login,
+			PortfolioID: sch.PortfolioID,
+			LastRun:     sch.LastRun,
+			LastJobID:   sch.LastJobID,
+			LastStatus:  sch.LastStatus,
+			LastError:   sch.LastError,
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// SaveSchedule adds background job schedule or replaces existing one with the same id
+func (db Db) SaveSchedule(sch models.Schedule) error {
+	schedules, err := db.getSchedules()
+	if err != nil {
+		return err
+	}
+	raw := scheduleMongo{
+		ID:          sch.ID,
+		Job:         string(sch.Job),
+		Cron:        sch.Cron,
+		Enabled:     sch.Enabled,
+		Login:       sch.Login,
+		PortfolioID: sch.PortfolioID,
+		LastRun:     sch.LastRun,
+		LastJobID:   sch.LastJobID,
+		LastStatus:  sch.LastStatus,
+		LastError:   sch.LastError,
+	}
+	replaced := false
+	for i := range schedules {
+		if schedules[i].ID == sch.ID {
+			schedules[i] = raw
+			replaced = true
+		}
+	}
+	if !replaced {
+		schedules = append(schedules, raw)
+	}
+	return db.setSchedules(schedules)
+}
+
+// DeleteSchedule removes background job schedule
+func (db Db) DeleteSchedule(id string) (bool, error) {
+	schedules, err := db.getSchedules()
+	if err != nil {
+		return false, err
+	}
+	result := schedules[:0]
+	for _, sch := range schedules {
+		if sch.ID != id {
+			result = append(result, sch)
+		}
+	}
+	if len(result) == len(schedules) {
+		return false, nil
+	}
+	return true, db.setSchedules(result)
+}
+
+// schedules are kept as array in settings document since
+// schedule id may contain dots which are not allowed in field paths
+func (db Db) getSchedules() ([]scheduleMongo, error) {
+	ctx := db.context()
+	var raw struct {
+		Schedules []scheduleMongo `bson:"schedules"`
+	}
+	err := db.settings.FindOne(ctx, bson.M{}).Decode(&raw)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	return raw.Schedules, nil
+}
+
+func (db Db) setSchedules(schedules []scheduleMongo) error {
+	ctx := db.context()
+	opts := options.Update()
+	opts.SetUpsert(true)
+	update := bson.M{
+		"$set": bson.M{
+			"schedules": schedules,
+		},
+	}
+	_, err := db.settings.UpdateOne(ctx, bson.M{}, update, opts)
+	return err
+}
@@ -1,6 +1,11 @@
 package mongo
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+)
 
 func TestTcsTokenStorage(t *testing.T) {
 	token := "test_token"
@@ -25,3 +30,34 @@ func TestTcsTokenStorage(t *testing.T) {
 		t.Errorf("Fail! Expected empty string, got %v", res)
 	}
 }
+
+func TestSchedules(t *testing.T) {
+	prices := models.Schedule{ID: "prices", Job: "prices", Cron: "0 3 * * *", Enabled: true}
+	gmail := models.Schedule{ID: "gmail:test", Job: "gmail", Cron: "0 5 * * *", Login: "test", PortfolioID: "1"}
+	if err := db.SaveSchedule(prices); err != nil {
+		t.Errorf("Fail! Error during save schedule: %v", err)
+	}
+	db.SaveSchedule(gmail)
+
+	prices.LastRun = time.Date(2020, 6, 6, 3, 0, 0, 0, time.UTC)
+	prices.LastStatus = "error"
+	prices.LastError = "test error"
+	db.SaveSchedule(prices)
+
+	res, _ := db.GetSchedules()
+	if len(res) == 2 && res[0].ID == gmail.ID && res[0].Login == gmail.Login && res[0].PortfolioID == gmail.PortfolioID &&
+		res[1].ID == prices.ID && res[1].Cron == prices.Cron && res[1].Enabled && res[1].LastRun.Equal(prices.LastRun) && res[1].LastError == prices.LastError {
+		t.Logf("Success! Expected %v, got %v", []models.Schedule{gmail, prices}, res)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", []models.Schedule{gmail, prices}, res)
+	}
+
+	ok, _ := db.DeleteSchedule(gmail.ID)
+	res, _ = db.GetSchedules()
+	if ok && len(res) == 1 {
+		t.Logf("Success! Expected %v schedules, got %v", 1, len(res))
+	} else {
+		t.Errorf("Fail! Expected %v schedules, got %v", 1, len(res))
+	}
+	db.DeleteSchedule(prices.ID)
+}
@@ -1,11 +1,11 @@
 package mongo
 
 import (
-	"fmt"
 	"log"
 	"time"
 
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -21,7 +21,7 @@ func (db Db) GetShares(pid string, onDate string) ([]models.Share, error) {
 	defer timeTrack(time.Now(), "GetShares")
 	p, err := primitive.ObjectIDFromHex(pid)
 	if err != nil {
-		return nil, fmt.Errorf("Could not decode portfolio Id (%s). Internal error : %s", pid, err)
+		return nil, errs.New(errs.Validation, "Could not decode portfolio Id (%s). Internal error : %s", pid, err)
 	}
 	dtime := time.Now()
 	if t, err := time.Parse("2006-01-02T15:04:05Z07:00", onDate); err == nil {
@@ -48,9 +48,9 @@ func (db Db) GetShares(pid string, onDate string) ([]models.Share, error) {
 		{Key: "price", Value: bson.M{
 			"$switch": bson.D{
 				{Key: "branches", Value: []bson.D{
-					{{Key: "case", Value: bson.M{"$in": []interface{}{"$type", []string{"buyback", "payIn", "accruedInterestSell", "sell"}}}},
+					{{Key: "case", Value: bson.M{"$in": []interface{}{"$type", []string{"buyback", "payIn", "accruedInterestSell", "sell", "coupon", "dividend", "amortization"}}}},
 						{Key: "then", Value: bson.M{"$multiply": []string{"$vol", "$price"}}}},
-					{{Key: "case", Value: bson.M{"$not": bson.M{"$in": []interface{}{"$type", []string{"buyback", "payIn", "accruedInterestSell", "sell"}}}}},
+					{{Key: "case", Value: bson.M{"$not": bson.M{"$in": []interface{}{"$type", []string{"buyback", "payIn", "accruedInterestSell", "sell", "coupon", "dividend", "amortization"}}}}},
 						{Key: "then", Value: bson.M{"$multiply": []interface{}{"$vol", "$price", -1}}}},
 				}},
 				{Key: "default", Value: 0},
@@ -21,6 +21,11 @@ type Db struct {
 	portfolios  *mongo.Collection
 	users       *mongo.Collection
 	prices      *mongo.Collection
+	rates       *mongo.Collection
+	events      *mongo.Collection
+	actions     *mongo.Collection
+	currencies  *mongo.Collection
+	jobs        *mongo.Collection
 	instruments *mongo.Collection
 	settings    *mongo.Collection
 	context     dbContext
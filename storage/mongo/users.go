@@ -1,10 +1,10 @@
 package mongo
 
 import (
-	"errors"
 	"time"
 
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -32,7 +32,7 @@ func (db Db) AddUser(login, email, hash string) (string, error) {
 	} else if found.Err() != nil {
 		return "", found.Err()
 	}
-	return "", errors.New("User with this login already exists")
+	return "", errs.New(errs.Conflict, "User with this login already exists")
 }
 
 // UpdateUser updates user info
@@ -156,7 +156,7 @@ func (db Db) GetUserByLogin(login string) (models.User, error) {
 
 	res := db.users.FindOne(ctx, filter, opts)
 	if res.Err() == mongo.ErrNoDocuments {
-		return result, res.Err()
+		return result, errs.New(errs.NotFound, "could not find user with login: %s", login)
 	}
 
 	var data struct {
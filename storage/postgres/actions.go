@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/kaseat/pManager/models"
+)
+
+// AddCorporateActions saves corporate actions into a storage. Existing actions are overwritten
+func (db Db) AddCorporateActions(actions []models.CorporateAction) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	isins := make([]string, len(actions))
+	types := make([]string, len(actions))
+	dates := make([]time.Time, len(actions))
+	froms := make([]int64, len(actions))
+	tos := make([]int64, len(actions))
+	newIsins := make([]string, len(actions))
+	newTickers := make([]string, len(actions))
+	costRatios := make([]float64, len(actions))
+	for i, a := range actions {
+		isins[i] = a.ISIN
+		types[i] = string(a.Type)
+		dates[i] = a.Date
+		froms[i] = a.RatioFrom
+		tos[i] = a.RatioTo
+		newIsins[i] = a.NewISIN
+		newTickers[i] = a.NewTicker
+		costRatios[i] = a.CostRatio
+	}
+
+	query := `insert into corporate_actions (isin,type,date,ratio_from,ratio_to,new_isin,new_ticker,cost_ratio)
+	select isin,type,date,ratio_from,ratio_to,nullif(new_isin,''),nullif(new_ticker,''),cost_ratio
+	from unnest($1::varchar(12)[], $2::varchar(20)[], $3::date[], $4::bigint[], $5::bigint[], $6::varchar(12)[], $7::varchar(12)[], $8::numeric[])
+		as x(isin,type,date,ratio_from,ratio_to,new_isin,new_ticker,cost_ratio)
+	on conflict (isin,type,date) do update set
+		ratio_from = excluded.ratio_from,
+		ratio_to = excluded.ratio_to,
+		new_isin = excluded.new_isin,
+		new_ticker = excluded.new_ticker,
+		cost_ratio = excluded.cost_ratio;`
+	_, err := db.connection.Exec(db.context, query, isins, types, dates, froms, tos, newIsins, newTickers, costRatios)
+	return err
+}
+
+// GetCorporateActions finds corporate actions of given security sorted by date.
+// Empty ISIN means all securities
+func (db Db) GetCorporateActions(isin string) ([]models.CorporateAction, error) {
+	params := []interface{}{}
+	query := `select isin,type,date,ratio_from,ratio_to,coalesce(new_isin,''),coalesce(new_ticker,''),cost_ratio
+	from corporate_actions where 1 = 1`
+	if isin != "" {
+		params = append(params, isin)
+		query += fmt.Sprintf(" and isin = $%d", len(params))
+	}
+	query += " order by date, isin, type;"
+
+	rows, err := db.connection.Query(db.context, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []models.CorporateAction{}
+	for rows.Next() {
+		a := models.CorporateAction{}
+		err = rows.Scan(&a.ISIN, &a.Type, &a.Date, &a.RatioFrom, &a.RatioTo, &a.NewISIN, &a.NewTicker, &a.CostRatio)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+// DeleteCorporateActions removes corporate actions of given security. Empty ISIN means all securities
+func (db Db) DeleteCorporateActions(isin string) (int64, error) {
+	var r pgconn.CommandTag
+	var err error
+	if isin != "" {
+		r, err = db.connection.Exec(db.context, "delete from corporate_actions where isin = $1;", isin)
+	} else {
+		r, err = db.connection.Exec(db.context, "delete from corporate_actions;")
+	}
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected(), nil
+}
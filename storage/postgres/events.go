@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/kaseat/pManager/models"
+)
+
+// AddSecurityEvents saves security events into a storage. Existing events are overwritten
+func (db Db) AddSecurityEvents(events []models.SecurityEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	isins := make([]string, len(events))
+	types := make([]string, len(events))
+	dates := make([]time.Time, len(events))
+	values := make([]float64, len(events))
+	currs := make([]string, len(events))
+	for i, e := range events {
+		isins[i] = e.ISIN
+		types[i] = string(e.Type)
+		dates[i] = e.Date
+		values[i] = e.Value
+		currs[i] = string(e.Currency)
+	}
+
+	query := `insert into security_events (isin,type,date,value,currency)
+	select * from unnest($1::varchar(12)[], $2::varchar(20)[], $3::date[], $4::numeric[], $5::char(3)[])
+	on conflict (isin,type,date) do update set value = excluded.value, currency = excluded.currency;`
+	_, err := db.connection.Exec(db.context, query, isins, types, dates, values, currs)
+	return err
+}
+
+// GetSecurityEvents finds events of given security between dates. Empty ISIN means all securities
+func (db Db) GetSecurityEvents(isin, from, to string) ([]models.SecurityEvent, error) {
+	params := []interface{}{}
+	query := "select isin,type,date,value,currency from security_events where 1 = 1"
+	if isin != "" {
+		params = append(params, isin)
+		query += fmt.Sprintf(" and isin = $%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", from); err == nil {
+		params = append(params, dtime)
+		query += fmt.Sprintf(" and date >= $%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", to); err == nil {
+		params = append(params, dtime)
+		query += fmt.Sprintf(" and date <= $%d", len(params))
+	}
+	query += " order by date, isin, type;"
+
+	rows, err := db.connection.Query(db.context, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []models.SecurityEvent{}
+	for rows.Next() {
+		e := models.SecurityEvent{}
+		err = rows.Scan(&e.ISIN, &e.Type, &e.Date, &e.Value, &e.Currency)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// DeleteSecurityEvents removes events of given security. Empty ISIN means all securities
+func (db Db) DeleteSecurityEvents(isin string) (int64, error) {
+	var r pgconn.CommandTag
+	var err error
+	if isin != "" {
+		r, err = db.connection.Exec(db.context, "delete from security_events where isin = $1;", isin)
+	} else {
+		r, err = db.connection.Exec(db.context, "delete from security_events;")
+	}
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected(), nil
+}
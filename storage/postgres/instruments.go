@@ -9,6 +9,7 @@ import (
 	"github.com/kaseat/pManager/models"
 	"github.com/kaseat/pManager/models/exchange"
 	"github.com/kaseat/pManager/models/instrument"
+	"github.com/kaseat/pManager/storage/errs"
 )
 
 // AddInstruments saves instruments info into a storage
@@ -27,7 +28,7 @@ func (db Db) AddInstruments(instr []models.Instrument) error {
 		return err
 	}
 	if pgerr.Code == "23503" {
-		return fmt.Errorf("could not add instrument: error in column %s", pgerr.ColumnName)
+		return errs.New(errs.Validation, "could not add instrument: error in column %s", pgerr.ColumnName)
 	}
 	return nil
 }
@@ -131,6 +132,50 @@ func (db Db) GetAllInstruments() ([]models.Instrument, error) {
 	return result, nil
 }
 
+// FindInstruments finds instruments matching given query.
+// Returns requested page of instruments and total number of matched ones
+func (db Db) FindInstruments(q models.ListQuery) ([]models.Instrument, int64, error) {
+	from := ` from securities s inner join securities_types t on t.id = s.asset_type
+		inner join exchange e on e.id = s.exchange_id where 1 = 1`
+	where, params, err := filterClause(q.Filters, instrumentColumns, "instrument", []interface{}{})
+	if err != nil {
+		return nil, 0, err
+	}
+	from += where
+	order, err := orderClause(q.Sort, instrumentColumns, "instrument", "s.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = db.connection.QueryRow(db.context, "select count(*)"+from+";", params...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := "select s.id,isin,ticker,figi,currency,code,id_name,s.title,price_upd_time" +
+		from + order + limitClause(q.Limit, q.Offset) + ";"
+	rows, err := db.connection.Query(db.context, query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	result := []models.Instrument{}
+	for rows.Next() {
+		ins := models.Instrument{}
+		var tm *time.Time
+		err = rows.Scan(&ins.SecID, &ins.ISIN, &ins.Ticker, &ins.FIGI, &ins.Currency, &ins.Exchange, &ins.Type, &ins.Name, &tm)
+		if err != nil {
+			return nil, 0, err
+		}
+		if tm != nil {
+			ins.PriceUptdTime = *tm
+		}
+		result = append(result, ins)
+	}
+	return result, total, rows.Err()
+}
+
 // DeleteInstruments removes instruments depending on input prameters
 func (db Db) DeleteInstruments(key string, value string) (int64, error) {
 	var r pgconn.CommandTag
@@ -158,6 +203,18 @@ func (db Db) DeleteAllInstruments() (int64, error) {
 	return r.RowsAffected(), nil
 }
 
+// instrumentColumns maps instrument filter and sort keys to columns
+var instrumentColumns = map[string]string{
+	"id":       "s.id",
+	"isin":     "s.isin",
+	"ticker":   "s.ticker",
+	"figi":     "s.figi",
+	"name":     "s.title",
+	"type":     "t.id_name",
+	"exchange": "e.code",
+	"currency": "s.currency",
+}
+
 func getsecuritiesTypeByName() map[instrument.Type]int {
 	return map[instrument.Type]int{
 		instrument.Stock:       10,
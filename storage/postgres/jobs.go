@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddJob saves new background job run and returns its id
+func (db Db) AddJob(j models.Job) (string, error) {
+	jobErrs, err := json.Marshal(j.Errors)
+	if err != nil {
+		return "", err
+	}
+	var id int
+	query := `insert into jobs (type,login,state,started,finished,total,processed,error,errors)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,$9) returning id;`
+	err = db.connection.QueryRow(db.context, query, string(j.Type), nullString(j.Login), string(j.State),
+		j.Started.UTC(), nullTime(j.Finished), j.Total, j.Processed, nullString(j.Error), string(jobErrs)).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(id), nil
+}
+
+// UpdateJob replaces state of background job run
+func (db Db) UpdateJob(j models.Job) (bool, error) {
+	id, err := strconv.ParseInt(j.ID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid job Id format. Expected positive number")
+	}
+	jobErrs, err := json.Marshal(j.Errors)
+	if err != nil {
+		return false, err
+	}
+	query := `update jobs set type = $2, login = $3, state = $4, started = $5, finished = $6,
+		total = $7, processed = $8, error = $9, errors = $10 where id = $1;`
+	r, err := db.connection.Exec(db.context, query, id, string(j.Type), nullString(j.Login), string(j.State),
+		j.Started.UTC(), nullTime(j.Finished), j.Total, j.Processed, nullString(j.Error), string(jobErrs))
+	if err != nil {
+		return false, err
+	}
+	return r.RowsAffected() != 0, nil
+}
+
+// GetJob finds background job run by id
+func (db Db) GetJob(id string) (models.Job, error) {
+	result := models.Job{}
+	jid, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return result, errs.New(errs.Validation, "Invalid job Id format. Expected positive number")
+	}
+
+	var jobType, state string
+	var login, jobErr *string
+	var finished *time.Time
+	var jobErrs []byte
+	query := "select type,login,state,started,finished,total,processed,error,errors from jobs where id = $1;"
+	err = db.connection.QueryRow(db.context, query, jid).Scan(&jobType, &login, &state,
+		&result.Started, &finished, &result.Total, &result.Processed, &jobErr, &jobErrs)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return result, errs.New(errs.NotFound, "No job found with %s Id", id)
+		}
+		return result, err
+	}
+	if jobErrs != nil {
+		if err = json.Unmarshal(jobErrs, &result.Errors); err != nil {
+			return result, err
+		}
+	}
+	result.ID = id
+	result.Type = job.Type(jobType)
+	result.State = job.State(state)
+	if login != nil {
+		result.Login = *login
+	}
+	if jobErr != nil {
+		result.Error = *jobErr
+	}
+	if finished != nil {
+		result.Finished = *finished
+	}
+	return result, nil
+}
+
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,505 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// migration represents single schema change. Every migration runs in
+// its own transaction along with schema_migrations bookkeeping
+type migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// advisory lock key, prevents several instances from migrating simultaneously
+const migrationLockKey = 736155121
+
+// migrations must be sorted by version with no gaps. Never edit migration
+// which has been released, add a new one instead. Up scripts must be
+// idempotent since db/deploy.sh creates the latest schema without
+// schema_migrations records
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		// idempotent, so databases deployed with db/deploy.sh
+		// are adopted with all their data
+		Up: `
+CREATE OR REPLACE FUNCTION pseudo_encrypt_24(VALUE int) returns int AS $$
+DECLARE
+l1 int;
+l2 int;
+r1 int;
+r2 int;
+i int:=0;
+BEGIN
+  l1:= (VALUE >> 12) & (4096-1);
+  r1:= VALUE & (4096-1);
+  WHILE i < 3 LOOP
+    l2 := r1;
+    r2 := l1 # ((((1366 * r1 + 150889) % 714025) / 714025.0) * (4096-1))::int;
+  l1 := l2;
+  r1 := r2;
+  i := i + 1;
+  END LOOP;
+  RETURN ((l1 << 12) + r1);
+END;
+$$ LANGUAGE plpgsql strict immutable;
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    id smallint NOT NULL,
+    id_name varchar(15) NOT NULL,
+    title varchar(50) NOT NULL,
+	CONSTRAINT pk_user_roles PRIMARY KEY (id)
+);
+
+CREATE SEQUENCE IF NOT EXISTS users_id_seq;
+CREATE TABLE IF NOT EXISTS users (
+    id integer DEFAULT pseudo_encrypt_24(CAST (nextval('users_id_seq') AS integer)),
+    login varchar(50) NOT NULL,
+    hash varchar(150) NOT NULL,
+    role_id smallint NOT NULL,
+    email varchar(100) NULL,
+    g_sync_state varchar(24) NULL,
+    g_sync_token json NULL,
+	CONSTRAINT pk_users PRIMARY KEY (id),
+    CONSTRAINT fk_users_user_roles FOREIGN KEY(role_id) REFERENCES user_roles(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS pk_users_login ON users(login);
+
+CREATE TABLE IF NOT EXISTS sync_providers (
+    id smallint NOT NULL,
+    name varchar(20) NOT NULL,
+    description varchar(50) NOT NULL,
+	CONSTRAINT pk_sync_providers PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS user_sync (
+    uid integer NOT NULL,
+    provider_id smallint NOT NULL,
+    last_sync timestamp NOT NULL,
+	CONSTRAINT pk_user_sync PRIMARY KEY (uid,provider_id),
+    CONSTRAINT fk_user_sync_users FOREIGN KEY(uid) REFERENCES users(id),
+    CONSTRAINT fk_user_sync_sync_providers FOREIGN KEY(provider_id) REFERENCES sync_providers(id)
+);
+
+CREATE SEQUENCE IF NOT EXISTS portfolios_id_seq;
+CREATE TABLE IF NOT EXISTS portfolios (
+    id integer DEFAULT pseudo_encrypt_24(CAST (nextval('portfolios_id_seq') AS integer)),
+    uid integer NOT NULL,
+    name varchar(50) NOT NULL,
+    title varchar(150) NULL,
+	CONSTRAINT pk_portfolios PRIMARY KEY (id),
+    CONSTRAINT fk_portfolios_users FOREIGN KEY(uid) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS currencies (
+    code char(3) NOT NULL,
+    title varchar(150) NULL,
+	CONSTRAINT pk_currencies PRIMARY KEY (code)
+);
+
+CREATE TABLE IF NOT EXISTS securities_types (
+	id smallint NOT NULL,
+	id_name varchar(15) NOT NULL,
+	title varchar(50) NOT NULL,
+	CONSTRAINT pk_securities_types PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS exchange (
+    id smallint NOT NULL,
+    code varchar(10) NOT NULL,
+    title varchar(150) NULL,
+	CONSTRAINT pk_exchange PRIMARY KEY (id)
+);
+
+CREATE SEQUENCE IF NOT EXISTS securities_id_seq;
+CREATE TABLE IF NOT EXISTS securities (
+	id integer DEFAULT pseudo_encrypt_24(CAST (nextval('securities_id_seq') AS integer)),
+	isin varchar(12) NOT NULL,
+	ticker varchar(12) NOT NULL,
+	figi varchar(12) NOT NULL,
+	currency char(3) NOT NULL,
+	exchange_id smallint NOT NULL,
+	asset_type smallint NOT NULL,
+	title varchar(100) NOT NULL,
+	price_upd_time date NULL,
+	CONSTRAINT pk_securities_id PRIMARY KEY (id),
+    CONSTRAINT fk_securities_currency FOREIGN KEY(currency) REFERENCES currencies(code),
+    CONSTRAINT fk_securities_securities_types FOREIGN KEY(asset_type) REFERENCES securities_types(id),
+    CONSTRAINT fk_securities_exchange FOREIGN KEY(exchange_id) REFERENCES exchange(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS pk_securities_isin ON securities(isin, currency);
+
+CREATE TABLE IF NOT EXISTS operation_types (
+    id smallint NOT NULL,
+    name varchar(30) NOT NULL,
+    title varchar(150) NOT NULL,
+	CONSTRAINT pk_operation_types PRIMARY KEY (id)
+);
+
+CREATE SEQUENCE IF NOT EXISTS operations_id_seq;
+CREATE TABLE IF NOT EXISTS operations (
+    id integer DEFAULT pseudo_encrypt_24(CAST (nextval('operations_id_seq') AS integer)),
+    pid integer NOT NULL,
+    sid integer NOT NULL,
+	time timestamp NOT NULL,
+    op_id smallint NOT NULL,
+	vol integer NOT NULL,
+	price numeric(20,6) NOT NULL,
+	CONSTRAINT pk_operations PRIMARY KEY (id),
+    CONSTRAINT fk_operations_portfolios FOREIGN KEY(pid) REFERENCES portfolios(id),
+    CONSTRAINT fk_operations_operation_types FOREIGN KEY(op_id) REFERENCES operation_types(id),
+    CONSTRAINT fk_operations_securities FOREIGN KEY(sid) REFERENCES securities(id)
+);
+CREATE INDEX IF NOT EXISTS ix_operations ON operations(pid, sid, time);
+
+CREATE TABLE IF NOT EXISTS prices (
+    sid integer NOT NULL,
+	date date NOT NULL,
+	vol integer NOT NULL,
+	price numeric(20,6) NOT NULL,
+	CONSTRAINT pk_prices PRIMARY KEY (sid, date),
+    CONSTRAINT fk_prices_securities FOREIGN KEY(sid) REFERENCES securities(id)
+);
+
+CREATE TABLE IF NOT EXISTS settings (
+	settings jsonb NOT NULL
+);
+
+INSERT INTO currencies VALUES
+    ('EUR','Евро'),
+    ('USD','Доллар США'),
+    ('RUB','Российский рубль')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO operation_types VALUES
+    (1,'buy','Покупка'),
+    (2,'sell','Продажа'),
+    (3,'brokerageFee','Комиссия брокера'),
+    (4,'exchangeFee','Комиссия биржи'),
+    (5,'payIn','Ввод средств'),
+    (6,'payOut','Вывод средств'),
+    (7,'coupon','Выплата купона'),
+    (8,'accruedInterestBuy','НКД при покупке'),
+    (9,'accruedInterestSell','НКД при продаже'),
+    (10,'buyback','Выкуп ценной бумаги')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO securities_types VALUES
+    (10,'Stock','Акции'),
+    (20,'Bond','Облигации'),
+    (31,'EtfStock','ETF на акции'),
+    (32,'EtfBond','ETF на облигации'),
+    (34,'EtfMixed','Смешанный ETF'),
+    (35,'EtfGold','ETF на золото'),
+    (36,'EtfCurrency','ETF на аналог кэша'),
+    (60,'Currency','Кэш')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_roles VALUES
+    (1,'admin','Администратор'),
+    (2,'user','Пользователь')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO sync_providers VALUES
+    (1,'sber','Сбербанк'),
+    (2,'tcs','Тинькофф'),
+    (3,'vtb','ВТБ')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO exchange VALUES
+    (1,'MOEX','Московская биржа'),
+    (2,'SPBEX','Санкт-Петербургская биржа')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO settings (settings)
+SELECT jsonb_build_object('ver', 1)
+WHERE NOT EXISTS (SELECT 1 FROM settings);`,
+		Down: `
+DROP TABLE IF EXISTS operations CASCADE;
+DROP TABLE IF EXISTS operation_types CASCADE;
+DROP TABLE IF EXISTS portfolios CASCADE;
+DROP TABLE IF EXISTS prices CASCADE;
+DROP TABLE IF EXISTS securities CASCADE;
+DROP TABLE IF EXISTS securities_types CASCADE;
+DROP TABLE IF EXISTS user_sync CASCADE;
+DROP TABLE IF EXISTS sync_providers CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
+DROP TABLE IF EXISTS currencies CASCADE;
+DROP TABLE IF EXISTS user_roles CASCADE;
+DROP TABLE IF EXISTS settings CASCADE;
+DROP TABLE IF EXISTS exchange CASCADE;
+DROP FUNCTION IF EXISTS pseudo_encrypt_24 CASCADE;
+DROP SEQUENCE IF EXISTS operations_id_seq CASCADE;
+DROP SEQUENCE IF EXISTS portfolios_id_seq CASCADE;
+DROP SEQUENCE IF EXISTS users_id_seq CASCADE;
+DROP SEQUENCE IF EXISTS securities_id_seq CASCADE;`,
+	},
+	{
+		Version: 2,
+		Name:    "currency rates",
+		Up: `
+CREATE TABLE IF NOT EXISTS rates (
+	currency char(3) NOT NULL,
+	date date NOT NULL,
+	rate numeric(20,6) NOT NULL,
+	CONSTRAINT pk_rates PRIMARY KEY (currency, date),
+	CONSTRAINT fk_rates_currency FOREIGN KEY(currency) REFERENCES currencies(code)
+);`,
+		Down: `DROP TABLE IF EXISTS rates;`,
+	},
+	{
+		Version: 3,
+		Name:    "currency rates update time",
+		Up:      `ALTER TABLE currencies ADD COLUMN IF NOT EXISTS rate_upd_time date NULL;`,
+		Down:    `ALTER TABLE currencies DROP COLUMN IF EXISTS rate_upd_time;`,
+	},
+	{
+		Version: 4,
+		Name:    "sync jobs",
+		Up: `
+CREATE TABLE IF NOT EXISTS jobs (
+	id serial NOT NULL,
+	type varchar(20) NOT NULL,
+	login varchar(50) NULL,
+	state varchar(20) NOT NULL,
+	started timestamp NOT NULL,
+	finished timestamp NULL,
+	total integer NOT NULL,
+	processed integer NOT NULL,
+	error text NULL,
+	errors jsonb NULL,
+	CONSTRAINT pk_jobs PRIMARY KEY (id)
+);`,
+		Down: `DROP TABLE IF EXISTS jobs;`,
+	},
+	{
+		Version: 5,
+		Name:    "dividend and tax operation types",
+		Up: `
+INSERT INTO operation_types VALUES
+    (11,'dividend','Выплата дивидендов'),
+    (12,'tax','Налог')
+ON CONFLICT DO NOTHING;`,
+		Down: `DELETE FROM operation_types WHERE id IN (11,12);`,
+	},
+	{
+		Version: 6,
+		Name:    "operation fingerprints",
+		// existing operations get data based fingerprints, see models.Operation.Fingerprint.
+		// Duplicates imported before keep empty fingerprint
+		Up: `
+ALTER TABLE operations ADD COLUMN IF NOT EXISTS fingerprint varchar(100) NULL;
+
+UPDATE operations o SET fingerprint = x.fingerprint
+FROM (
+    SELECT y.id, y.fingerprint, row_number() OVER (PARTITION BY y.pid, y.fingerprint ORDER BY y.id) AS n
+    FROM (
+        SELECT o.id, o.pid, md5(concat_ws('|', to_char(o.time, 'YYYY-MM-DD"T"HH24:MI:SS'), s.isin, t.name, o.vol::text, o.price::text)) AS fingerprint
+        FROM operations o
+        INNER JOIN securities s ON s.id = o.sid
+        INNER JOIN operation_types t ON t.id = o.op_id
+        WHERE o.fingerprint IS NULL
+    ) y
+) x
+WHERE o.id = x.id AND x.n = 1
+    AND NOT EXISTS (SELECT 1 FROM operations e WHERE e.pid = o.pid AND e.fingerprint = x.fingerprint);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_operations_fingerprint ON operations(pid, fingerprint);`,
+		Down: `
+DROP INDEX IF EXISTS ux_operations_fingerprint;
+ALTER TABLE operations DROP COLUMN IF EXISTS fingerprint;`,
+	},
+	{
+		Version: 7,
+		Name:    "withholding tax operation type",
+		Up: `
+INSERT INTO operation_types VALUES
+    (13,'withholdingTax','Налог, удержанный с дохода')
+ON CONFLICT DO NOTHING;`,
+		Down: `DELETE FROM operation_types WHERE id = 13;`,
+	},
+	{
+		Version: 8,
+		Name:    "security events",
+		Up: `
+CREATE TABLE IF NOT EXISTS security_events (
+	isin varchar(12) NOT NULL,
+	type varchar(20) NOT NULL,
+	date date NOT NULL,
+	value numeric(20,6) NOT NULL,
+	currency char(3) NOT NULL,
+	CONSTRAINT pk_security_events PRIMARY KEY (isin, type, date)
+);`,
+		Down: `DROP TABLE IF EXISTS security_events;`,
+	},
+	{
+		Version: 9,
+		Name:    "corporate actions",
+		Up: `
+CREATE TABLE IF NOT EXISTS corporate_actions (
+	isin varchar(12) NOT NULL,
+	type varchar(20) NOT NULL,
+	date date NOT NULL,
+	ratio_from bigint NOT NULL,
+	ratio_to bigint NOT NULL,
+	new_isin varchar(12) NULL,
+	new_ticker varchar(12) NULL,
+	cost_ratio numeric(10,6) NOT NULL,
+	CONSTRAINT pk_corporate_actions PRIMARY KEY (isin, type, date)
+);`,
+		Down: `DROP TABLE IF EXISTS corporate_actions;`,
+	},
+	{
+		Version: 10,
+		Name:    "amortization operation type",
+		Up: `
+INSERT INTO operation_types VALUES
+    (14,'amortization','Частичное погашение номинала')
+ON CONFLICT DO NOTHING;`,
+		Down: `DELETE FROM operation_types WHERE id = 14;`,
+	},
+	{
+		Version: 11,
+		Name:    "ordinals of identical operation fingerprints",
+		// identical operations left without fingerprint by version 6 get their
+		// ordinal appended, see models.Fingerprints
+		Up: `
+UPDATE operations o SET fingerprint = x.fingerprint || '#' || (x.n - 1)::text
+FROM (
+    SELECT y.id, y.fingerprint, row_number() OVER (PARTITION BY y.pid, y.fingerprint ORDER BY y.id) AS n
+    FROM (
+        SELECT o.id, o.pid, o.fingerprint AS current,
+            md5(concat_ws('|', to_char(o.time, 'YYYY-MM-DD"T"HH24:MI:SS'), s.isin, t.name, o.vol::text, o.price::text)) AS fingerprint
+        FROM operations o
+        INNER JOIN securities s ON s.id = o.sid
+        INNER JOIN operation_types t ON t.id = o.op_id
+    ) y
+    WHERE y.current IS NULL OR y.current = y.fingerprint
+) x
+WHERE o.id = x.id AND o.fingerprint IS NULL AND x.n > 1
+    AND NOT EXISTS (SELECT 1 FROM operations e WHERE e.pid = o.pid AND e.fingerprint = x.fingerprint || '#' || (x.n - 1)::text);`,
+		Down: `UPDATE operations SET fingerprint = NULL WHERE fingerprint LIKE '%#%';`,
+	},
+}
+
+// LatestSchemaVersion returns version of the most recent migration
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrate brings database schema to the latest version
+func (db Db) Migrate() error {
+	return db.MigrateTo(LatestSchemaVersion())
+}
+
+// MigrateTo applies up or down migrations until database schema reaches given version
+func (db Db) MigrateTo(version int) error {
+	if version < 0 || version > LatestSchemaVersion() {
+		return fmt.Errorf("unknown schema version %d. Expected value from 0 to %d", version, LatestSchemaVersion())
+	}
+
+	conn, err := db.connection.Acquire(db.context)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(db.context, "select pg_advisory_lock($1);", migrationLockKey)
+	if err != nil {
+		return err
+	}
+	defer conn.Exec(db.context, "select pg_advisory_unlock($1);", migrationLockKey)
+
+	query := `create table if not exists schema_migrations (
+		version integer NOT NULL,
+		name varchar(100) NOT NULL,
+		applied_at timestamp NOT NULL,
+		CONSTRAINT pk_schema_migrations PRIMARY KEY (version)
+	);`
+	_, err = conn.Exec(db.context, query)
+	if err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(db, conn.Conn())
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current || m.Version > version {
+			continue
+		}
+		err = applyMigration(db, conn.Conn(), m.Up, "insert into schema_migrations (version,name,applied_at) values ($1,$2,$3);", m.Version, m.Name, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("could not apply migration %d (%s): %v", m.Version, m.Name, err)
+		}
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Applied migration", m.Version, m.Name)
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > current || m.Version <= version {
+			continue
+		}
+		err = applyMigration(db, conn.Conn(), m.Down, "delete from schema_migrations where version = $1;", m.Version)
+		if err != nil {
+			return fmt.Errorf("could not revert migration %d (%s): %v", m.Version, m.Name, err)
+		}
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Reverted migration", m.Version, m.Name)
+	}
+	return nil
+}
+
+// SchemaVersion returns current database schema version. Zero means no migrations applied
+func (db Db) SchemaVersion() (int, error) {
+	var exists bool
+	query := "select to_regclass('schema_migrations') is not null;"
+	err := db.connection.QueryRow(db.context, query).Scan(&exists)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, nil
+	}
+	var version int
+	query = "select coalesce(max(version), 0) from schema_migrations;"
+	err = db.connection.QueryRow(db.context, query).Scan(&version)
+	return version, err
+}
+
+func schemaVersion(db Db, conn *pgx.Conn) (int, error) {
+	var version int
+	query := "select coalesce(max(version), 0) from schema_migrations;"
+	err := conn.QueryRow(db.context, query).Scan(&version)
+	return version, err
+}
+
+func applyMigration(db Db, conn *pgx.Conn, script string, bookkeeping string, args ...interface{}) error {
+	if script == "" {
+		return errors.New("migration script is empty")
+	}
+	tx, err := conn.Begin(db.context)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(db.context, script)
+	if err != nil {
+		tx.Rollback(db.context)
+		return err
+	}
+	_, err = tx.Exec(db.context, bookkeeping, args...)
+	if err != nil {
+		tx.Rollback(db.context)
+		return err
+	}
+	return tx.Commit(db.context)
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import "testing"
+
+func TestMigrationsOrder(t *testing.T) {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("Fail! Expected migration version %v, got %v", i+1, m.Version)
+		}
+		if m.Up == "" || m.Down == "" {
+			t.Errorf("Fail! Migration %v must have both up and down scripts", m.Version)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	err := db.Migrate()
+	if err != nil {
+		t.Errorf("Fail! Error during migration: %v", err)
+	}
+
+	// second run must be no-op
+	err = db.Migrate()
+	if err != nil {
+		t.Errorf("Fail! Error during repeated migration: %v", err)
+	}
+
+	ver, err := db.SchemaVersion()
+	if err != nil {
+		t.Errorf("Fail! Error during fetch schema version: %v", err)
+	}
+	if ver == LatestSchemaVersion() {
+		t.Logf("Success! Expected %v, got %v", LatestSchemaVersion(), ver)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", LatestSchemaVersion(), ver)
+	}
+
+	err = db.MigrateTo(LatestSchemaVersion() + 1)
+	if err != nil {
+		t.Logf("Success! Expected error, got %v", err)
+	} else {
+		t.Errorf("Fail! Expected error for unknown version, got nil")
+	}
+}
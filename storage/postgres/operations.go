@@ -1,7 +1,6 @@
 package postgres
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -10,38 +9,57 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
 )
 
-// AddOperation saves single opertion into a storage
+// AddOperation saves single opertion into a storage.
+// Operation with the same fingerprint must not exist in portfolio
 func (db Db) AddOperation(portfolioID string, op models.Operation) (string, error) {
 	pid, err := strconv.ParseInt(portfolioID, 10, 32)
 	if err != nil {
-		return "", errors.New("Invalid portfolio Id format. Expected positive number")
+		return "", errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
 	}
 
 	var id int
 	opIds := getOperationTypesByName()
-	query := "insert into operations (pid,sid,time,op_id,vol,price) select $1,id,$3,$4,$5,$6 from securities where isin = $2 returning id;"
-	err = db.connection.QueryRow(db.context, query, pid, op.ISIN, op.DateTime.UTC(), opIds[string(op.OperationType)], op.Volume, op.Price).Scan(&id)
+	query := `insert into operations (pid,sid,time,op_id,vol,price,fingerprint) select $1,id,$3,$4,$5,$6,$7 from securities where isin = $2
+	on conflict (pid,fingerprint) do nothing returning id;`
+	err = db.connection.QueryRow(db.context, query, pid, op.ISIN, op.DateTime.UTC(), opIds[string(op.OperationType)], op.Volume, op.Price, op.Fingerprint()).Scan(&id)
+	if err == pgx.ErrNoRows {
+		var hasSecurity bool
+		query = "select exists(select 1 from securities where isin = $1);"
+		e := db.connection.QueryRow(db.context, query, op.ISIN).Scan(&hasSecurity)
+		if e != nil {
+			return "", e
+		}
+		if hasSecurity {
+			return "", errs.New(errs.Conflict, "operation already exists")
+		}
+		return "", errs.New(errs.Validation, "could not add operation with unknown ISIN %s", op.ISIN)
+	}
 	if err != nil {
 		pgerr, ok := err.(*pgconn.PgError)
 		if !ok {
 			return "", err
 		}
 		if pgerr.Code == "23503" {
-			return "", errors.New("could not add operation to unknown portfolio")
+			return "", errs.New(errs.NotFound, "could not add operation to unknown portfolio")
 		}
 		return "", err
 	}
 	return strconv.Itoa(id), nil
 }
 
-// AddOperations saves multiple opertions into a storage
+// AddOperations saves multiple opertions into a storage.
+// Operations which fingerprint already exists in portfolio, or repeats
+// within the batch, are skipped. Fingerprints are taken from models.Fingerprints,
+// so identical fills are kept. Returned Ids follow input order,
+// skipped operations get empty Id
 func (db Db) AddOperations(portfolioID string, ops []models.Operation) ([]string, error) {
 
 	pid, err := strconv.ParseInt(portfolioID, 10, 32)
 	if err != nil {
-		return nil, errors.New("Invalid portfolio Id format. Expected positive number")
+		return nil, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
 	}
 
 	isins := map[string]bool{}
@@ -69,30 +87,82 @@ func (db Db) AddOperations(portfolioID string, ops []models.Operation) ([]string
 		}
 		isinIDMap[isin] = id
 	}
+	for _, op := range ops {
+		if _, ok := isinIDMap[op.ISIN]; !ok {
+			return nil, errs.New(errs.Validation, "could not add operation with unknown ISIN %s", op.ISIN)
+		}
+	}
 
-	rows := make([][]interface{}, len(ops))
+	fingerprints := make(map[string]int, len(ops))
+	rows := make([][]interface{}, 0, len(ops))
 	opIds := getOperationTypesByName()
-	for i, op := range ops {
-		rows[i] = []interface{}{pid, isinIDMap[op.ISIN], op.DateTime.UTC(), opIds[string(op.OperationType)], op.Volume, op.Price}
+	for i, fp := range models.Fingerprints(ops) {
+		if _, ok := fingerprints[fp]; ok {
+			continue
+		}
+		fingerprints[fp] = i
+		op := ops[i]
+		rows = append(rows, []interface{}{len(rows), pid, isinIDMap[op.ISIN], op.DateTime.UTC(), opIds[string(op.OperationType)], op.Volume, op.Price, fp})
 	}
 
-	colNames := []string{"pid", "sid", "time", "op_id", "vol", "price"}
-	_, err = db.connection.CopyFrom(db.context, pgx.Identifier{"operations"}, colNames, pgx.CopyFromRows(rows))
-	pgerr, ok := err.(*pgconn.PgError)
-	if !ok {
+	c, err := db.connection.Begin(db.context)
+	if err != nil {
 		return nil, err
 	}
-	if pgerr.Code == "23503" {
-		return nil, errors.New("could not add operation to unknown portfolio")
+	defer c.Rollback(db.context)
+
+	// copy to temporary table first since copy does not support conflicts resolution
+	query = `create temp table tmp_operations (
+		n integer, pid integer, sid integer, time timestamp, op_id smallint,
+		vol integer, price numeric(20,6), fingerprint varchar(100)
+	) on commit drop;`
+	_, err = c.Exec(db.context, query)
+	if err != nil {
+		return nil, err
 	}
-	return nil, err
+	colNames := []string{"n", "pid", "sid", "time", "op_id", "vol", "price", "fingerprint"}
+	_, err = c.CopyFrom(db.context, pgx.Identifier{"tmp_operations"}, colNames, pgx.CopyFromRows(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	query = `insert into operations (pid,sid,time,op_id,vol,price,fingerprint)
+	select pid,sid,time,op_id,vol,price,fingerprint from tmp_operations order by n
+	on conflict (pid,fingerprint) do nothing returning id::varchar(20), fingerprint;`
+	inserted, err := c.Query(db.context, query)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(ops))
+	for inserted.Next() {
+		var id, fp string
+		err = inserted.Scan(&id, &fp)
+		if err != nil {
+			return nil, err
+		}
+		ids[fingerprints[fp]] = id
+	}
+	err = inserted.Err()
+	if err != nil {
+		pgerr, ok := err.(*pgconn.PgError)
+		if ok && pgerr.Code == "23503" {
+			return nil, errs.New(errs.NotFound, "could not add operation to unknown portfolio")
+		}
+		return nil, err
+	}
+
+	err = c.Commit(db.context)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 // GetOperations finds operations depending on input prameters
 func (db Db) GetOperations(portfolioID string, key string, value string, from string, to string) ([]models.Operation, error) {
 	pid, err := strconv.ParseInt(portfolioID, 10, 32)
 	if err != nil {
-		return nil, errors.New("Invalid portfolio Id format. Expected positive number")
+		return nil, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
 	}
 	params := []interface{}{pid}
 	n := 1
@@ -134,15 +204,131 @@ func (db Db) GetOperations(portfolioID string, key string, value string, from st
 	return result, nil
 }
 
+// FindOperations finds portfolio operations matching given query.
+// Returns requested page of operations and total number of matched ones
+func (db Db) FindOperations(portfolioID string, q models.ListQuery) ([]models.Operation, int64, error) {
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return nil, 0, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+	params := []interface{}{pid}
+	from := ` from operations o inner join securities s on s.id = o.sid
+	inner join operation_types t on t.id = o.op_id where pid = $1`
+	where, params, err := filterClause(q.Filters, operationFilterColumns, "operation", params)
+	if err != nil {
+		return nil, 0, err
+	}
+	from += where
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.From); err == nil {
+		params = append(params, dtime)
+		from += fmt.Sprintf(" and time >= $%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.To); err == nil {
+		params = append(params, dtime)
+		from += fmt.Sprintf(" and time <= $%d", len(params))
+	}
+	order, err := orderClause(q.Sort, operationSortColumns, "operation", "o.time, o.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = db.connection.QueryRow(db.context, "select count(*)"+from+";", params...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := "select o.id::varchar(20), s.isin, s.figi, s.ticker, s.currency, o.time, t.name, o.vol, o.price" +
+		from + order + limitClause(q.Limit, q.Offset) + ";"
+	rows, err := db.connection.Query(db.context, query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	result := []models.Operation{}
+	for rows.Next() {
+		op := models.Operation{
+			PortfolioID: portfolioID,
+		}
+		err = rows.Scan(&op.OperationID, &op.ISIN, &op.FIGI, &op.Ticker, &op.Currency, &op.DateTime, &op.OperationType, &op.Volume, &op.Price)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, op)
+	}
+	return result, total, rows.Err()
+}
+
+// GetOperation finds operation by Id
+func (db Db) GetOperation(portfolioID string, operationID string) (models.Operation, error) {
+	result := models.Operation{}
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return result, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+	id, err := strconv.ParseInt(operationID, 10, 32)
+	if err != nil {
+		return result, errs.New(errs.Validation, "Invalid operation Id format. Expected positive number")
+	}
+
+	query := `select s.isin, s.figi, s.ticker, s.currency, o.time, t.name, o.vol, o.price from operations o
+	inner join securities s on s.id = o.sid inner join operation_types t on t.id = o.op_id where o.pid = $1 and o.id = $2;`
+	err = db.connection.QueryRow(db.context, query, pid, id).Scan(&result.ISIN, &result.FIGI, &result.Ticker,
+		&result.Currency, &result.DateTime, &result.OperationType, &result.Volume, &result.Price)
+	if err == pgx.ErrNoRows {
+		return result, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
+	}
+	if err != nil {
+		return result, err
+	}
+	result.PortfolioID = portfolioID
+	result.OperationID = operationID
+	return result, nil
+}
+
+// UpdateOperation replaces operation data. Fingerprint is kept, so that
+// edited operation is still recognized when its report is imported again
+func (db Db) UpdateOperation(portfolioID string, operationID string, op models.Operation) (bool, error) {
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+	id, err := strconv.ParseInt(operationID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid operation Id format. Expected positive number")
+	}
+
+	opIds := getOperationTypesByName()
+	query := `update operations o set sid = s.id, time = $4, op_id = $5, vol = $6, price = $7
+	from securities s where o.pid = $1 and o.id = $2 and s.isin = $3;`
+	r, err := db.connection.Exec(db.context, query, pid, id, op.ISIN, op.DateTime.UTC(), opIds[string(op.OperationType)], op.Volume, op.Price)
+	if err != nil {
+		return false, err
+	}
+	if r.RowsAffected() != 0 {
+		return true, nil
+	}
+	var hasSecurity bool
+	query = "select exists(select 1 from securities where isin = $1);"
+	err = db.connection.QueryRow(db.context, query, op.ISIN).Scan(&hasSecurity)
+	if err != nil {
+		return false, err
+	}
+	if !hasSecurity {
+		return false, errs.New(errs.Validation, "could not update operation with unknown ISIN %s", op.ISIN)
+	}
+	return false, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
+}
+
 // DeleteOperation removes operation by Id
 func (db Db) DeleteOperation(portfolioID string, operationID string) (bool, error) {
 	pid, err := strconv.ParseInt(portfolioID, 10, 32)
 	if err != nil {
-		return false, errors.New("Invalid portfolio Id format. Expected positive number")
+		return false, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
 	}
 	id, err := strconv.ParseInt(operationID, 10, 32)
 	if err != nil {
-		return false, errors.New("Invalid operation Id format. Expected positive number")
+		return false, errs.New(errs.Validation, "Invalid operation Id format. Expected positive number")
 	}
 
 	query := "delete from operations where pid = $1 and id = $2;"
@@ -150,17 +336,17 @@ func (db Db) DeleteOperation(portfolioID string, operationID string) (bool, erro
 	if err != nil {
 		return false, err
 	}
-	if r.RowsAffected() == 1 {
-		return true, nil
+	if r.RowsAffected() == 0 {
+		return false, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
 	}
-	return false, nil
+	return true, nil
 }
 
 // DeleteOperations removes all operations for provided portfolio Id
 func (db Db) DeleteOperations(portfolioID string) (int64, error) {
 	pid, err := strconv.ParseInt(portfolioID, 10, 32)
 	if err != nil {
-		return 0, errors.New("Invalid portfolio Id format. Expected positive number")
+		return 0, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
 	}
 
 	query := "delete from operations where pid = $1;"
@@ -183,5 +369,28 @@ func getOperationTypesByName() map[string]int {
 		"accruedInterestBuy":  8,
 		"accruedInterestSell": 9,
 		"buyback":             10,
+		"dividend":            11,
+		"tax":                 12,
+		"withholdingTax":      13,
+		"amortization":        14,
 	}
 }
+
+var operationFilterColumns = map[string]string{
+	"isin":     "s.isin",
+	"ticker":   "s.ticker",
+	"figi":     "s.figi",
+	"currency": "s.currency",
+	"type":     "t.name",
+}
+
+var operationSortColumns = map[string]string{
+	"date":     "o.time",
+	"price":    "o.price",
+	"vol":      "o.vol",
+	"type":     "t.name",
+	"ticker":   "s.ticker",
+	"isin":     "s.isin",
+	"figi":     "s.figi",
+	"currency": "s.currency",
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/kaseat/pManager/models"
 	"github.com/kaseat/pManager/models/currency"
 	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/storage/errs"
 )
 
 func TestMultipleOperations(t *testing.T) {
@@ -190,26 +191,20 @@ func TestMultipleOperations(t *testing.T) {
 		t.Logf("Success! Expected '0' got '%d'", resInt)
 	}
 
-	// throws no error when provided pid is not found
+	// throws not found error when provided pid is not found
 	resBool, err := db.DeleteOperation(unknownID, res[0].OperationID)
-	if err != nil {
-		t.Errorf("Fail! Unknown error: '%s'", err)
-	}
-	if resBool != false {
-		t.Errorf("Fail! Expected 'false' got '%v'", resBool)
+	if errs.KindOf(err) != errs.NotFound || resBool != false {
+		t.Errorf("Fail! Expected not found error got '%v', '%v'", resBool, err)
 	} else {
-		t.Logf("Success! Expected 'false' got '%v'", resBool)
+		t.Logf("Success! Got expected error: %s", err)
 	}
 
-	// throws no error when provided pid is not found
+	// throws not found error when provided oid is not found
 	resBool, err = db.DeleteOperation(pid, unknownID)
-	if err != nil {
-		t.Errorf("Fail! Unknown error: '%s'", err)
-	}
-	if resBool != false {
-		t.Errorf("Fail! Expected 'false' got '%v'", resBool)
+	if errs.KindOf(err) != errs.NotFound || resBool != false {
+		t.Errorf("Fail! Expected not found error got '%v', '%v'", resBool, err)
 	} else {
-		t.Logf("Success! Expected 'false' got '%v'", resBool)
+		t.Logf("Success! Got expected error: %s", err)
 	}
 
 	remOp := res[0].OperationID
@@ -302,6 +297,21 @@ func TestSaveSingleOperation(t *testing.T) {
 		t.Errorf("Fail! Expected '%v' got nothing", ops[0].ISIN)
 	}
 
+	unknown := ops[0]
+	unknown.ISIN = "XX0000000000"
+	_, err = db.AddOperation(pid, unknown)
+	if errs.KindOf(err) == errs.Validation {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected validation error for unknown ISIN, got %v", err)
+	}
+	_, err = db.AddOperations(pid, []models.Operation{ops[1], unknown})
+	if errs.KindOf(err) == errs.Validation {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected validation error for unknown ISIN, got %v", err)
+	}
+
 	db.DeleteUser("logins")
 	dropTestSecurities()
 }
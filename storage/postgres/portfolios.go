@@ -1,18 +1,19 @@
 package postgres
 
 import (
-	"errors"
 	"strconv"
 
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
 )
 
 // AddPortfolio adds new potrfolio
 func (db Db) AddPortfolio(userID string, p models.Portfolio) (string, error) {
 	uid, err := strconv.ParseInt(userID, 10, 32)
 	if err != nil {
-		return "", errors.New("Invalid user Id format. Expected positive number")
+		return "", errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
 	}
 	var id int
 	query := "insert into portfolios (uid,name,title) values ($1,$2,$3) returning id;"
@@ -23,7 +24,7 @@ func (db Db) AddPortfolio(userID string, p models.Portfolio) (string, error) {
 			return "", err
 		}
 		if pgerr.Code == "23503" {
-			return "", errors.New("could not add portfolio to unknown user")
+			return "", errs.New(errs.NotFound, "could not add portfolio to unknown user")
 		}
 		return "", err
 	}
@@ -35,11 +36,11 @@ func (db Db) GetPortfolio(userID string, portfolioID string) (models.Portfolio,
 	result := models.Portfolio{}
 	uid, err := strconv.ParseInt(userID, 10, 32)
 	if err != nil {
-		return result, errors.New("Invalid user Id format. Expected positive number")
+		return result, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
 	}
 	pid, err := strconv.ParseInt(portfolioID, 10, 32)
 	if err != nil {
-		return result, errors.New("Invalid portfolio Id format. Expected positive number")
+		return result, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
 	}
 
 	var name string
@@ -47,6 +48,9 @@ func (db Db) GetPortfolio(userID string, portfolioID string) (models.Portfolio,
 
 	query := "select name,title from portfolios where uid = $1 and id = $2;"
 	err = db.connection.QueryRow(db.context, query, uid, pid).Scan(&name, &title)
+	if err == pgx.ErrNoRows {
+		return result, errs.New(errs.NotFound, "No portfolio found with %s Id", portfolioID)
+	}
 	if err != nil {
 		return result, err
 	}
@@ -62,7 +66,7 @@ func (db Db) GetPortfolios(userID string) ([]models.Portfolio, error) {
 	result := []models.Portfolio{}
 	uid, err := strconv.ParseInt(userID, 10, 32)
 	if err != nil {
-		return nil, errors.New("Invalid user Id format. Expected positive number")
+		return nil, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
 	}
 	query := "select id,name,title from portfolios where uid = $1;"
 	rows, err := db.connection.Query(db.context, query, uid)
@@ -92,11 +96,11 @@ func (db Db) GetPortfolios(userID string) ([]models.Portfolio, error) {
 func (db Db) UpdatePortfolio(userID string, portfolioID string, p models.Portfolio) (bool, error) {
 	uid, err := strconv.ParseInt(userID, 10, 32)
 	if err != nil {
-		return false, errors.New("Invalid user Id format. Expected positive number")
+		return false, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
 	}
 	pid, err := strconv.ParseInt(portfolioID, 10, 32)
 	if err != nil {
-		return false, errors.New("Invalid portfolio Id format. Expected positive number")
+		return false, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
 	}
 
 	query := "update portfolios set name = $1, title = $2 where uid = $3 and id = $4;"
@@ -115,11 +119,11 @@ func (db Db) UpdatePortfolio(userID string, portfolioID string, p models.Portfol
 func (db Db) DeletePortfolio(userID string, portfolioID string) (bool, error) {
 	uid, err := strconv.ParseInt(userID, 10, 32)
 	if err != nil {
-		return false, errors.New("Invalid user Id format. Expected positive number")
+		return false, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
 	}
 	pid, err := strconv.ParseInt(portfolioID, 10, 32)
 	if err != nil {
-		return false, errors.New("Invalid portfolio Id format. Expected positive number")
+		return false, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
 	}
 
 	query := "delete from portfolios where uid = $1 and id = $2;"
@@ -138,7 +142,7 @@ func (db Db) DeletePortfolio(userID string, portfolioID string) (bool, error) {
 func (db Db) DeletePortfolios(userID string) (int64, error) {
 	uid, err := strconv.ParseInt(userID, 10, 32)
 	if err != nil {
-		return 0, errors.New("Invalid user Id format. Expected positive number")
+		return 0, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
 	}
 	c, err := db.connection.Begin(db.context)
 	query := "delete from operations o using portfolios p where o.pid = p.id and p.uid = $1;"
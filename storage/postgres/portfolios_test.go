@@ -1,9 +1,11 @@
 package postgres
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
 )
 
 func TestPortfolioDeletion(t *testing.T) {
@@ -322,27 +324,20 @@ func TestPortfolios(t *testing.T) {
 
 	// feed GetPortfolio with unknown user Id
 	_, err = db.GetPortfolio(unknownID, pid)
-	expectedErrMsg = "no rows in result set"
-	if err == nil {
-		t.Errorf("Fail! Expected '%s' error", err)
+	expectedErrMsg = fmt.Sprintf("No portfolio found with %s Id", pid)
+	if err == nil || err.Error() != expectedErrMsg || errs.KindOf(err) != errs.NotFound {
+		t.Errorf("Fail! Expected '%s' got '%v'", expectedErrMsg, err)
 	} else {
-		if err.Error() != expectedErrMsg {
-			t.Errorf("Fail! Expected '%s' got '%s'", expectedErrMsg, err)
-		} else {
-			t.Logf("Success! Expected '%s' got '%s'", expectedErrMsg, err)
-		}
+		t.Logf("Success! Expected '%s' got '%s'", expectedErrMsg, err)
 	}
 
 	// feed GetPortfolio with unknown portfolio Id
 	_, err = db.GetPortfolio(uid, unknownID)
-	if err == nil {
-		t.Errorf("Fail! Expected '%s' error", err)
+	expectedErrMsg = fmt.Sprintf("No portfolio found with %s Id", unknownID)
+	if err == nil || err.Error() != expectedErrMsg || errs.KindOf(err) != errs.NotFound {
+		t.Errorf("Fail! Expected '%s' got '%v'", expectedErrMsg, err)
 	} else {
-		if err.Error() != expectedErrMsg {
-			t.Errorf("Fail! Expected '%s' got '%s'", expectedErrMsg, err)
-		} else {
-			t.Logf("Success! Expected '%s' got '%s'", expectedErrMsg, err)
-		}
+		t.Logf("Success! Expected '%s' got '%s'", expectedErrMsg, err)
 	}
 
 	// cleanup
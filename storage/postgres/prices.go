@@ -7,6 +7,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
 )
 
 // AddPrices saves prices series into a storage
@@ -23,7 +24,7 @@ func (db Db) AddPrices(prices []models.Price) error {
 		return err
 	}
 	if pgerr.Code == "23503" {
-		return fmt.Errorf("could not add prices: error in column %s", pgerr.ColumnName)
+		return errs.New(errs.Validation, "could not add prices: error in column %s", pgerr.ColumnName)
 	}
 	if err != nil {
 		return err
@@ -82,6 +83,52 @@ func (db Db) GetPrices(key, value, from, to string) ([]models.Price, error) {
 	return result, nil
 }
 
+// FindPrices finds prices matching given query. Prices are filtered
+// by their instrument fields. Returns requested page of prices and total number of matched ones
+func (db Db) FindPrices(q models.ListQuery) ([]models.Price, int64, error) {
+	from := " from prices p inner join securities s on s.id = p.sid where 1 = 1"
+	where, params, err := filterClause(q.Filters, priceFilterColumns, "price", []interface{}{})
+	if err != nil {
+		return nil, 0, err
+	}
+	from += where
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.From); err == nil {
+		params = append(params, dtime)
+		from += fmt.Sprintf(" and date >= $%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.To); err == nil {
+		params = append(params, dtime)
+		from += fmt.Sprintf(" and date <= $%d", len(params))
+	}
+	order, err := orderClause(q.Sort, priceSortColumns, "price", "p.date, p.sid")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = db.connection.QueryRow(db.context, "select count(*)"+from+";", params...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := "select p.sid, s.isin, p.date, p.vol, p.price" + from + order + limitClause(q.Limit, q.Offset) + ";"
+	rows, err := db.connection.Query(db.context, query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	result := []models.Price{}
+	for rows.Next() {
+		pr := models.Price{}
+		err = rows.Scan(&pr.SecID, &pr.ISIN, &pr.Date, &pr.Volume, &pr.Price)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, pr)
+	}
+	return result, total, rows.Err()
+}
+
 // GetPricesByIsin finds prices for given ISIN and dates
 func (db Db) GetPricesByIsin(isin, from, to string) ([]models.Price, error) {
 	return db.GetPrices("isin", isin, from, to)
@@ -108,3 +155,16 @@ func (db Db) DeletePrices(key string, value string) (int64, error) {
 func (db Db) DeleteAllPrices() (int64, error) {
 	return db.DeletePrices("", "")
 }
+
+var priceFilterColumns = map[string]string{
+	"isin":   "s.isin",
+	"ticker": "s.ticker",
+	"figi":   "s.figi",
+}
+
+var priceSortColumns = map[string]string{
+	"date":  "p.date",
+	"isin":  "s.isin",
+	"price": "p.price",
+	"vol":   "p.vol",
+}
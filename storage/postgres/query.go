@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// filterClause appends conditions for given filters to the query. Values of a filter
+// are combined with OR, different filters with AND. Keys are mapped to columns
+// by given whitelist, so no raw user input gets into the query
+func filterClause(filters map[string][]string, columns map[string]string, entity string, params []interface{}) (string, []interface{}, error) {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	clause := ""
+	for _, key := range keys {
+		col, ok := columns[key]
+		if !ok {
+			return "", nil, errs.New(errs.Validation, "unknown %s filter: %s", entity, key)
+		}
+		values := filters[key]
+		if len(values) == 0 {
+			continue
+		}
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			params = append(params, v)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+		clause += fmt.Sprintf(" and %s in (%s)", col, strings.Join(placeholders, ","))
+	}
+	return clause, params, nil
+}
+
+// orderClause makes order by clause for given sort keys. Descending keys are
+// prefixed with minus. Tie breaker columns are appended to keep paging stable
+func orderClause(keys []string, columns map[string]string, entity string, tieBreaker string) (string, error) {
+	order := []string{}
+	for _, key := range keys {
+		col, ok := columns[strings.TrimPrefix(key, "-")]
+		if !ok {
+			return "", errs.New(errs.Validation, "unknown %s sort key: %s", entity, strings.TrimPrefix(key, "-"))
+		}
+		if strings.HasPrefix(key, "-") {
+			col += " desc"
+		}
+		order = append(order, col)
+	}
+	order = append(order, tieBreaker)
+	return " order by " + strings.Join(order, ", "), nil
+}
+
+func limitClause(limit, offset int) string {
+	clause := ""
+	if limit > 0 {
+		clause += fmt.Sprintf(" limit %d", limit)
+	}
+	if offset > 0 {
+		clause += fmt.Sprintf(" offset %d", offset)
+	}
+	return clause
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddRates saves currency rates into a storage. Existing rates are overwritten
+func (db Db) AddRates(rates []models.Rate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	currs := make([]string, len(rates))
+	dates := make([]time.Time, len(rates))
+	values := make([]float64, len(rates))
+	for i, r := range rates {
+		currs[i] = string(r.Currency)
+		dates[i] = r.Date
+		values[i] = r.Rate
+	}
+
+	query := `insert into rates (currency,date,rate)
+	select * from unnest($1::char(3)[], $2::date[], $3::numeric[])
+	on conflict (currency,date) do update set rate = excluded.rate;`
+	_, err := db.connection.Exec(db.context, query, currs, dates, values)
+	if pgerr, ok := err.(*pgconn.PgError); ok && pgerr.Code == "23503" {
+		return errs.New(errs.Validation, "could not add rates: unknown currency")
+	}
+	return err
+}
+
+// GetRates finds rates of given currency between dates. Empty currency means all currencies
+func (db Db) GetRates(curr currency.Type, from, to string) ([]models.Rate, error) {
+	params := []interface{}{}
+	query := "select currency,date,rate from rates where 1 = 1"
+	if curr != "" {
+		params = append(params, string(curr))
+		query += fmt.Sprintf(" and currency = $%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", from); err == nil {
+		params = append(params, dtime)
+		query += fmt.Sprintf(" and date >= $%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", to); err == nil {
+		params = append(params, dtime)
+		query += fmt.Sprintf(" and date <= $%d", len(params))
+	}
+	query += " order by date, currency;"
+
+	rows, err := db.connection.Query(db.context, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []models.Rate{}
+	for rows.Next() {
+		r := models.Rate{}
+		err = rows.Scan(&r.Currency, &r.Date, &r.Rate)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// DeleteRates removes rates of given currency. Empty currency means all currencies
+func (db Db) DeleteRates(curr currency.Type) (int64, error) {
+	var r pgconn.CommandTag
+	var err error
+	if curr != "" {
+		r, err = db.connection.Exec(db.context, "delete from rates where currency = $1;", string(curr))
+	} else {
+		r, err = db.connection.Exec(db.context, "delete from rates;")
+	}
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected(), nil
+}
+
+// SetRateUptdTime sets time currency rates were updated
+func (db Db) SetRateUptdTime(curr currency.Type, updTime time.Time) (bool, error) {
+	query := "update currencies set rate_upd_time = $1 where code = $2;"
+	r, err := db.connection.Exec(db.context, query, updTime, string(curr))
+	if err != nil {
+		return false, err
+	}
+	if r.RowsAffected() == 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetRateUptdTime gets time currency rates were updated. Zero time means never
+func (db Db) GetRateUptdTime(curr currency.Type) (time.Time, error) {
+	var updTime *time.Time
+	query := "select rate_upd_time from currencies where code = $1;"
+	err := db.connection.QueryRow(db.context, query, string(curr)).Scan(&updTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if updTime == nil {
+		return time.Time{}, nil
+	}
+	return *updTime, nil
+}
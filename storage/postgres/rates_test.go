@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+)
+
+func TestRates(t *testing.T) {
+	d1 := time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(2020, 1, 16, 0, 0, 0, 0, time.UTC)
+	err := db.AddRates([]models.Rate{
+		{Currency: currency.USD, Date: d1, Rate: 61.414},
+		{Currency: currency.EUR, Date: d1, Rate: 68.363},
+		{Currency: currency.USD, Date: d2, Rate: 1},
+	})
+	if err != nil {
+		t.Errorf("Fail! Error during add rates: %v", err)
+	}
+	// existing rate must be overwritten
+	err = db.AddRates([]models.Rate{{Currency: currency.USD, Date: d2, Rate: 61.4328}})
+	if err != nil {
+		t.Errorf("Fail! Error during overwrite rates: %v", err)
+	}
+
+	res, _ := db.GetRates(currency.USD, "", "")
+	if len(res) == 2 && res[0].Rate == 61.414 && res[1].Rate == 61.4328 && res[1].Date.Equal(d2) {
+		t.Logf("Success! Got expected rates %v", res)
+	} else {
+		t.Errorf("Fail! Unexpected rates %v", res)
+	}
+	res, _ = db.GetRates("", "2020-01-15T00:00:00Z", "2020-01-15T00:00:00Z")
+	if len(res) == 2 {
+		t.Logf("Success! Expected %v, got %v", 2, len(res))
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", 2, len(res))
+	}
+
+	num, _ := db.DeleteRates("")
+	if num == 3 {
+		t.Logf("Success! Expected %v, got %v", 3, num)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", 3, num)
+	}
+}
+
+func TestRateUptdTime(t *testing.T) {
+	d := time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC)
+	ok, err := db.SetRateUptdTime(currency.USD, d)
+	if !ok || err != nil {
+		t.Errorf("Fail! Error during set update time: %v", err)
+	}
+	res, _ := db.GetRateUptdTime(currency.USD)
+	if res.Equal(d) {
+		t.Logf("Success! Expected %v, got %v", d, res)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", d, res)
+	}
+	res, _ = db.GetRateUptdTime(currency.EUR)
+	if res.IsZero() {
+		t.Logf("Success! Expected zero time, got %v", res)
+	} else {
+		t.Errorf("Fail! Expected zero time, got %v", res)
+	}
+}
@@ -57,7 +57,7 @@ from (
 					else 0
 					end as vol,
 				case
-					when o.op_id in (2, 5, 9, 10)
+					when o.op_id in (2, 5, 7, 9, 10, 11, 14)
 						then o.vol * o.price
 					else
 						o.vol * o.price * -1
@@ -1,6 +1,12 @@
 package postgres
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+)
 
 // AddTcsToken adds token to access tcs API
 func (db Db) AddTcsToken(token string) error {
@@ -35,3 +41,48 @@ func (db Db) GetTcsToken() (string, error) {
 	}
 	return "", nil
 }
+
+// GetSchedules returns all background job schedules sorted by id
+func (db Db) GetSchedules() ([]models.Schedule, error) {
+	var out []byte
+	query := "select settings->'schedules' from settings where settings->>'ver' = '1';"
+	err := db.connection.QueryRow(db.context, query).Scan(&out)
+	if err != nil {
+		return nil, err
+	}
+	schedules := map[string]models.Schedule{}
+	if out != nil {
+		if err = json.Unmarshal(out, &schedules); err != nil {
+			return nil, err
+		}
+	}
+	result := make([]models.Schedule, 0, len(schedules))
+	for _, sch := range schedules {
+		result = append(result, sch)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// SaveSchedule adds background job schedule or replaces existing one with the same id
+func (db Db) SaveSchedule(sch models.Schedule) error {
+	raw, err := json.Marshal(sch)
+	if err != nil {
+		return err
+	}
+	query := `update settings set settings = jsonb_set(settings, '{schedules}',
+		coalesce(settings->'schedules', '{}'::jsonb) || jsonb_build_object($1::text, $2::jsonb))
+		where settings->>'ver' = '1';`
+	_, err = db.connection.Exec(db.context, query, sch.ID, string(raw))
+	return err
+}
+
+// DeleteSchedule removes background job schedule
+func (db Db) DeleteSchedule(id string) (bool, error) {
+	query := "update settings set settings = settings #- array['schedules', $1::text] where settings->>'ver' = '1' and settings->'schedules' ? $1::text;"
+	r, err := db.connection.Exec(db.context, query, id)
+	if err != nil {
+		return false, err
+	}
+	return r.RowsAffected() != 0, nil
+}
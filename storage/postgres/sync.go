@@ -1,11 +1,11 @@
 package postgres
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/jackc/pgconn"
 	"github.com/kaseat/pManager/models/provider"
+	"github.com/kaseat/pManager/storage/errs"
 )
 
 // AddUserLastUpdateTime saves last date when specified provider made sync
@@ -16,13 +16,13 @@ func (db Db) AddUserLastUpdateTime(login string, provider provider.Type, date ti
 	if err != nil {
 		if pgerr, ok := err.(*pgconn.PgError); ok {
 			if pgerr.Code == "23503" {
-				return fmt.Errorf("last uptate time with login: %s and provider: %s already exists", login, provider)
+				return errs.New(errs.Conflict, "last uptate time with login: %s and provider: %s already exists", login, provider)
 			}
 		}
 		return err
 	}
 	if r.RowsAffected() == 0 {
-		return fmt.Errorf("could not fint user with login: %s", login)
+		return errs.New(errs.NotFound, "could not fint user with login: %s", login)
 	}
 	return nil
 }
@@ -2,10 +2,11 @@ package postgres
 
 import (
 	"encoding/json"
-	"errors"
 	"strconv"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
 	"golang.org/x/oauth2"
 )
 
@@ -22,7 +23,7 @@ func (db Db) AddUser(login, email, hash string) (string, error) {
 	}
 	if err != nil {
 		if err.Error() == `ERROR: duplicate key value violates unique constraint "pk_users_login" (SQLSTATE 23505)` {
-			return "", errors.New("User with this login already exists")
+			return "", errs.New(errs.Conflict, "User with this login already exists")
 		}
 		return "", err
 	}
@@ -39,6 +40,9 @@ func (db Db) GetUserByLogin(login string) (models.User, error) {
 
 	query := "select id,role_id,email from users where login = $1;"
 	err := db.connection.QueryRow(db.context, query, login).Scan(&id, &role, &email)
+	if err == pgx.ErrNoRows {
+		return result, errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
 	if err != nil {
 		return result, err
 	}
@@ -73,6 +77,9 @@ func (db Db) GetUserState(login string) (string, error) {
 	state := ""
 	query := "select g_sync_state from users where login = $1;"
 	err := db.connection.QueryRow(db.context, query, login).Scan(&state)
+	if err == pgx.ErrNoRows {
+		return "", errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -98,6 +105,9 @@ func (db Db) GetUserToken(login string) (oauth2.Token, error) {
 	token := oauth2.Token{}
 	query := "select g_sync_token from users where login = $1;"
 	err := db.connection.QueryRow(db.context, query, login).Scan(&token)
+	if err == pgx.ErrNoRows {
+		return token, errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
 	if err != nil {
 		return token, err
 	}
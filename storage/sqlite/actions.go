@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/action"
+)
+
+// AddCorporateActions saves corporate actions into a storage. Existing actions are overwritten
+func (db Db) AddCorporateActions(actions []models.CorporateAction) error {
+	c, err := db.connection.BeginTx(db.context, nil)
+	if err != nil {
+		return err
+	}
+	query := `insert into corporate_actions (isin,type,date,ratio_from,ratio_to,new_isin,new_ticker,cost_ratio)
+		values (?1,?2,?3,?4,?5,nullif(?6,''),nullif(?7,''),?8)
+		on conflict (isin,type,date) do update set
+			ratio_from = excluded.ratio_from,
+			ratio_to = excluded.ratio_to,
+			new_isin = excluded.new_isin,
+			new_ticker = excluded.new_ticker,
+			cost_ratio = excluded.cost_ratio;`
+	for _, a := range actions {
+		_, err = c.ExecContext(db.context, query, a.ISIN, string(a.Type), toDate(a.Date),
+			a.RatioFrom, a.RatioTo, a.NewISIN, a.NewTicker, a.CostRatio)
+		if err != nil {
+			c.Rollback()
+			return err
+		}
+	}
+	return c.Commit()
+}
+
+// GetCorporateActions finds corporate actions of given security sorted by date.
+// Empty ISIN means all securities
+func (db Db) GetCorporateActions(isin string) ([]models.CorporateAction, error) {
+	params := []interface{}{}
+	query := `select isin,type,date,ratio_from,ratio_to,coalesce(new_isin,''),coalesce(new_ticker,''),cost_ratio
+		from corporate_actions where 1 = 1`
+	if isin != "" {
+		params = append(params, isin)
+		query += fmt.Sprintf(" and isin = ?%d", len(params))
+	}
+	query += " order by date, isin, type;"
+
+	rows, err := db.connection.QueryContext(db.context, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.CorporateAction{}
+	for rows.Next() {
+		a := models.CorporateAction{}
+		var kind string
+		err = rows.Scan(&a.ISIN, &kind, &a.Date, &a.RatioFrom, &a.RatioTo, &a.NewISIN, &a.NewTicker, &a.CostRatio)
+		if err != nil {
+			return nil, err
+		}
+		a.Type = action.Type(kind)
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+// DeleteCorporateActions removes corporate actions of given security. Empty ISIN means all securities
+func (db Db) DeleteCorporateActions(isin string) (int64, error) {
+	query := "delete from corporate_actions;"
+	params := []interface{}{}
+	if isin != "" {
+		query = "delete from corporate_actions where isin = ?1;"
+		params = append(params, isin)
+	}
+	r, err := db.connection.ExecContext(db.context, query, params...)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
@@ -0,0 +1,160 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	// registers sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Init sqlite module. Creates schema if database is empty
+func (db *Db) Init(config Config) error {
+	db.context = context.Background()
+	conn, err := sql.Open("sqlite3", config.Path)
+	if err != nil {
+		return err
+	}
+	// sqlite allows single writer only, and pragmas and :memory: databases
+	// are bound to connection, so keep exactly one
+	conn.SetMaxOpenConns(1)
+
+	_, err = conn.ExecContext(db.context, "PRAGMA foreign_keys = ON;")
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	for _, query := range append(schema, seed...) {
+		_, err = conn.ExecContext(db.context, query)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	err = upgrade(db.context, conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Init sqlite ok")
+	db.connection = conn
+	return nil
+}
+
+// IsInitialized checks if db initialized
+func (db *Db) IsInitialized() bool {
+	if db.context == nil {
+		return false
+	}
+	return true
+}
+
+// Close closes database
+func (db *Db) Close() error {
+	return db.connection.Close()
+}
+
+func upgrade(ctx context.Context, conn *sql.DB) error {
+	var version int
+	err := conn.QueryRowContext(ctx, "PRAGMA user_version;").Scan(&version)
+	if err != nil {
+		return err
+	}
+	for i := version; i < len(upgrades); i++ {
+		err = upgrades[i](ctx, conn)
+		if err != nil {
+			return err
+		}
+		// pragma does not accept parameters
+		_, err = conn.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d;", i+1))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upgradeStep changes schema or data of existing database
+type upgradeStep func(ctx context.Context, conn *sql.DB) error
+
+func exec(query string) upgradeStep {
+	return func(ctx context.Context, conn *sql.DB) error {
+		_, err := conn.ExecContext(ctx, query)
+		return err
+	}
+}
+
+// backfillFingerprints sets fingerprints of operations added before they were
+// introduced. Fingerprints are computed by models.Operation.Fingerprint, and identical
+// operations get their ordinal appended the same way models.Fingerprints does
+func backfillFingerprints(ctx context.Context, conn *sql.DB) error {
+	type row struct {
+		id  int
+		pid int
+		op  models.Operation
+	}
+	query := `select o.id, o.pid, s.isin, s.ticker, o.time, t.name, o.vol, o.price from operations o
+	inner join securities s on s.id = o.sid inner join operation_types t on t.id = o.op_id
+	where o.fingerprint is null order by o.id;`
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	// single connection is busy until rows are closed, so read them all first
+	pending := []row{}
+	for rows.Next() {
+		r := row{}
+		err = rows.Scan(&r.id, &r.pid, &r.op.ISIN, &r.op.Ticker, &r.op.DateTime, &r.op.OperationType, &r.op.Volume, &r.op.Price)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, r)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil || len(pending) == 0 {
+		return err
+	}
+
+	rows, err = conn.QueryContext(ctx, "select pid, fingerprint from operations where fingerprint is not null;")
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var pid int
+		var fp string
+		err = rows.Scan(&pid, &fp)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		existing[fmt.Sprintf("%d:%s", pid, fp)] = true
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, r := range pending {
+		base := r.op.Fingerprint()
+		fp := base
+		for n := 1; existing[fmt.Sprintf("%d:%s", r.pid, fp)]; n++ {
+			fp = fmt.Sprintf("%s#%d", base, n)
+		}
+		existing[fmt.Sprintf("%d:%s", r.pid, fp)] = true
+		_, err = tx.ExecContext(ctx, "update operations set fingerprint = ?1 where id = ?2;", fp, r.id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
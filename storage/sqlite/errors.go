@@ -0,0 +1,15 @@
+package sqlite
+
+import (
+	"github.com/mattn/go-sqlite3"
+)
+
+func isUniqueViolation(err error) bool {
+	sqlerr, ok := err.(sqlite3.Error)
+	return ok && (sqlerr.ExtendedCode == sqlite3.ErrConstraintUnique || sqlerr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
+
+func isForeignKeyViolation(err error) bool {
+	sqlerr, ok := err.(sqlite3.Error)
+	return ok && sqlerr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/event"
+)
+
+// AddSecurityEvents saves security events into a storage. Existing events are overwritten
+func (db Db) AddSecurityEvents(events []models.SecurityEvent) error {
+	c, err := db.connection.BeginTx(db.context, nil)
+	if err != nil {
+		return err
+	}
+	query := `insert into security_events (isin,type,date,value,currency) values (?1,?2,?3,?4,?5)
+		on conflict (isin,type,date) do update set value = excluded.value, currency = excluded.currency;`
+	for _, e := range events {
+		_, err = c.ExecContext(db.context, query, e.ISIN, string(e.Type), toDate(e.Date), e.Value, string(e.Currency))
+		if err != nil {
+			c.Rollback()
+			return err
+		}
+	}
+	return c.Commit()
+}
+
+// GetSecurityEvents finds events of given security between dates. Empty ISIN means all securities
+func (db Db) GetSecurityEvents(isin, from, to string) ([]models.SecurityEvent, error) {
+	params := []interface{}{}
+	query := "select isin,type,date,value,currency from security_events where 1 = 1"
+	if isin != "" {
+		params = append(params, isin)
+		query += fmt.Sprintf(" and isin = ?%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", from); err == nil {
+		params = append(params, toDate(dtime))
+		query += fmt.Sprintf(" and date >= ?%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", to); err == nil {
+		params = append(params, dtime.UTC())
+		query += fmt.Sprintf(" and date <= ?%d", len(params))
+	}
+	query += " order by date, isin, type;"
+
+	rows, err := db.connection.QueryContext(db.context, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.SecurityEvent{}
+	for rows.Next() {
+		e := models.SecurityEvent{}
+		var kind, curr string
+		err = rows.Scan(&e.ISIN, &kind, &e.Date, &e.Value, &curr)
+		if err != nil {
+			return nil, err
+		}
+		e.Type = event.Type(kind)
+		e.Currency = currency.Type(curr)
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// DeleteSecurityEvents removes events of given security. Empty ISIN means all securities
+func (db Db) DeleteSecurityEvents(isin string) (int64, error) {
+	query := "delete from security_events;"
+	params := []interface{}{}
+	if isin != "" {
+		query = "delete from security_events where isin = ?1;"
+		params = append(params, isin)
+	}
+	r, err := db.connection.ExecContext(db.context, query, params...)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
@@ -0,0 +1,238 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/exchange"
+	"github.com/kaseat/pManager/models/instrument"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+const selectInstruments = `select s.id,isin,ticker,figi,currency,coalesce(code,''),id_name,s.title,price_upd_time
+	from securities s inner join securities_types t on t.id = s.asset_type
+	left join exchange e on e.id = s.exchange_id`
+
+// AddInstruments saves instruments info into a storage
+func (db Db) AddInstruments(instr []models.Instrument) error {
+	sType := getsecuritiesTypeByName()
+	exType := getExchangeIDByName()
+
+	c, err := db.connection.BeginTx(db.context, nil)
+	if err != nil {
+		return err
+	}
+	query := "insert into securities (isin,ticker,figi,currency,exchange_id,asset_type,title) values (?1,?2,?3,?4,?5,?6,?7);"
+	for _, ins := range instr {
+		var exchangeID *int
+		if id, ok := exType[ins.Exchange]; ok {
+			exchangeID = &id
+		}
+		_, err = c.ExecContext(db.context, query, ins.ISIN, ins.Ticker, ins.FIGI, ins.Currency, exchangeID, sType[ins.Type], ins.Name)
+		if err != nil {
+			c.Rollback()
+			if isForeignKeyViolation(err) {
+				return errs.New(errs.Validation, "could not add instrument %s: unknown currency or type", ins.ISIN)
+			}
+			if isUniqueViolation(err) {
+				return errs.New(errs.Conflict, "could not add instrument %s: already exists", ins.ISIN)
+			}
+			return err
+		}
+	}
+	return c.Commit()
+}
+
+// SetInstrumentPriceUptdTime sets time instrument prise was updated
+func (db Db) SetInstrumentPriceUptdTime(sid int, updTime time.Time) (bool, error) {
+	query := "update securities set price_upd_time = ?1 where id = ?2;"
+	r, err := db.connection.ExecContext(db.context, query, toDate(updTime), sid)
+	if err != nil {
+		return false, err
+	}
+	return hasAffected(r)
+}
+
+// ClearInstrumentPriceUptdTime clears time instrument prise was updated
+func (db Db) ClearInstrumentPriceUptdTime(isin string) (bool, error) {
+	query := "update securities set price_upd_time = NULL where isin = ?1 and price_upd_time is not null;"
+	r, err := db.connection.ExecContext(db.context, query, isin)
+	if err != nil {
+		return false, err
+	}
+	return hasAffected(r)
+}
+
+// ClearAllInstrumentPriceUptdTime clears time instrument prise was updated (for all)
+func (db Db) ClearAllInstrumentPriceUptdTime() (bool, error) {
+	query := "update securities set price_upd_time = NULL where price_upd_time is not null;"
+	r, err := db.connection.ExecContext(db.context, query)
+	if err != nil {
+		return false, err
+	}
+	return hasAffected(r)
+}
+
+// GetInstruments finds instruments depending on input prameters
+func (db Db) GetInstruments(key string, value string) ([]models.Instrument, error) {
+	if key == "" || value == "" {
+		return db.GetAllInstruments()
+	}
+	column, err := getInstrumentColumn(key)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("%s where %s = ?1;", selectInstruments, column)
+	rows, err := db.connection.QueryContext(db.context, query, value)
+	if err != nil {
+		return nil, err
+	}
+	return scanInstruments(rows)
+}
+
+// GetAllInstruments finds all instruments
+func (db Db) GetAllInstruments() ([]models.Instrument, error) {
+	rows, err := db.connection.QueryContext(db.context, selectInstruments+";")
+	if err != nil {
+		return nil, err
+	}
+	return scanInstruments(rows)
+}
+
+// FindInstruments finds instruments matching given query.
+// Returns requested page of instruments and total number of matched ones
+func (db Db) FindInstruments(q models.ListQuery) ([]models.Instrument, int64, error) {
+	from := ` from securities s inner join securities_types t on t.id = s.asset_type
+	left join exchange e on e.id = s.exchange_id where 1 = 1`
+	where, params, err := filterClause(q.Filters, getInstrumentColumn, []interface{}{})
+	if err != nil {
+		return nil, 0, err
+	}
+	from += where
+	order, err := orderClause(q.Sort, instrumentSortColumns, "instrument", "s.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = db.connection.QueryRowContext(db.context, "select count(*)"+from+";", params...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := "select s.id,isin,ticker,figi,currency,coalesce(code,''),id_name,s.title,price_upd_time" +
+		from + order + limitClause(q.Limit, q.Offset) + ";"
+	rows, err := db.connection.QueryContext(db.context, query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	result, err := scanInstruments(rows)
+	return result, total, err
+}
+
+// DeleteInstruments removes instruments depending on input prameters
+func (db Db) DeleteInstruments(key string, value string) (int64, error) {
+	if key == "" || value == "" {
+		return db.DeleteAllInstruments()
+	}
+	column, err := getInstrumentColumn(key)
+	if err != nil {
+		return 0, err
+	}
+	query := fmt.Sprintf(`delete from securities where id in (select s.id from securities s
+		inner join securities_types t on t.id = s.asset_type
+		left join exchange e on e.id = s.exchange_id where %s = ?1);`, column)
+	r, err := db.connection.ExecContext(db.context, query, value)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
+
+// DeleteAllInstruments removes all instruments from storage
+func (db Db) DeleteAllInstruments() (int64, error) {
+	query := "delete from securities;"
+	r, err := db.connection.ExecContext(db.context, query)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
+
+func scanInstruments(rows *sql.Rows) ([]models.Instrument, error) {
+	defer rows.Close()
+	result := []models.Instrument{}
+	for rows.Next() {
+		ins := models.Instrument{}
+		var tm *time.Time
+		err := rows.Scan(&ins.SecID, &ins.ISIN, &ins.Ticker, &ins.FIGI, &ins.Currency, &ins.Exchange, &ins.Type, &ins.Name, &tm)
+		if err != nil {
+			return nil, err
+		}
+		if tm != nil {
+			ins.PriceUptdTime = *tm
+		}
+		result = append(result, ins)
+	}
+	return result, rows.Err()
+}
+
+// getInstrumentColumn maps filter key to column name, so no raw user
+// input gets into the query
+func getInstrumentColumn(key string) (string, error) {
+	columns := map[string]string{
+		"id":       "s.id",
+		"isin":     "s.isin",
+		"ticker":   "s.ticker",
+		"figi":     "s.figi",
+		"currency": "s.currency",
+		"code":     "e.code",
+		"exchange": "e.code",
+		"id_name":  "t.id_name",
+		"type":     "t.id_name",
+		"title":    "s.title",
+		"name":     "s.title",
+	}
+	column, ok := columns[key]
+	if !ok {
+		return "", errs.New(errs.Validation, "unknown instrument filter: %s", key)
+	}
+	return column, nil
+}
+
+var instrumentSortColumns = map[string]string{
+	"id":       "s.id",
+	"isin":     "s.isin",
+	"ticker":   "s.ticker",
+	"figi":     "s.figi",
+	"name":     "s.title",
+	"type":     "t.id_name",
+	"exchange": "e.code",
+	"currency": "s.currency",
+}
+
+func getsecuritiesTypeByName() map[instrument.Type]int {
+	return map[instrument.Type]int{
+		instrument.Stock:       10,
+		instrument.Bond:        20,
+		instrument.EtfStock:    31,
+		instrument.EtfBond:     32,
+		instrument.EtfMixed:    34,
+		instrument.EtfGold:     35,
+		instrument.EtfCurrency: 36,
+		instrument.Currency:    60,
+	}
+}
+
+func getExchangeIDByName() map[exchange.Type]int {
+	return map[exchange.Type]int{
+		exchange.MOEX:  1,
+		exchange.SPBEX: 2,
+	}
+}
+
+func toDate(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
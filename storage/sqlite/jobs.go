@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddJob saves new background job run and returns its id
+func (db Db) AddJob(j models.Job) (string, error) {
+	jobErrs, err := json.Marshal(j.Errors)
+	if err != nil {
+		return "", err
+	}
+	query := `insert into jobs (type,login,state,started,finished,total,processed,error,errors)
+		values (?1,?2,?3,?4,?5,?6,?7,?8,?9);`
+	r, err := db.connection.ExecContext(db.context, query, string(j.Type), nullString(j.Login), string(j.State),
+		j.Started.UTC(), nullTime(j.Finished), j.Total, j.Processed, nullString(j.Error), string(jobErrs))
+	if err != nil {
+		return "", err
+	}
+	id, err := r.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// UpdateJob replaces state of background job run
+func (db Db) UpdateJob(j models.Job) (bool, error) {
+	id, err := strconv.ParseInt(j.ID, 10, 64)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid job Id format. Expected positive number")
+	}
+	jobErrs, err := json.Marshal(j.Errors)
+	if err != nil {
+		return false, err
+	}
+	query := `update jobs set type = ?2, login = ?3, state = ?4, started = ?5, finished = ?6,
+		total = ?7, processed = ?8, error = ?9, errors = ?10 where id = ?1;`
+	r, err := db.connection.ExecContext(db.context, query, id, string(j.Type), nullString(j.Login), string(j.State),
+		j.Started.UTC(), nullTime(j.Finished), j.Total, j.Processed, nullString(j.Error), string(jobErrs))
+	if err != nil {
+		return false, err
+	}
+	n, err := r.RowsAffected()
+	return n != 0, err
+}
+
+// GetJob finds background job run by id
+func (db Db) GetJob(id string) (models.Job, error) {
+	result := models.Job{}
+	jid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return result, errs.New(errs.Validation, "Invalid job Id format. Expected positive number")
+	}
+
+	var jobType, state string
+	var login, jobErr, jobErrs sql.NullString
+	var finished *time.Time
+	query := "select type,login,state,started,finished,total,processed,error,errors from jobs where id = ?1;"
+	err = db.connection.QueryRowContext(db.context, query, jid).Scan(&jobType, &login, &state,
+		&result.Started, &finished, &result.Total, &result.Processed, &jobErr, &jobErrs)
+	if err == sql.ErrNoRows {
+		return result, errs.New(errs.NotFound, "No job found with %s Id", id)
+	}
+	if err != nil {
+		return result, err
+	}
+	if jobErrs.Valid && jobErrs.String != "" {
+		if err = json.Unmarshal([]byte(jobErrs.String), &result.Errors); err != nil {
+			return result, err
+		}
+	}
+	result.ID = id
+	result.Type = job.Type(jobType)
+	result.Login = login.String
+	result.State = job.State(state)
+	result.Error = jobErr.String
+	if finished != nil {
+		result.Finished = *finished
+	}
+	return result, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
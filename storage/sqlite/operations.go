@@ -0,0 +1,337 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddOperation saves single opertion into a storage.
+// Operation with the same fingerprint must not exist in portfolio
+func (db Db) AddOperation(portfolioID string, op models.Operation) (string, error) {
+	ids, err := db.AddOperations(portfolioID, []models.Operation{op})
+	if err != nil {
+		return "", err
+	}
+	if ids[0] == "" {
+		return "", errs.New(errs.Conflict, "operation already exists")
+	}
+	return ids[0], nil
+}
+
+// AddOperations saves multiple opertions into a storage.
+// Operations which fingerprint already exists in portfolio, or repeats
+// within the batch, are skipped. Fingerprints are taken from models.Fingerprints,
+// so identical fills are kept. Returned Ids follow input order,
+// skipped operations get empty Id
+func (db Db) AddOperations(portfolioID string, ops []models.Operation) ([]string, error) {
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return nil, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+
+	c, err := db.connection.BeginTx(db.context, nil)
+	if err != nil {
+		return nil, err
+	}
+	opIds := getOperationTypesByName()
+	fingerprints := models.Fingerprints(ops)
+	ids := make([]string, len(ops))
+	query := `insert into operations (pid,sid,time,op_id,vol,price,fingerprint) select ?1,id,?3,?4,?5,?6,?7 from securities where isin = ?2 limit 1
+	on conflict (pid,fingerprint) do nothing;`
+	for i, op := range ops {
+		r, err := c.ExecContext(db.context, query, pid, op.ISIN, op.DateTime.UTC(), opIds[string(op.OperationType)], op.Volume, op.Price, fingerprints[i])
+		if err != nil {
+			c.Rollback()
+			if isForeignKeyViolation(err) {
+				return nil, errs.New(errs.NotFound, "could not add operation to unknown portfolio")
+			}
+			return nil, err
+		}
+		if ok, _ := hasAffected(r); !ok {
+			var hasSecurity bool
+			err = c.QueryRowContext(db.context, "select exists(select 1 from securities where isin = ?1);", op.ISIN).Scan(&hasSecurity)
+			if err != nil {
+				c.Rollback()
+				return nil, err
+			}
+			if hasSecurity {
+				continue
+			}
+			c.Rollback()
+			return nil, errs.New(errs.Validation, "could not add operation with unknown ISIN %s", op.ISIN)
+		}
+		id, err := r.LastInsertId()
+		if err != nil {
+			c.Rollback()
+			return nil, err
+		}
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	err = c.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetOperations finds operations depending on input prameters
+func (db Db) GetOperations(portfolioID string, key string, value string, from string, to string) ([]models.Operation, error) {
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return nil, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+	params := []interface{}{pid}
+	query := `select o.id, s.isin, s.figi, s.ticker, s.currency, o.time, t.name, o.vol, o.price from operations o
+	inner join securities s on s.id = o.sid inner join operation_types t on t.id = o.op_id where pid = ?1`
+	if key != "" && value != "" {
+		column, err := getOperationColumn(key)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, value)
+		query += fmt.Sprintf(" and %s = ?%d", column, len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", from); err == nil {
+		params = append(params, dtime.UTC())
+		query += fmt.Sprintf(" and time >= ?%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", to); err == nil {
+		params = append(params, dtime.UTC())
+		query += fmt.Sprintf(" and time <= ?%d", len(params))
+	}
+	query += " order by o.time, o.id;"
+
+	rows, err := db.connection.QueryContext(db.context, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.Operation{}
+	for rows.Next() {
+		var id int
+		op := models.Operation{
+			PortfolioID: portfolioID,
+		}
+		err = rows.Scan(&id, &op.ISIN, &op.FIGI, &op.Ticker, &op.Currency, &op.DateTime, &op.OperationType, &op.Volume, &op.Price)
+		if err != nil {
+			return nil, err
+		}
+		op.OperationID = strconv.Itoa(id)
+		result = append(result, op)
+	}
+	return result, rows.Err()
+}
+
+// FindOperations finds portfolio operations matching given query.
+// Returns requested page of operations and total number of matched ones
+func (db Db) FindOperations(portfolioID string, q models.ListQuery) ([]models.Operation, int64, error) {
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return nil, 0, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+	params := []interface{}{pid}
+	from := ` from operations o inner join securities s on s.id = o.sid
+	inner join operation_types t on t.id = o.op_id where pid = ?1`
+	where, params, err := filterClause(q.Filters, getOperationColumn, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	from += where
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.From); err == nil {
+		params = append(params, dtime.UTC())
+		from += fmt.Sprintf(" and time >= ?%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.To); err == nil {
+		params = append(params, dtime.UTC())
+		from += fmt.Sprintf(" and time <= ?%d", len(params))
+	}
+	order, err := orderClause(q.Sort, operationSortColumns, "operation", "o.time, o.id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = db.connection.QueryRowContext(db.context, "select count(*)"+from+";", params...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := "select o.id, s.isin, s.figi, s.ticker, s.currency, o.time, t.name, o.vol, o.price" +
+		from + order + limitClause(q.Limit, q.Offset) + ";"
+	rows, err := db.connection.QueryContext(db.context, query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	result := []models.Operation{}
+	for rows.Next() {
+		var id int
+		op := models.Operation{
+			PortfolioID: portfolioID,
+		}
+		err = rows.Scan(&id, &op.ISIN, &op.FIGI, &op.Ticker, &op.Currency, &op.DateTime, &op.OperationType, &op.Volume, &op.Price)
+		if err != nil {
+			return nil, 0, err
+		}
+		op.OperationID = strconv.Itoa(id)
+		result = append(result, op)
+	}
+	return result, total, rows.Err()
+}
+
+// GetOperation finds operation by Id
+func (db Db) GetOperation(portfolioID string, operationID string) (models.Operation, error) {
+	result := models.Operation{}
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return result, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+	id, err := strconv.ParseInt(operationID, 10, 32)
+	if err != nil {
+		return result, errs.New(errs.Validation, "Invalid operation Id format. Expected positive number")
+	}
+
+	query := `select s.isin, s.figi, s.ticker, s.currency, o.time, t.name, o.vol, o.price from operations o
+	inner join securities s on s.id = o.sid inner join operation_types t on t.id = o.op_id where o.pid = ?1 and o.id = ?2;`
+	err = db.connection.QueryRowContext(db.context, query, pid, id).Scan(&result.ISIN, &result.FIGI, &result.Ticker,
+		&result.Currency, &result.DateTime, &result.OperationType, &result.Volume, &result.Price)
+	if err == sql.ErrNoRows {
+		return result, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
+	}
+	if err != nil {
+		return result, err
+	}
+	result.PortfolioID = portfolioID
+	result.OperationID = operationID
+	return result, nil
+}
+
+// UpdateOperation replaces operation data. Fingerprint is kept, so that
+// edited operation is still recognized when its report is imported again
+func (db Db) UpdateOperation(portfolioID string, operationID string, op models.Operation) (bool, error) {
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+	id, err := strconv.ParseInt(operationID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid operation Id format. Expected positive number")
+	}
+
+	var sid int64
+	query := "select id from securities where isin = ?1 limit 1;"
+	err = db.connection.QueryRowContext(db.context, query, op.ISIN).Scan(&sid)
+	if err == sql.ErrNoRows {
+		return false, errs.New(errs.Validation, "could not update operation with unknown ISIN %s", op.ISIN)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	opIds := getOperationTypesByName()
+	query = "update operations set sid = ?3, time = ?4, op_id = ?5, vol = ?6, price = ?7 where pid = ?1 and id = ?2;"
+	r, err := db.connection.ExecContext(db.context, query, pid, id, sid, op.DateTime.UTC(), opIds[string(op.OperationType)], op.Volume, op.Price)
+	if err != nil {
+		return false, err
+	}
+	return hasAffectedOperation(r, operationID)
+}
+
+// DeleteOperation removes operation by Id
+func (db Db) DeleteOperation(portfolioID string, operationID string) (bool, error) {
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+	id, err := strconv.ParseInt(operationID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid operation Id format. Expected positive number")
+	}
+
+	query := "delete from operations where pid = ?1 and id = ?2;"
+	r, err := db.connection.ExecContext(db.context, query, pid, id)
+	if err != nil {
+		return false, err
+	}
+	return hasAffectedOperation(r, operationID)
+}
+
+// hasAffectedOperation reports operation not found when no row was affected
+func hasAffectedOperation(r sql.Result, operationID string) (bool, error) {
+	ok, err := hasAffected(r)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, errs.New(errs.NotFound, "No operation found with %s Id", operationID)
+	}
+	return true, nil
+}
+
+// DeleteOperations removes all operations for provided portfolio Id
+func (db Db) DeleteOperations(portfolioID string) (int64, error) {
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return 0, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+
+	query := "delete from operations where pid = ?1;"
+	r, err := db.connection.ExecContext(db.context, query, pid)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
+
+// getOperationColumn maps filter key to column name, so no raw user
+// input gets into the query
+func getOperationColumn(key string) (string, error) {
+	columns := map[string]string{
+		"isin":     "s.isin",
+		"ticker":   "s.ticker",
+		"figi":     "s.figi",
+		"currency": "s.currency",
+		"type":     "t.name",
+	}
+	column, ok := columns[key]
+	if !ok {
+		return "", errs.New(errs.Validation, "unknown operation filter: %s", key)
+	}
+	return column, nil
+}
+
+var operationSortColumns = map[string]string{
+	"date":     "o.time",
+	"price":    "o.price",
+	"vol":      "o.vol",
+	"type":     "t.name",
+	"ticker":   "s.ticker",
+	"isin":     "s.isin",
+	"figi":     "s.figi",
+	"currency": "s.currency",
+}
+
+func getOperationTypesByName() map[string]int {
+	return map[string]int{
+		"buy":                 1,
+		"sell":                2,
+		"brokerageFee":        3,
+		"exchangeFee":         4,
+		"payIn":               5,
+		"payOut":              6,
+		"coupon":              7,
+		"accruedInterestBuy":  8,
+		"accruedInterestSell": 9,
+		"buyback":             10,
+		"dividend":            11,
+		"tax":                 12,
+		"withholdingTax":      13,
+		"amortization":        14,
+	}
+}
@@ -0,0 +1,174 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddPortfolio adds new potrfolio
+func (db Db) AddPortfolio(userID string, p models.Portfolio) (string, error) {
+	uid, err := strconv.ParseInt(userID, 10, 32)
+	if err != nil {
+		return "", errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
+	}
+	query := "insert into portfolios (uid,name,title) values (?1,?2,?3);"
+	r, err := db.connection.ExecContext(db.context, query, uid, p.Name, p.Description)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return "", errs.New(errs.NotFound, "could not add portfolio to unknown user")
+		}
+		return "", err
+	}
+	id, err := r.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// GetPortfolio gets operation by id
+func (db Db) GetPortfolio(userID string, portfolioID string) (models.Portfolio, error) {
+	result := models.Portfolio{}
+	uid, err := strconv.ParseInt(userID, 10, 32)
+	if err != nil {
+		return result, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
+	}
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return result, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+
+	var title *string
+	query := "select name,title from portfolios where uid = ?1 and id = ?2;"
+	err = db.connection.QueryRowContext(db.context, query, uid, pid).Scan(&result.Name, &title)
+	if err == sql.ErrNoRows {
+		return result, errs.New(errs.NotFound, "No portfolio found with %s Id", portfolioID)
+	}
+	if err != nil {
+		return result, err
+	}
+	if title != nil {
+		result.Description = *title
+	}
+	result.UserID = userID
+	result.PortfolioID = portfolioID
+	return result, nil
+}
+
+// GetPortfolios gets all portfolio fpvie user Id
+func (db Db) GetPortfolios(userID string) ([]models.Portfolio, error) {
+	uid, err := strconv.ParseInt(userID, 10, 32)
+	if err != nil {
+		return nil, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
+	}
+	query := "select id,name,title from portfolios where uid = ?1;"
+	rows, err := db.connection.QueryContext(db.context, query, uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.Portfolio{}
+	for rows.Next() {
+		var id int
+		var title *string
+		p := models.Portfolio{UserID: userID}
+		err = rows.Scan(&id, &p.Name, &title)
+		if err != nil {
+			return nil, err
+		}
+		p.PortfolioID = strconv.Itoa(id)
+		if title != nil {
+			p.Description = *title
+		}
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
+// UpdatePortfolio updates portfolio with provided uid and pid
+func (db Db) UpdatePortfolio(userID string, portfolioID string, p models.Portfolio) (bool, error) {
+	uid, err := strconv.ParseInt(userID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
+	}
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+
+	query := "update portfolios set name = ?1, title = ?2 where uid = ?3 and id = ?4;"
+	r, err := db.connection.ExecContext(db.context, query, p.Name, p.Description, uid, pid)
+	if err != nil {
+		return false, err
+	}
+	return hasAffected(r)
+}
+
+// DeletePortfolio removes portfolio by Id
+// Also removes all operations associated with this portfolio
+func (db Db) DeletePortfolio(userID string, portfolioID string) (bool, error) {
+	uid, err := strconv.ParseInt(userID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
+	}
+	pid, err := strconv.ParseInt(portfolioID, 10, 32)
+	if err != nil {
+		return false, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+
+	c, err := db.connection.BeginTx(db.context, nil)
+	if err != nil {
+		return false, err
+	}
+	query := "delete from operations where pid in (select id from portfolios where uid = ?1 and id = ?2);"
+	_, err = c.ExecContext(db.context, query, uid, pid)
+	if err != nil {
+		c.Rollback()
+		return false, err
+	}
+	query = "delete from portfolios where uid = ?1 and id = ?2;"
+	r, err := c.ExecContext(db.context, query, uid, pid)
+	if err != nil {
+		c.Rollback()
+		return false, err
+	}
+	err = c.Commit()
+	if err != nil {
+		return false, err
+	}
+	return hasAffected(r)
+}
+
+// DeletePortfolios removes all portfolios for provided user
+// Also removes all operations associated with this portfolios
+func (db Db) DeletePortfolios(userID string) (int64, error) {
+	uid, err := strconv.ParseInt(userID, 10, 32)
+	if err != nil {
+		return 0, errs.New(errs.Validation, "Invalid user Id format. Expected positive number")
+	}
+	c, err := db.connection.BeginTx(db.context, nil)
+	if err != nil {
+		return 0, err
+	}
+	query := "delete from operations where pid in (select id from portfolios where uid = ?1);"
+	_, err = c.ExecContext(db.context, query, uid)
+	if err != nil {
+		c.Rollback()
+		return 0, err
+	}
+	query = "delete from portfolios where uid = ?1;"
+	r, err := c.ExecContext(db.context, query, uid)
+	if err != nil {
+		c.Rollback()
+		return 0, err
+	}
+	err = c.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
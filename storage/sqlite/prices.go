@@ -0,0 +1,168 @@
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddPrices saves prices series into a storage
+func (db Db) AddPrices(prices []models.Price) error {
+	c, err := db.connection.BeginTx(db.context, nil)
+	if err != nil {
+		return err
+	}
+	// prices added through API have no security id, so fall back to ISIN
+	query := `insert into prices (sid,date,vol,price)
+		values (coalesce(nullif(?1,0),(select id from securities where isin = ?2 limit 1)),?3,?4,?5);`
+	for _, pr := range prices {
+		_, err = c.ExecContext(db.context, query, pr.SecID, pr.ISIN, toDate(pr.Date), pr.Volume, pr.Price)
+		if err != nil {
+			c.Rollback()
+			if isUniqueViolation(err) {
+				return errs.New(errs.Conflict, "could not add prices: price for %s on %s already exists", pr.ISIN, pr.Date.Format("2006-01-02"))
+			}
+			return fmt.Errorf("could not add prices for %s: %v", pr.ISIN, err)
+		}
+	}
+	return c.Commit()
+}
+
+// GetPrices finds prices depending on input prameters
+func (db Db) GetPrices(key, value, from, to string) ([]models.Price, error) {
+	params := []interface{}{}
+	query := "select sid,isin,date,vol,price from prices p inner join securities s on s.id=p.sid where 1 = 1"
+	if key != "" && value != "" {
+		column, err := getInstrumentColumn(key)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, value)
+		query += fmt.Sprintf(" and %s = ?%d", column, len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", from); err == nil {
+		params = append(params, toDate(dtime))
+		query += fmt.Sprintf(" and date >= ?%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", to); err == nil {
+		params = append(params, dtime.UTC())
+		query += fmt.Sprintf(" and date <= ?%d", len(params))
+	}
+	query += " order by date, sid;"
+
+	rows, err := db.connection.QueryContext(db.context, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.Price{}
+	for rows.Next() {
+		pr := models.Price{}
+		err = rows.Scan(&pr.SecID, &pr.ISIN, &pr.Date, &pr.Volume, &pr.Price)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, pr)
+	}
+	return result, rows.Err()
+}
+
+// FindPrices finds prices matching given query. Prices are filtered
+// by their instrument fields. Returns requested page of prices and total number of matched ones
+func (db Db) FindPrices(q models.ListQuery) ([]models.Price, int64, error) {
+	from := " from prices p inner join securities s on s.id = p.sid where 1 = 1"
+	where, params, err := filterClause(q.Filters, getPriceColumn, []interface{}{})
+	if err != nil {
+		return nil, 0, err
+	}
+	from += where
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.From); err == nil {
+		params = append(params, toDate(dtime))
+		from += fmt.Sprintf(" and date >= ?%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", q.To); err == nil {
+		params = append(params, dtime.UTC())
+		from += fmt.Sprintf(" and date <= ?%d", len(params))
+	}
+	order, err := orderClause(q.Sort, priceSortColumns, "price", "p.date, p.sid")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = db.connection.QueryRowContext(db.context, "select count(*)"+from+";", params...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := "select p.sid, s.isin, p.date, p.vol, p.price" + from + order + limitClause(q.Limit, q.Offset) + ";"
+	rows, err := db.connection.QueryContext(db.context, query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	result := []models.Price{}
+	for rows.Next() {
+		pr := models.Price{}
+		err = rows.Scan(&pr.SecID, &pr.ISIN, &pr.Date, &pr.Volume, &pr.Price)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, pr)
+	}
+	return result, total, rows.Err()
+}
+
+// GetPricesByIsin finds prices for given ISIN and dates
+func (db Db) GetPricesByIsin(isin, from, to string) ([]models.Price, error) {
+	return db.GetPrices("isin", isin, from, to)
+}
+
+// DeletePrices removes prices depending on input prameters
+func (db Db) DeletePrices(key string, value string) (int64, error) {
+	if key == "" || value == "" {
+		return db.DeleteAllPrices()
+	}
+	column, err := getInstrumentColumn(key)
+	if err != nil {
+		return 0, err
+	}
+	query := fmt.Sprintf(`delete from prices where sid in (select s.id from securities s
+		inner join securities_types t on t.id = s.asset_type
+		left join exchange e on e.id = s.exchange_id where %s = ?1);`, column)
+	r, err := db.connection.ExecContext(db.context, query, value)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
+
+// DeleteAllPrices removes all prices from storage
+func (db Db) DeleteAllPrices() (int64, error) {
+	query := "delete from prices;"
+	r, err := db.connection.ExecContext(db.context, query)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
+
+var priceSortColumns = map[string]string{
+	"date":  "p.date",
+	"isin":  "s.isin",
+	"price": "p.price",
+	"vol":   "p.vol",
+}
+
+// getPriceColumn maps price filter key to column of price instrument
+func getPriceColumn(key string) (string, error) {
+	switch key {
+	case "isin", "ticker", "figi":
+		return "s." + key, nil
+	default:
+		return "", errs.New(errs.Validation, "unknown price filter: %s", key)
+	}
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddRates saves currency rates into a storage. Existing rates are overwritten
+func (db Db) AddRates(rates []models.Rate) error {
+	c, err := db.connection.BeginTx(db.context, nil)
+	if err != nil {
+		return err
+	}
+	query := `insert into rates (currency,date,rate) values (?1,?2,?3)
+		on conflict (currency,date) do update set rate = excluded.rate;`
+	for _, r := range rates {
+		_, err = c.ExecContext(db.context, query, string(r.Currency), toDate(r.Date), r.Rate)
+		if err != nil {
+			c.Rollback()
+			if isForeignKeyViolation(err) {
+				return errs.New(errs.Validation, "could not add rates: unknown currency %s", r.Currency)
+			}
+			return err
+		}
+	}
+	return c.Commit()
+}
+
+// GetRates finds rates of given currency between dates. Empty currency means all currencies
+func (db Db) GetRates(curr currency.Type, from, to string) ([]models.Rate, error) {
+	params := []interface{}{}
+	query := "select currency,date,rate from rates where 1 = 1"
+	if curr != "" {
+		params = append(params, string(curr))
+		query += fmt.Sprintf(" and currency = ?%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", from); err == nil {
+		params = append(params, toDate(dtime))
+		query += fmt.Sprintf(" and date >= ?%d", len(params))
+	}
+	if dtime, err := time.Parse("2006-01-02T15:04:05Z07:00", to); err == nil {
+		params = append(params, dtime.UTC())
+		query += fmt.Sprintf(" and date <= ?%d", len(params))
+	}
+	query += " order by date, currency;"
+
+	rows, err := db.connection.QueryContext(db.context, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.Rate{}
+	for rows.Next() {
+		r := models.Rate{}
+		var curr string
+		err = rows.Scan(&curr, &r.Date, &r.Rate)
+		if err != nil {
+			return nil, err
+		}
+		r.Currency = currency.Type(curr)
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// DeleteRates removes rates of given currency. Empty currency means all currencies
+func (db Db) DeleteRates(curr currency.Type) (int64, error) {
+	query := "delete from rates;"
+	params := []interface{}{}
+	if curr != "" {
+		query = "delete from rates where currency = ?1;"
+		params = append(params, string(curr))
+	}
+	r, err := db.connection.ExecContext(db.context, query, params...)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
+
+// SetRateUptdTime sets time currency rates were updated
+func (db Db) SetRateUptdTime(curr currency.Type, updTime time.Time) (bool, error) {
+	query := "update currencies set rate_upd_time = ?1 where code = ?2;"
+	r, err := db.connection.ExecContext(db.context, query, toDate(updTime), string(curr))
+	if err != nil {
+		return false, err
+	}
+	return hasAffected(r)
+}
+
+// GetRateUptdTime gets time currency rates were updated. Zero time means never
+func (db Db) GetRateUptdTime(curr currency.Type) (time.Time, error) {
+	var updTime *time.Time
+	query := "select rate_upd_time from currencies where code = ?1;"
+	err := db.connection.QueryRowContext(db.context, query, string(curr)).Scan(&updTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if updTime == nil {
+		return time.Time{}, nil
+	}
+	return *updTime, nil
+}
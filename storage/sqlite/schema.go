@@ -0,0 +1,201 @@
+package sqlite
+
+// schema mirrors db/tables/*.sql
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS user_roles (
+		id integer NOT NULL,
+		id_name varchar(15) NOT NULL,
+		title varchar(50) NOT NULL,
+		CONSTRAINT pk_user_roles PRIMARY KEY (id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS users (
+		id integer NOT NULL,
+		login varchar(50) NOT NULL,
+		hash varchar(150) NOT NULL,
+		role_id integer NOT NULL,
+		email varchar(100) NULL,
+		g_sync_state varchar(24) NULL,
+		g_sync_token text NULL,
+		CONSTRAINT pk_users PRIMARY KEY (id),
+		CONSTRAINT fk_users_user_roles FOREIGN KEY(role_id) REFERENCES user_roles(id)
+	);`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS pk_users_login ON users(login);`,
+	`CREATE TABLE IF NOT EXISTS sync_providers (
+		id integer NOT NULL,
+		name varchar(20) NOT NULL,
+		description varchar(50) NOT NULL,
+		CONSTRAINT pk_sync_providers PRIMARY KEY (id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS user_sync (
+		uid integer NOT NULL,
+		provider_id integer NOT NULL,
+		last_sync timestamp NOT NULL,
+		CONSTRAINT pk_user_sync PRIMARY KEY (uid,provider_id),
+		CONSTRAINT fk_user_sync_users FOREIGN KEY(uid) REFERENCES users(id),
+		CONSTRAINT fk_user_sync_sync_providers FOREIGN KEY(provider_id) REFERENCES sync_providers(id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS portfolios (
+		id integer NOT NULL,
+		uid integer NOT NULL,
+		name varchar(50) NOT NULL,
+		title varchar(150) NULL,
+		CONSTRAINT pk_portfolios PRIMARY KEY (id),
+		CONSTRAINT fk_portfolios_users FOREIGN KEY(uid) REFERENCES users(id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS currencies (
+		code char(3) NOT NULL,
+		title varchar(150) NULL,
+		CONSTRAINT pk_currencies PRIMARY KEY (code)
+	);`,
+	`CREATE TABLE IF NOT EXISTS securities_types (
+		id integer NOT NULL,
+		id_name varchar(15) NOT NULL,
+		title varchar(50) NOT NULL,
+		CONSTRAINT pk_securities_types PRIMARY KEY (id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS exchange (
+		id integer NOT NULL,
+		code varchar(10) NOT NULL,
+		title varchar(150) NULL,
+		CONSTRAINT pk_exchange PRIMARY KEY (id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS securities (
+		id integer NOT NULL,
+		isin varchar(12) NOT NULL,
+		ticker varchar(12) NOT NULL,
+		figi varchar(12) NOT NULL,
+		currency char(3) NOT NULL,
+		-- nullable unlike postgres: instruments synced from TCS have no exchange
+		exchange_id integer NULL,
+		asset_type integer NOT NULL,
+		title varchar(100) NOT NULL,
+		price_upd_time date NULL,
+		CONSTRAINT pk_securities_id PRIMARY KEY (id),
+		CONSTRAINT fk_securities_currency FOREIGN KEY(currency) REFERENCES currencies(code),
+		CONSTRAINT fk_securities_securities_types FOREIGN KEY(asset_type) REFERENCES securities_types(id),
+		CONSTRAINT fk_securities_exchange FOREIGN KEY(exchange_id) REFERENCES exchange(id)
+	);`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS pk_securities_isin ON securities(isin, currency);`,
+	`CREATE TABLE IF NOT EXISTS operation_types (
+		id integer NOT NULL,
+		name varchar(30) NOT NULL,
+		title varchar(150) NOT NULL,
+		CONSTRAINT pk_operation_types PRIMARY KEY (id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS operations (
+		id integer NOT NULL,
+		pid integer NOT NULL,
+		sid integer NOT NULL,
+		time timestamp NOT NULL,
+		op_id integer NOT NULL,
+		vol integer NOT NULL,
+		price numeric(20,6) NOT NULL,
+		CONSTRAINT pk_operations PRIMARY KEY (id),
+		CONSTRAINT fk_operations_portfolios FOREIGN KEY(pid) REFERENCES portfolios(id),
+		CONSTRAINT fk_operations_operation_types FOREIGN KEY(op_id) REFERENCES operation_types(id),
+		CONSTRAINT fk_operations_securities FOREIGN KEY(sid) REFERENCES securities(id)
+	);`,
+	`CREATE INDEX IF NOT EXISTS ix_operations ON operations(pid, sid, time);`,
+	`CREATE TABLE IF NOT EXISTS prices (
+		sid integer NOT NULL,
+		date date NOT NULL,
+		vol integer NOT NULL,
+		price numeric(20,6) NOT NULL,
+		CONSTRAINT pk_prices PRIMARY KEY (sid, date),
+		CONSTRAINT fk_prices_securities FOREIGN KEY(sid) REFERENCES securities(id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS rates (
+		currency char(3) NOT NULL,
+		date date NOT NULL,
+		rate numeric(20,6) NOT NULL,
+		CONSTRAINT pk_rates PRIMARY KEY (currency, date),
+		CONSTRAINT fk_rates_currency FOREIGN KEY(currency) REFERENCES currencies(code)
+	);`,
+	`CREATE TABLE IF NOT EXISTS security_events (
+		isin varchar(12) NOT NULL,
+		type varchar(20) NOT NULL,
+		date date NOT NULL,
+		value numeric(20,6) NOT NULL,
+		currency char(3) NOT NULL,
+		CONSTRAINT pk_security_events PRIMARY KEY (isin, type, date)
+	);`,
+	`CREATE TABLE IF NOT EXISTS corporate_actions (
+		isin varchar(12) NOT NULL,
+		type varchar(20) NOT NULL,
+		date date NOT NULL,
+		ratio_from bigint NOT NULL,
+		ratio_to bigint NOT NULL,
+		new_isin varchar(12) NULL,
+		new_ticker varchar(12) NULL,
+		cost_ratio numeric(10,6) NOT NULL,
+		CONSTRAINT pk_corporate_actions PRIMARY KEY (isin, type, date)
+	);`,
+	`CREATE TABLE IF NOT EXISTS jobs (
+		id integer NOT NULL,
+		type varchar(20) NOT NULL,
+		login varchar(50) NULL,
+		state varchar(20) NOT NULL,
+		started timestamp NOT NULL,
+		finished timestamp NULL,
+		total integer NOT NULL,
+		processed integer NOT NULL,
+		error text NULL,
+		errors text NULL,
+		CONSTRAINT pk_jobs PRIMARY KEY (id)
+	);`,
+	`CREATE TABLE IF NOT EXISTS settings (
+		settings text NOT NULL
+	);`,
+}
+
+// upgrades change schema of existing databases. Applied upgrades count
+// is kept in user_version pragma, so only append new statements here
+var upgrades = []upgradeStep{
+	exec(`ALTER TABLE currencies ADD COLUMN rate_upd_time date NULL;`),
+	exec(`ALTER TABLE operations ADD COLUMN fingerprint varchar(100) NULL;`),
+	exec(`CREATE UNIQUE INDEX IF NOT EXISTS ux_operations_fingerprint ON operations(pid, fingerprint);`),
+	backfillFingerprints,
+}
+
+// seed mirrors db/post_deployment.sql
+var seed = []string{
+	`INSERT OR IGNORE INTO currencies (code,title) VALUES
+		('EUR','Евро'),
+		('USD','Доллар США'),
+		('RUB','Российский рубль');`,
+	`INSERT OR IGNORE INTO operation_types VALUES
+		(1,'buy','Покупка'),
+		(2,'sell','Продажа'),
+		(3,'brokerageFee','Комиссия брокера'),
+		(4,'exchangeFee','Комиссия биржи'),
+		(5,'payIn','Ввод средств'),
+		(6,'payOut','Вывод средств'),
+		(7,'coupon','Выплата купона'),
+		(8,'accruedInterestBuy','НКД при покупке'),
+		(9,'accruedInterestSell','НКД при продаже'),
+		(10,'buyback','Выкуп ценной бумаги'),
+		(11,'dividend','Выплата дивидендов'),
+		(12,'tax','Налог'),
+		(13,'withholdingTax','Налог, удержанный с дохода'),
+		(14,'amortization','Частичное погашение номинала');`,
+	`INSERT OR IGNORE INTO securities_types VALUES
+		(10,'Stock','Акции'),
+		(20,'Bond','Облигации'),
+		(31,'EtfStock','ETF на акции'),
+		(32,'EtfBond','ETF на облигации'),
+		(34,'EtfMixed','Смешанный ETF'),
+		(35,'EtfGold','ETF на золото'),
+		(36,'EtfCurrency','ETF на аналог кэша'),
+		(60,'Currency','Кэш');`,
+	`INSERT OR IGNORE INTO user_roles VALUES
+		(1,'admin','Администратор'),
+		(2,'user','Пользователь');`,
+	`INSERT OR IGNORE INTO sync_providers VALUES
+		(1,'sber','Сбербанк'),
+		(2,'tcs','Тинькофф'),
+		(3,'vtb','ВТБ');`,
+	`INSERT OR IGNORE INTO exchange VALUES
+		(1,'MOEX','Московская биржа'),
+		(2,'SPBEX','Санкт-Петербургская биржа');`,
+	`INSERT INTO settings (settings) SELECT '{"ver":1}' WHERE NOT EXISTS (SELECT 1 FROM settings);`,
+}
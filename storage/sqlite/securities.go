@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// GetShares gets shares
+func (db Db) GetShares(pid string, onDate string) ([]models.Share, error) {
+	id, err := strconv.ParseInt(pid, 10, 32)
+	if err != nil {
+		return nil, errs.New(errs.Validation, "Invalid portfolio Id format. Expected positive number")
+	}
+	dtime := time.Now()
+	if t, err := time.Parse("2006-01-02T15:04:05Z07:00", onDate); err == nil {
+		dtime = t
+	}
+
+	query := `
+select
+	s.isin,
+	s.ticker,
+	x.vol,
+	x.balance,
+	coalesce((
+		select p.price from prices p
+		where p.sid = s.id and p.date <= ?2
+		order by p.date desc limit 1
+	), 0) as current_price
+from (
+	select
+		o.sid,
+		sum(case
+			when t.name = 'buy' then o.vol
+			when t.name in ('sell', 'buyback') then -o.vol
+			else 0
+		end) as vol,
+		sum(case
+			when t.name in ('sell', 'payIn', 'accruedInterestSell', 'buyback', 'coupon', 'dividend', 'amortization') then o.vol * o.price
+			else -o.vol * o.price
+		end) as balance
+	from operations o
+	inner join operation_types t on t.id = o.op_id
+	where o.pid = ?1 and o.time <= ?2
+	group by o.sid
+) x
+inner join securities s on s.id = x.sid
+order by s.isin;`
+
+	rows, err := db.connection.QueryContext(db.context, query, id, dtime.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []models.Share{}
+	sum := 0.0
+	hasOperations := false
+	for rows.Next() {
+		share := models.Share{Date: dtime}
+		var balance float64
+		err = rows.Scan(&share.ISIN, &share.Ticker, &share.Volume, &balance, &share.Price)
+		if err != nil {
+			return nil, err
+		}
+		hasOperations = true
+		sum += balance
+		if share.Volume == 0 || share.ISIN == "RUB" {
+			continue
+		}
+		shares = append(shares, share)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if hasOperations {
+		shares = append(shares, models.Share{
+			ISIN:   "RUB",
+			Ticker: "RUB",
+			Date:   dtime,
+			Volume: 0,
+			Price:  math.Round(sum*100) / 100,
+		})
+	}
+	return shares, nil
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+)
+
+// AddTcsToken adds token to access tcs API
+func (db Db) AddTcsToken(token string) error {
+	return db.updateSettings(func(s map[string]interface{}) { s["tcs_token"] = token })
+}
+
+// DeleteTcsToken deletes token to access tcs API
+func (db Db) DeleteTcsToken() error {
+	return db.updateSettings(func(s map[string]interface{}) { delete(s, "tcs_token") })
+}
+
+// GetTcsToken finds token to access tcs API
+func (db Db) GetTcsToken() (string, error) {
+	s, err := db.getSettings()
+	if err != nil {
+		return "", err
+	}
+	token, _ := s["tcs_token"].(string)
+	return token, nil
+}
+
+// GetSchedules returns all background job schedules sorted by id
+func (db Db) GetSchedules() ([]models.Schedule, error) {
+	s, err := db.getSettings()
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := getSchedules(s)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.Schedule, 0, len(schedules))
+	for _, sch := range schedules {
+		result = append(result, sch)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// SaveSchedule adds background job schedule or replaces existing one with the same id
+func (db Db) SaveSchedule(sch models.Schedule) error {
+	var err error
+	updErr := db.updateSettings(func(s map[string]interface{}) {
+		var schedules map[string]models.Schedule
+		if schedules, err = getSchedules(s); err == nil {
+			schedules[sch.ID] = sch
+			s["schedules"] = schedules
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return updErr
+}
+
+// DeleteSchedule removes background job schedule
+func (db Db) DeleteSchedule(id string) (bool, error) {
+	var err error
+	found := false
+	updErr := db.updateSettings(func(s map[string]interface{}) {
+		var schedules map[string]models.Schedule
+		if schedules, err = getSchedules(s); err == nil {
+			_, found = schedules[id]
+			delete(schedules, id)
+			s["schedules"] = schedules
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+	return found, updErr
+}
+
+// getSchedules converts generic settings value to schedules map keyed by id
+func getSchedules(s map[string]interface{}) (map[string]models.Schedule, error) {
+	result := map[string]models.Schedule{}
+	raw, ok := s["schedules"]
+	if !ok {
+		return result, nil
+	}
+	bytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bytes, &result)
+	return result, err
+}
+
+func (db Db) getSettings() (map[string]interface{}, error) {
+	var raw string
+	// settings table keeps single json row, see seed
+	query := "select settings from settings limit 1;"
+	err := db.connection.QueryRowContext(db.context, query).Scan(&raw)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	err = json.Unmarshal([]byte(raw), &result)
+	return result, err
+}
+
+func (db Db) updateSettings(update func(map[string]interface{})) error {
+	s, err := db.getSettings()
+	if err != nil {
+		return err
+	}
+	update(s)
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	query := "update settings set settings = ?1;"
+	_, err = db.connection.ExecContext(db.context, query, string(bytes))
+	return err
+}
@@ -0,0 +1,832 @@
+package sqlite
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/action"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/event"
+	"github.com/kaseat/pManager/models/exchange"
+	"github.com/kaseat/pManager/models/instrument"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/models/provider"
+	"github.com/kaseat/pManager/storage/errs"
+	"golang.org/x/oauth2"
+)
+
+var db Db
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "pmanager")
+	if err != nil {
+		panic(err)
+	}
+	db = Db{}
+	err = db.Init(Config{
+		Path: filepath.Join(dir, "p_manager_test.db"),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	db.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestUsers(t *testing.T) {
+	login, email, hash := "login", "email", "hash"
+
+	uid, err := db.AddUser(login, email, hash)
+	if err != nil {
+		t.Errorf("Fail! Could not add test user. Internal error: %s", err)
+	}
+
+	_, err = db.AddUser(login, email, hash)
+	expectedErrMsg := "User with this login already exists"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Fail! Expected '%s' got '%v'", expectedErrMsg, err)
+	} else {
+		t.Logf("Success! Expected '%s' got '%s'", expectedErrMsg, err)
+	}
+
+	user, err := db.GetUserByLogin(login)
+	if err != nil {
+		t.Errorf("Fail! Could not get user by login. Internal error: %s", err)
+	}
+	if user.UserID == uid && user.Email == email {
+		t.Logf("Success! Expected %v, got %v", uid, user.UserID)
+	} else {
+		t.Errorf("Fail! Saved and fetched user Ids not match! Expected %v, got %v", uid, user.UserID)
+	}
+
+	state := "state"
+	db.AddUserState(login, state)
+	tok := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}
+	db.AddUserToken(state, tok)
+	res, err := db.GetUserToken(login)
+	if err != nil {
+		t.Errorf("Fail! Could not get user token. Internal error: %s", err)
+	}
+	if res.AccessToken == tok.AccessToken && res.RefreshToken == tok.RefreshToken {
+		t.Logf("Success! Expected %v, got %v", tok.AccessToken, res.AccessToken)
+	} else {
+		t.Errorf("Fail! Saved and fetched tokens not match! Expected %v, got %v", tok.AccessToken, res.AccessToken)
+	}
+
+	updated, _ := db.UpdateUserPassword(login, "new_hash")
+	pass, _ := db.GetUserPassword(login)
+	if updated && pass == "new_hash" {
+		t.Logf("Success! Expected %v, got %v", "new_hash", pass)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", "new_hash", pass)
+	}
+
+	deleted, err := db.DeleteUser(login)
+	if deleted {
+		t.Logf("Success! Expected user deleted, got %v", deleted)
+	} else {
+		t.Errorf("Fail! Expected user deleted, got %v, %v", deleted, err)
+	}
+}
+
+func TestLastUpdateTimeStorage(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-05-13T22:08:41Z")
+	login := "test_upd_login"
+	db.AddUser(login, "a@a.a", "some_hash")
+
+	err := db.AddUserLastUpdateTime(login, provider.Sber, now)
+	if err != nil {
+		t.Errorf("Fail! Could not save '%s' provider. Internal error: %s", provider.Sber, err)
+	}
+	err = db.AddUserLastUpdateTime(login, provider.Sber, now)
+	if err != nil {
+		t.Errorf("Fail! Could not update '%s' provider. Internal error: %s", provider.Sber, err)
+	}
+	res, _ := db.GetUserLastUpdateTime(login, provider.Sber)
+	if res.Equal(now) {
+		t.Logf("Success! Expected %s, got %s", now, res)
+	} else {
+		t.Errorf("Fail! Saved and fetched time not match! Expected %s, got %s", now, res)
+	}
+
+	db.DeleteUserLastUpdateTime(login, provider.Sber)
+	res, _ = db.GetUserLastUpdateTime(login, provider.Sber)
+	if res.IsZero() {
+		t.Logf("Success! Expected zero time, got %s", res)
+	} else {
+		t.Errorf("Fail! Expected zero time, got %s", res)
+	}
+
+	db.DeleteUser(login)
+}
+
+func TestPortfolios(t *testing.T) {
+	uid, _ := db.AddUser("portfolio_login", "", "hash")
+
+	_, err := db.AddPortfolio("0", models.Portfolio{Name: "bp"})
+	expectedErrMsg := "could not add portfolio to unknown user"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Fail! Expected '%s' got '%v'", expectedErrMsg, err)
+	}
+
+	pid, err := db.AddPortfolio(uid, models.Portfolio{Name: "bp", Description: "Best Portfolio"})
+	if err != nil {
+		t.Errorf("Fail! Could not add portfolio. Internal error: %s", err)
+	}
+
+	modified, _ := db.UpdatePortfolio(uid, pid, models.Portfolio{Name: "bp2", Description: "Better Portfolio"})
+	p, err := db.GetPortfolio(uid, pid)
+	if err != nil {
+		t.Errorf("Fail! Could not get portfolio. Internal error: %s", err)
+	}
+	if modified && p.Name == "bp2" && p.Description == "Better Portfolio" {
+		t.Logf("Success! Expected %v, got %v", "bp2", p.Name)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", "bp2", p.Name)
+	}
+
+	_, err = db.GetPortfolio(uid, "999999")
+	expectedErrMsg = "No portfolio found with 999999 Id"
+	if err == nil || err.Error() != expectedErrMsg || errs.KindOf(err) != errs.NotFound {
+		t.Errorf("Fail! Expected '%s' got '%v'", expectedErrMsg, err)
+	} else {
+		t.Logf("Success! Expected '%s' got '%s'", expectedErrMsg, err)
+	}
+
+	db.AddPortfolio(uid, models.Portfolio{Name: "other"})
+	ps, _ := db.GetPortfolios(uid)
+	if len(ps) == 2 {
+		t.Logf("Success! Expected %v, got %v", 2, len(ps))
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", 2, len(ps))
+	}
+
+	n, _ := db.DeletePortfolios(uid)
+	if n == 2 {
+		t.Logf("Success! Expected %v, got %v", 2, n)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", 2, n)
+	}
+
+	db.DeleteUser("portfolio_login")
+}
+
+func TestOperations(t *testing.T) {
+	uid, _ := db.AddUser("operations_login", "", "hash")
+	pid, _ := db.AddPortfolio(uid, models.Portfolio{Name: "bp"})
+	db.AddInstruments(getinstrumentsForShares())
+	base, _ := time.Parse(time.RFC3339, "2019-01-24T07:00:00Z")
+
+	_, err := db.AddOperations("0", getOperationsForShares())
+	expectedErrMsg := "could not add operation to unknown portfolio"
+	if err == nil || err.Error() != expectedErrMsg || errs.KindOf(err) != errs.NotFound {
+		t.Errorf("Fail! Expected '%s' got '%v'", expectedErrMsg, err)
+	} else {
+		t.Logf("Success! Expected '%s' got '%s'", expectedErrMsg, err)
+	}
+
+	ids, err := db.AddOperations(pid, getOperationsForShares())
+	if err != nil || len(ids) != 6 {
+		t.Errorf("Fail! Could not add operations. Internal error: %v", err)
+	}
+
+	res, _ := db.GetOperations(pid, "", "", "", "")
+	if len(res) == 6 && res[0].OperationType == operation.PayIn {
+		t.Logf("Success! Expected '6' got '%d'", len(res))
+	} else {
+		t.Errorf("Fail! Expected '6' operations sorted by time, got '%v'", res)
+	}
+
+	res, _ = db.GetOperations(pid, "ticker", "FXGD", "", "")
+	if len(res) == 3 {
+		t.Logf("Success! Expected '3' got '%d'", len(res))
+	} else {
+		t.Errorf("Fail! Expected '3' got '%d'", len(res))
+	}
+
+	res, _ = db.GetOperations(pid, "", "", base.AddDate(0, 0, 2).Format(time.RFC3339), "")
+	if len(res) == 3 {
+		t.Logf("Success! Expected '3' got '%d'", len(res))
+	} else {
+		t.Errorf("Fail! Expected '3' got '%d'", len(res))
+	}
+
+	_, err = db.GetOperations(pid, "pid; drop table operations", "1", "", "")
+	if err == nil {
+		t.Errorf("Fail! Expected error for unknown filter key")
+	}
+
+	ops := getOperationsForShares()
+	ops[0].Source = provider.Tcs
+	ops[0].ExternalID = "12345"
+	// second identical operation of the batch is another fill, not a duplicate
+	dupIds, err := db.AddOperations(pid, []models.Operation{ops[0], ops[1], ops[1]})
+	if err == nil && len(dupIds) == 3 && dupIds[0] != "" && dupIds[1] == "" && dupIds[2] != "" {
+		t.Logf("Success! Expected duplicates to be skipped, got %v", dupIds)
+	} else {
+		t.Errorf("Fail! Expected duplicates to be skipped, got %v, %v", dupIds, err)
+	}
+	againIds, err := db.AddOperations(pid, []models.Operation{ops[0], ops[1], ops[1]})
+	if err == nil && len(againIds) == 3 && againIds[0] == "" && againIds[1] == "" && againIds[2] == "" {
+		t.Logf("Success! Expected repeated batch to be skipped, got %v", againIds)
+	} else {
+		t.Errorf("Fail! Expected repeated batch to be skipped, got %v, %v", againIds, err)
+	}
+	_, err = db.AddOperation(pid, ops[1])
+	expectedErrMsg = "operation already exists"
+	if err == nil || err.Error() != expectedErrMsg || errs.KindOf(err) != errs.Conflict {
+		t.Errorf("Fail! Expected '%s' got '%v'", expectedErrMsg, err)
+	} else {
+		t.Logf("Success! Expected '%s' got '%s'", expectedErrMsg, err)
+	}
+	db.DeleteOperation(pid, dupIds[0])
+	db.DeleteOperation(pid, dupIds[2])
+
+	unknown := ops[1]
+	unknown.ISIN = "XX0000000000"
+	_, err = db.AddOperation(pid, unknown)
+	if errs.KindOf(err) == errs.Validation {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected validation error for unknown ISIN, got %v", err)
+	}
+
+	op, err := db.GetOperation(pid, ids[1])
+	if err == nil && op.OperationID == ids[1] && op.ISIN == ops[1].ISIN && op.Volume == ops[1].Volume {
+		t.Logf("Success! Got operation %+v", op)
+	} else {
+		t.Errorf("Fail! Expected operation %v, got %+v, %v", ids[1], op, err)
+	}
+	op.Volume = 42
+	modified, err := db.UpdateOperation(pid, ids[1], op)
+	op, _ = db.GetOperation(pid, ids[1])
+	if err == nil && modified && op.Volume == 42 {
+		t.Logf("Success! Expected '42' got '%d'", op.Volume)
+	} else {
+		t.Errorf("Fail! Expected '42' got '%d', %v", op.Volume, err)
+	}
+	modified, err = db.UpdateOperation(pid, "999999", op)
+	if !modified && errs.KindOf(err) == errs.NotFound {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected not found error for unknown operation, got %v", err)
+	}
+	_, err = db.DeleteOperation(pid, "999999")
+	if errs.KindOf(err) == errs.NotFound {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected not found error for unknown operation, got %v", err)
+	}
+	_, err = db.GetOperation(pid, "999999")
+	if errs.KindOf(err) == errs.NotFound {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected not found error for unknown operation, got %v", err)
+	}
+	op.ISIN = "XX0000000000"
+	_, err = db.UpdateOperation(pid, ids[1], op)
+	if err != nil {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected error for unknown ISIN")
+	}
+
+	deleted, _ := db.DeleteOperation(pid, ids[0])
+	n, _ := db.DeleteOperations(pid)
+	if deleted && n == 5 {
+		t.Logf("Success! Expected '5' got '%d'", n)
+	} else {
+		t.Errorf("Fail! Expected '5' got '%d'", n)
+	}
+
+	db.DeleteUser("operations_login")
+	db.DeleteAllInstruments()
+}
+
+func TestInstrumentsAndPrices(t *testing.T) {
+	err := db.AddInstruments(getinstrumentsForShares())
+	if err != nil {
+		t.Errorf("Fail! Could not add instruments. Internal error: %s", err)
+	}
+
+	ins, _ := db.GetInstruments("code", "MOEX")
+	if len(ins) == 2 {
+		t.Logf("Success! Expected %v, got %v", 2, len(ins))
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", 2, len(ins))
+	}
+
+	ins, _ = db.GetInstruments("ticker", "FXGD")
+	if len(ins) == 1 && ins[0].ISIN == "IE00B8XB7377" && ins[0].Type == instrument.EtfGold {
+		t.Logf("Success! Expected %v, got %v", "IE00B8XB7377", ins[0].ISIN)
+	} else {
+		t.Errorf("Fail! Expected single FXGD instrument, got %v", ins)
+	}
+
+	err = db.AddPrices(getPricesForShres())
+	if err != nil {
+		t.Errorf("Fail! Could not add prices. Internal error: %s", err)
+	}
+
+	upd, _ := time.Parse(time.RFC3339, "2019-02-01T00:00:00Z")
+	ok, _ := db.SetInstrumentPriceUptdTime(ins[0].SecID, upd)
+	ins, _ = db.GetInstruments("isin", "IE00B8XB7377")
+	if ok && ins[0].PriceUptdTime.Equal(upd) {
+		t.Logf("Success! Expected %v, got %v", upd, ins[0].PriceUptdTime)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", upd, ins[0].PriceUptdTime)
+	}
+
+	prices, _ := db.GetPricesByIsin("IE00B8XB7377", "2019-01-25T00:00:00Z", "2019-01-28T00:00:00Z")
+	if len(prices) == 2 && prices[0].Price == 599.5 {
+		t.Logf("Success! Expected %v prices, got %v", 2, len(prices))
+	} else {
+		t.Errorf("Fail! Expected %v prices, got %v", 2, prices)
+	}
+
+	n, _ := db.DeletePrices("isin", "IE00B8XB7377")
+	if n == 6 {
+		t.Logf("Success! Expected %v, got %v", 6, n)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", 6, n)
+	}
+
+	db.DeleteAllPrices()
+	db.DeleteAllInstruments()
+}
+
+func TestListQueries(t *testing.T) {
+	uid, _ := db.AddUser("list_login", "", "hash")
+	pid, _ := db.AddPortfolio(uid, models.Portfolio{Name: "bp"})
+	db.AddInstruments(getinstrumentsForShares())
+	db.AddOperations(pid, getOperationsForShares())
+	db.AddPrices(getPricesForShres())
+
+	ops, total, err := db.FindOperations(pid, models.ListQuery{
+		Filters: map[string][]string{"type": {string(operation.Sell)}},
+		Sort:    []string{"-price"},
+		Limit:   2,
+	})
+	if err == nil && total == 3 && len(ops) == 2 && ops[0].Price == 4230 && ops[1].Price == 604.9 {
+		t.Logf("Success! Expected 2 of 3 sells sorted by price, got %v of %v", len(ops), total)
+	} else {
+		t.Errorf("Fail! Expected 2 of 3 sells sorted by price, got %v of %v (%v)", ops, total, err)
+	}
+
+	ops, total, _ = db.FindOperations(pid, models.ListQuery{
+		Filters: map[string][]string{"type": {string(operation.Buy), string(operation.Sell)}, "ticker": {"FXGD"}},
+		Limit:   1,
+		Offset:  1,
+	})
+	if total == 3 && len(ops) == 1 && ops[0].Price == 595 {
+		t.Logf("Success! Expected second FXGD operation, got %v", ops[0].Price)
+	} else {
+		t.Errorf("Fail! Expected second FXGD operation of 3, got %v of %v", ops, total)
+	}
+
+	_, _, err = db.FindOperations(pid, models.ListQuery{Sort: []string{"unknown"}})
+	if errs.KindOf(err) == errs.Validation {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected validation error for unknown sort key, got %v", err)
+	}
+
+	ins, total, err := db.FindInstruments(models.ListQuery{
+		Filters: map[string][]string{"type": {string(instrument.EtfGold), string(instrument.EtfStock)}},
+		Sort:    []string{"-ticker"},
+		Limit:   1,
+	})
+	if err == nil && total == 2 && len(ins) == 1 && ins[0].Ticker == "FXIT" {
+		t.Logf("Success! Expected FXIT first, got %v", ins[0].Ticker)
+	} else {
+		t.Errorf("Fail! Expected FXIT first of 2 instruments, got %v of %v (%v)", ins, total, err)
+	}
+
+	_, _, err = db.FindInstruments(models.ListQuery{Filters: map[string][]string{"unknown": {"value"}}})
+	if errs.KindOf(err) == errs.Validation {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected validation error for unknown filter, got %v", err)
+	}
+
+	prices, total, err := db.FindPrices(models.ListQuery{
+		Filters: map[string][]string{"ticker": {"FXGD"}},
+		From:    "2019-01-25T00:00:00Z",
+		Sort:    []string{"-price"},
+		Limit:   2,
+	})
+	if err == nil && total == 5 && len(prices) == 2 && prices[0].Price == 606 && prices[0].Date.Before(prices[1].Date) {
+		t.Logf("Success! Expected 2 of 5 prices sorted by price and date, got %v of %v", len(prices), total)
+	} else {
+		t.Errorf("Fail! Expected 2 of 5 prices sorted by price and date, got %v of %v (%v)", prices, total, err)
+	}
+
+	db.DeleteUser("list_login")
+	db.DeleteAllPrices()
+	db.DeleteAllInstruments()
+}
+
+func TestPortfolioGetShares(t *testing.T) {
+	uid, _ := db.AddUser("shares_login", "", "hash")
+	pid, _ := db.AddPortfolio(uid, models.Portfolio{Name: "bp"})
+	db.AddInstruments(getinstrumentsForShares())
+	db.AddOperations(pid, getOperationsForShares())
+	db.AddPrices(getPricesForShres())
+
+	sh, err := db.GetShares(pid, "2019-01-26T07:00:00Z")
+	if len(sh) == 3 {
+		t.Logf("Success! Expected %v, got %v", 3, len(sh))
+
+		for _, s := range sh {
+			switch s.Ticker {
+			case "RUB":
+				if s.Price == 72177 {
+					t.Logf("Success! Expected %v balance on 2019-01-26, got %v", 72177, s.Price)
+				} else {
+					t.Errorf("Fail! Expected %v balance on 2019-01-26, got %v", 72177, s.Price)
+				}
+			case "FXIT":
+				if s.Price == 4265 {
+					t.Logf("Success! Expected %v FXIT price on 2019-01-26, got %v", 4265, s.Price)
+				} else {
+					t.Errorf("Fail! Expected %v FXIT price on 2019-01-26, got %v", 4265, s.Price)
+				}
+			case "FXGD":
+				if s.Price == 599.5 {
+					t.Logf("Success! Expected %v FXGD price on 2019-01-26, got %v", 599.5, s.Price)
+				} else {
+					t.Errorf("Fail! Expected %v FXGD price on 2019-01-26, got %v", 599.5, s.Price)
+				}
+			default:
+				t.Errorf("Fail! Expected FXGD, FXIT or RUB, got nothing.")
+			}
+		}
+	} else {
+		t.Errorf("Fail! Expected %v securities on 2019-01-26, got %v (%v)", 3, len(sh), err)
+	}
+
+	// coupon increases cash balance
+	rub := func(shares []models.Share) float64 {
+		for _, s := range shares {
+			if s.ISIN == "RUB" {
+				return s.Price
+			}
+		}
+		return 0
+	}
+	sh, _ = db.GetShares(pid, "2019-02-26T07:00:00Z")
+	before := rub(sh)
+	coupon, _ := time.Parse(time.RFC3339, "2019-02-27T07:00:00Z")
+	db.AddOperation(pid, models.Operation{Currency: currency.RUB, Price: 50, Volume: 1, Ticker: "FXIT", ISIN: "IE00BD3QJ757", DateTime: coupon, OperationType: operation.Coupon})
+	sh, _ = db.GetShares(pid, "2019-02-28T07:00:00Z")
+	if after := rub(sh); after == before+50 {
+		t.Logf("Success! Expected %v balance after coupon, got %v", before+50, after)
+	} else {
+		t.Errorf("Fail! Expected %v balance after coupon, got %v", before+50, after)
+	}
+
+	db.DeleteUser("shares_login")
+	db.DeleteAllPrices()
+	db.DeleteAllInstruments()
+}
+
+func TestTcsTokenStorage(t *testing.T) {
+	token := "test_token"
+	err := db.AddTcsToken(token)
+	if err != nil {
+		t.Errorf("Fail! error during save token: %v", err)
+	}
+	res, _ := db.GetTcsToken()
+	if res == token {
+		t.Logf("Success! Expected %v, got %v", token, res)
+	} else {
+		t.Errorf("Fail! Saved and fetched tokens not match! Expected %v, got %v", token, res)
+	}
+
+	db.DeleteTcsToken()
+	res, _ = db.GetTcsToken()
+	if res == "" {
+		t.Logf("Success! Expected empty string, got %v", res)
+	} else {
+		t.Errorf("Fail! Expected empty string, got %v", res)
+	}
+}
+
+func getOperationsForShares() []models.Operation {
+	base, _ := time.Parse("2006-01-02T15:04:05Z07:00", "2019-01-24T07:00:00Z")
+	return []models.Operation{
+		{Currency: currency.RUB, Price: 1, Volume: 100000, Ticker: "RUB", ISIN: "RUB", DateTime: base, OperationType: operation.PayIn},
+		{Currency: currency.RUB, Price: 4195, Volume: 1, Ticker: "FXIT", ISIN: "IE00BD3QJ757", DateTime: base.AddDate(0, 0, 1), OperationType: operation.Buy},
+		{Currency: currency.RUB, Price: 590.7, Volume: 40, Ticker: "FXGD", ISIN: "IE00B8XB7377", DateTime: base.AddDate(0, 0, 1), OperationType: operation.Buy},
+		{Currency: currency.RUB, Price: 595, Volume: 20, Ticker: "FXGD", ISIN: "IE00B8XB7377", DateTime: base.AddDate(0, 0, 5), OperationType: operation.Sell},
+		{Currency: currency.RUB, Price: 4230, Volume: 1, Ticker: "FXIT", ISIN: "IE00BD3QJ757", DateTime: base.AddDate(0, 0, 5), OperationType: operation.Sell},
+		{Currency: currency.RUB, Price: 604.9, Volume: 10, Ticker: "FXGD", ISIN: "IE00B8XB7377", DateTime: base.AddDate(0, 0, 6), OperationType: operation.Sell},
+	}
+}
+
+func getPricesForShres() []models.Price {
+	base, _ := time.Parse("2006-01-02T15:04:05Z07:00", "2019-01-24T07:00:00Z")
+	return []models.Price{
+		{Price: 4189, Volume: 1569, Date: base, ISIN: "IE00BD3QJ757"},
+		{Price: 4265, Volume: 1513, Date: base.AddDate(0, 0, 1), ISIN: "IE00BD3QJ757"},
+		{Price: 4228, Volume: 1110, Date: base.AddDate(0, 0, 4), ISIN: "IE00BD3QJ757"},
+		{Price: 4202, Volume: 3596, Date: base.AddDate(0, 0, 5), ISIN: "IE00BD3QJ757"},
+		{Price: 4235, Volume: 3626, Date: base.AddDate(0, 0, 6), ISIN: "IE00BD3QJ757"},
+		{Price: 4275, Volume: 6190, Date: base.AddDate(0, 0, 7), ISIN: "IE00BD3QJ757"},
+		{Price: 590.5, Volume: 6521, Date: base, ISIN: "IE00B8XB7377"},
+		{Price: 599.5, Volume: 10395, Date: base.AddDate(0, 0, 1), ISIN: "IE00B8XB7377"},
+		{Price: 603.5, Volume: 12127, Date: base.AddDate(0, 0, 4), ISIN: "IE00B8XB7377"},
+		{Price: 606, Volume: 16404, Date: base.AddDate(0, 0, 5), ISIN: "IE00B8XB7377"},
+		{Price: 605.5, Volume: 14652, Date: base.AddDate(0, 0, 6), ISIN: "IE00B8XB7377"},
+		{Price: 606, Volume: 14653, Date: base.AddDate(0, 0, 7), ISIN: "IE00B8XB7377"},
+	}
+}
+
+func getinstrumentsForShares() []models.Instrument {
+	return []models.Instrument{
+		{FIGI: "BBG005DXDPK9", ISIN: "IE00B8XB7377", Ticker: "FXGD", Name: "FinEx Золото", Currency: currency.RUB, Type: instrument.EtfGold, Exchange: exchange.MOEX},
+		{FIGI: "BBG005HLTYH9", ISIN: "IE00BD3QJ757", Ticker: "FXIT", Name: "FinEx Акции компаний IT-сектора США", Currency: currency.RUB, Type: instrument.EtfStock, Exchange: exchange.MOEX},
+		{FIGI: "RUB", ISIN: "RUB", Ticker: "RUB", Name: "Российский рубль", Currency: currency.RUB, Type: instrument.Currency},
+	}
+}
+
+func TestRates(t *testing.T) {
+	d1 := time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(2020, 1, 16, 0, 0, 0, 0, time.UTC)
+	err := db.AddRates([]models.Rate{
+		{Currency: currency.USD, Date: d1, Rate: 61.414},
+		{Currency: currency.EUR, Date: d1, Rate: 68.363},
+		{Currency: currency.USD, Date: d2, Rate: 1},
+	})
+	if err != nil {
+		t.Errorf("Fail! Error during add rates: %v", err)
+	}
+	// existing rate must be overwritten
+	err = db.AddRates([]models.Rate{{Currency: currency.USD, Date: d2, Rate: 61.4328}})
+	if err != nil {
+		t.Errorf("Fail! Error during overwrite rates: %v", err)
+	}
+
+	res, _ := db.GetRates(currency.USD, "", "")
+	if len(res) == 2 && res[0].Rate == 61.414 && res[1].Rate == 61.4328 && res[1].Date.Equal(d2) {
+		t.Logf("Success! Got expected rates %v", res)
+	} else {
+		t.Errorf("Fail! Unexpected rates %v", res)
+	}
+	res, _ = db.GetRates("", "2020-01-15T00:00:00Z", "2020-01-15T00:00:00Z")
+	if len(res) == 2 {
+		t.Logf("Success! Expected %v, got %v", 2, len(res))
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", 2, len(res))
+	}
+
+	num, _ := db.DeleteRates("")
+	if num == 3 {
+		t.Logf("Success! Expected %v, got %v", 3, num)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", 3, num)
+	}
+}
+
+func TestRateUptdTime(t *testing.T) {
+	d := time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC)
+	ok, err := db.SetRateUptdTime(currency.USD, d)
+	if !ok || err != nil {
+		t.Errorf("Fail! Error during set update time: %v", err)
+	}
+	res, _ := db.GetRateUptdTime(currency.USD)
+	if res.Equal(d) {
+		t.Logf("Success! Expected %v, got %v", d, res)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", d, res)
+	}
+	res, _ = db.GetRateUptdTime(currency.EUR)
+	if res.IsZero() {
+		t.Logf("Success! Expected zero time, got %v", res)
+	} else {
+		t.Errorf("Fail! Expected zero time, got %v", res)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "pmanager")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "p_manager_reopen.db")
+
+	for i := 0; i < 2; i++ {
+		d := Db{}
+		err := d.Init(Config{Path: path})
+		if err != nil {
+			t.Fatalf("Fail! Error during open database %v time: %v", i+1, err)
+		}
+		d.Close()
+	}
+	t.Logf("Success! Database reopened without errors")
+}
+
+func TestFingerprintBackfill(t *testing.T) {
+	uid, _ := db.AddUser("backfill_login", "", "hash")
+	pid, _ := db.AddPortfolio(uid, models.Portfolio{Name: "bp"})
+	db.AddInstruments(getinstrumentsForShares())
+
+	ops := getOperationsForShares()
+	ops = append(ops, ops[2])
+	ids, err := db.AddOperations(pid, ops)
+	if err != nil || len(ids) != 7 || ids[6] == "" {
+		t.Fatalf("Fail! Could not add operations %v: %v", ids, err)
+	}
+
+	// operations stored before fingerprints were introduced have none
+	db.connection.ExecContext(db.context, "update operations set fingerprint = null where pid = ?1;", pid)
+	err = backfillFingerprints(db.context, db.connection)
+	if err != nil {
+		t.Fatalf("Fail! Could not backfill fingerprints: %v", err)
+	}
+
+	expected := models.Fingerprints(ops)
+	for i, id := range ids {
+		var fp string
+		db.connection.QueryRowContext(db.context, "select ifnull(fingerprint,'') from operations where id = ?1;", id).Scan(&fp)
+		if fp == expected[i] {
+			t.Logf("Success! Expected %v got %v", expected[i], fp)
+		} else {
+			t.Errorf("Fail! Expected %v got %v", expected[i], fp)
+		}
+	}
+
+	ids, _ = db.AddOperations(pid, ops)
+	if len(ids) == 7 && ids[0] == "" && ids[6] == "" {
+		t.Logf("Success! Expected backfilled operations to be skipped, got %v", ids)
+	} else {
+		t.Errorf("Fail! Expected backfilled operations to be skipped, got %v", ids)
+	}
+
+	db.DeleteUser("backfill_login")
+	db.DeleteAllInstruments()
+}
+
+func TestSchedules(t *testing.T) {
+	prices := models.Schedule{ID: "prices", Job: "prices", Cron: "0 3 * * *", Enabled: true}
+	gmail := models.Schedule{ID: "gmail:test", Job: "gmail", Cron: "0 5 * * *", Login: "test", PortfolioID: "1"}
+	if err := db.SaveSchedule(prices); err != nil {
+		t.Errorf("Fail! Error during save schedule: %v", err)
+	}
+	db.SaveSchedule(gmail)
+
+	prices.LastRun = time.Date(2020, 6, 6, 3, 0, 0, 0, time.UTC)
+	prices.LastStatus = "error"
+	prices.LastError = "test error"
+	db.SaveSchedule(prices)
+
+	res, _ := db.GetSchedules()
+	if len(res) == 2 && res[0].ID == gmail.ID && res[0].Login == gmail.Login && res[0].PortfolioID == gmail.PortfolioID &&
+		res[1].ID == prices.ID && res[1].Cron == prices.Cron && res[1].Enabled && res[1].LastRun.Equal(prices.LastRun) && res[1].LastError == prices.LastError {
+		t.Logf("Success! Expected %v, got %v", []models.Schedule{gmail, prices}, res)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", []models.Schedule{gmail, prices}, res)
+	}
+
+	ok, _ := db.DeleteSchedule(gmail.ID)
+	res, _ = db.GetSchedules()
+	if ok && len(res) == 1 {
+		t.Logf("Success! Expected %v schedules, got %v", 1, len(res))
+	} else {
+		t.Errorf("Fail! Expected %v schedules, got %v", 1, len(res))
+	}
+	db.DeleteSchedule(prices.ID)
+}
+
+func TestJobs(t *testing.T) {
+	started := time.Date(2020, 6, 6, 3, 0, 0, 0, time.UTC)
+	j := models.Job{Type: job.Prices, Login: "test", State: job.Running, Started: started, Total: 2}
+	id, err := db.AddJob(j)
+	if err != nil || id == "" {
+		t.Errorf("Fail! Error during add job: %v", err)
+	}
+
+	j.ID = id
+	j.Processed = 2
+	j.State = job.Completed
+	j.Finished = started.Add(time.Minute)
+	j.Errors = []models.JobError{{Item: "IDCC", Error: "no price provider"}}
+	ok, err := db.UpdateJob(j)
+	if !ok || err != nil {
+		t.Errorf("Fail! Error during update job: %v", err)
+	}
+
+	res, err := db.GetJob(id)
+	if err == nil && res.ID == id && res.Type == job.Prices && res.Login == "test" && res.State == job.Completed &&
+		res.Started.Equal(started) && res.Finished.Equal(j.Finished) && res.Total == 2 && res.Processed == 2 &&
+		len(res.Errors) == 1 && res.Errors[0] == j.Errors[0] {
+		t.Logf("Success! Expected %v, got %v", j, res)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v (%v)", j, res, err)
+	}
+
+	// system jobs have no user and running jobs have no finish time
+	id, _ = db.AddJob(models.Job{Type: job.Rates, State: job.Running, Started: started})
+	res, err = db.GetJob(id)
+	if err == nil && res.Login == "" && res.Finished.IsZero() && len(res.Errors) == 0 {
+		t.Logf("Success! Got expected job %v", res)
+	} else {
+		t.Errorf("Fail! Unexpected job %v (%v)", res, err)
+	}
+}
+
+func TestSecurityEvents(t *testing.T) {
+	d1 := time.Date(2020, 7, 22, 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC)
+	err := db.AddSecurityEvents([]models.SecurityEvent{
+		{ISIN: "RU000A0JSMA2", Type: event.Coupon, Date: d2, Value: 1, Currency: currency.RUB},
+		{ISIN: "RU000A0JSMA2", Type: event.Coupon, Date: d1, Value: 37.9, Currency: currency.RUB},
+		{ISIN: "RU0009029540", Type: event.Dividend, Date: d1, Value: 18.7, Currency: currency.RUB},
+	})
+	if err != nil {
+		t.Errorf("Fail! Error during add events: %v", err)
+	}
+	// existing event must be overwritten
+	err = db.AddSecurityEvents([]models.SecurityEvent{{ISIN: "RU000A0JSMA2", Type: event.Coupon, Date: d2, Value: 37.9, Currency: currency.RUB}})
+	if err != nil {
+		t.Errorf("Fail! Error during overwrite events: %v", err)
+	}
+
+	res, _ := db.GetSecurityEvents("RU000A0JSMA2", "", "")
+	if len(res) == 2 && res[0].Date.Equal(d1) && res[1].Value == 37.9 && res[1].Type == event.Coupon && res[1].Currency == currency.RUB {
+		t.Logf("Success! Got expected events %+v", res)
+	} else {
+		t.Errorf("Fail! Unexpected events %+v", res)
+	}
+
+	res, _ = db.GetSecurityEvents("", "", d1.Format(time.RFC3339))
+	if len(res) == 2 && res[0].ISIN == "RU0009029540" {
+		t.Logf("Success! Expected '2' got '%d'", len(res))
+	} else {
+		t.Errorf("Fail! Expected 2 events till %v, got %+v", d1, res)
+	}
+
+	n, _ := db.DeleteSecurityEvents("RU000A0JSMA2")
+	if n == 2 {
+		t.Logf("Success! Expected '2' got '%d'", n)
+	} else {
+		t.Errorf("Fail! Expected '2' got '%d'", n)
+	}
+	n, _ = db.DeleteSecurityEvents("")
+	if n == 1 {
+		t.Logf("Success! Expected '1' got '%d'", n)
+	} else {
+		t.Errorf("Fail! Expected '1' got '%d'", n)
+	}
+}
+
+func TestCorporateActions(t *testing.T) {
+	d1 := time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(2021, 1, 20, 0, 0, 0, 0, time.UTC)
+	err := db.AddCorporateActions([]models.CorporateAction{
+		{ISIN: "US0378331005", Type: action.Rename, Date: d2, RatioFrom: 1, RatioTo: 1},
+		{ISIN: "US0378331005", Type: action.Split, Date: d1, RatioFrom: 1, RatioTo: 4},
+		{ISIN: "RU000A0JP5V6", Type: action.Conversion, Date: d1, RatioFrom: 1, RatioTo: 2, NewISIN: "RU000A0JP5V7", NewTicker: "VTBR"},
+	})
+	if err != nil {
+		t.Errorf("Fail! Error during add corporate actions: %v", err)
+	}
+	// existing action must be overwritten
+	err = db.AddCorporateActions([]models.CorporateAction{{ISIN: "US0378331005", Type: action.Rename, Date: d2, RatioFrom: 1, RatioTo: 1, NewTicker: "APPL"}})
+	if err != nil {
+		t.Errorf("Fail! Error during overwrite corporate actions: %v", err)
+	}
+
+	res, _ := db.GetCorporateActions("US0378331005")
+	if len(res) == 2 && res[0].Date.Equal(d1) && res[0].RatioTo == 4 && res[1].Type == action.Rename && res[1].NewTicker == "APPL" && res[1].NewISIN == "" {
+		t.Logf("Success! Got expected corporate actions %+v", res)
+	} else {
+		t.Errorf("Fail! Unexpected corporate actions %+v", res)
+	}
+
+	res, _ = db.GetCorporateActions("")
+	if len(res) == 3 && res[0].ISIN == "RU000A0JP5V6" && res[0].NewISIN == "RU000A0JP5V7" && res[0].NewTicker == "VTBR" {
+		t.Logf("Success! Expected '3' got '%d'", len(res))
+	} else {
+		t.Errorf("Fail! Expected 3 corporate actions, got %+v", res)
+	}
+
+	n, _ := db.DeleteCorporateActions("US0378331005")
+	if n == 2 {
+		t.Logf("Success! Expected '2' got '%d'", n)
+	} else {
+		t.Errorf("Fail! Expected '2' got '%d'", n)
+	}
+	n, _ = db.DeleteCorporateActions("")
+	if n == 1 {
+		t.Logf("Success! Expected '1' got '%d'", n)
+	} else {
+		t.Errorf("Fail! Expected '1' got '%d'", n)
+	}
+}
@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/kaseat/pManager/models/provider"
+	"github.com/kaseat/pManager/storage/errs"
+)
+
+// AddUserLastUpdateTime saves last date when specified provider made sync
+func (db Db) AddUserLastUpdateTime(login string, provider provider.Type, date time.Time) error {
+	pvds := getSyncPviderTypeByName()
+	query := `insert into user_sync (uid,provider_id,last_sync) select u.id,?2,?3 from users u where login = ?1
+		on conflict (uid,provider_id) do update set last_sync = excluded.last_sync;`
+	r, err := db.connection.ExecContext(db.context, query, login, pvds[provider], date.UTC())
+	if err != nil {
+		return err
+	}
+	if ok, _ := hasAffected(r); !ok {
+		return errs.New(errs.NotFound, "could not fint user with login: %s", login)
+	}
+	return nil
+}
+
+// GetUserLastUpdateTime receives last date when specified provider made sync
+func (db Db) GetUserLastUpdateTime(login string, provider provider.Type) (time.Time, error) {
+	var result time.Time
+	pvds := getSyncPviderTypeByName()
+	query := "select last_sync from user_sync us inner join users u on u.id = us.uid where u.login = ?1 and us.provider_id = ?2;"
+	err := db.connection.QueryRowContext(db.context, query, login, pvds[provider]).Scan(&result)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return result, nil
+}
+
+// DeleteUserLastUpdateTime removes last date when specified provider made sync
+func (db Db) DeleteUserLastUpdateTime(login string, provider provider.Type) error {
+	pvds := getSyncPviderTypeByName()
+	query := "delete from user_sync where uid in (select id from users where login = ?1) and provider_id = ?2;"
+	_, err := db.connection.ExecContext(db.context, query, login, pvds[provider])
+	return err
+}
+
+func getSyncPviderTypeByName() map[provider.Type]int {
+	return map[provider.Type]int{
+		provider.Sber: 1,
+		provider.Tcs:  2,
+		provider.Vtb:  3,
+	}
+}
@@ -0,0 +1,18 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Config represents database configuration
+type Config struct {
+	// Path is a path to database file. Use ":memory:" for throwaway database
+	Path string `json:"path"`
+}
+
+// Db represents storage
+type Db struct {
+	connection *sql.DB
+	context    context.Context
+}
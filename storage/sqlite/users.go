@@ -0,0 +1,174 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/storage/errs"
+	"golang.org/x/oauth2"
+)
+
+// AddUser saves user and password hash to storage
+func (db Db) AddUser(login, email, hash string) (string, error) {
+	var mail *string
+	if email != "" {
+		mail = &email
+	}
+	query := "insert into users (login,hash,role_id,email) values (?1,?2,2,?3);"
+	r, err := db.connection.ExecContext(db.context, query, login, hash, mail)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return "", errs.New(errs.Conflict, "User with this login already exists")
+		}
+		return "", err
+	}
+	id, err := r.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// GetUserByLogin gets user by login
+func (db Db) GetUserByLogin(login string) (models.User, error) {
+	result := models.User{}
+	var id int
+	var role int
+	var email *string
+
+	query := "select id,role_id,email from users where login = ?1;"
+	err := db.connection.QueryRowContext(db.context, query, login).Scan(&id, &role, &email)
+	if err == sql.ErrNoRows {
+		return result, errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if email != nil {
+		result.Email = *email
+	}
+
+	result.Login = login
+	result.UserID = strconv.Itoa(id)
+	result.IsAdmin = role == 1
+	return result, nil
+}
+
+// AddUserState adds state to user
+func (db Db) AddUserState(login string, state string) error {
+	query := "update users set g_sync_state = ?1 where login = ?2;"
+	_, err := db.connection.ExecContext(db.context, query, state, login)
+	return err
+}
+
+// GetUserState gets user's state
+func (db Db) GetUserState(login string) (string, error) {
+	var state *string
+	query := "select g_sync_state from users where login = ?1;"
+	err := db.connection.QueryRowContext(db.context, query, login).Scan(&state)
+	if err == sql.ErrNoRows {
+		return "", errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
+	if err != nil {
+		return "", err
+	}
+	if state == nil {
+		return "", nil
+	}
+	return *state, nil
+}
+
+// AddUserToken adds oauth2 token to user
+func (db Db) AddUserToken(state string, token *oauth2.Token) error {
+	bytes, err := json.Marshal(*token)
+	if err != nil {
+		return err
+	}
+	query := "update users set g_sync_token = ?1 where g_sync_state = ?2;"
+	_, err = db.connection.ExecContext(db.context, query, string(bytes), state)
+	return err
+}
+
+// GetUserToken gets user's oauth2 token
+func (db Db) GetUserToken(login string) (oauth2.Token, error) {
+	token := oauth2.Token{}
+	var raw *string
+	query := "select g_sync_token from users where login = ?1;"
+	err := db.connection.QueryRowContext(db.context, query, login).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return token, errs.New(errs.NotFound, "could not find user with login: %s", login)
+	}
+	if err != nil {
+		return token, err
+	}
+	if raw == nil {
+		return token, errs.New(errs.NotFound, "no token found for user with login: %s", login)
+	}
+	err = json.Unmarshal([]byte(*raw), &token)
+	return token, err
+}
+
+// GetUserPassword gets password hash from storage
+func (db Db) GetUserPassword(login string) (string, error) {
+	hash := ""
+	query := "select hash from users where login = ?1;"
+	err := db.connection.QueryRowContext(db.context, query, login).Scan(&hash)
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// UpdateUserPassword updates user password
+func (db Db) UpdateUserPassword(login, hash string) (bool, error) {
+	query := "update users set hash = ?1 where login = ?2;"
+	r, err := db.connection.ExecContext(db.context, query, hash, login)
+	if err != nil {
+		return false, err
+	}
+	return hasAffected(r)
+}
+
+// DeleteUser removes password hash from storage
+// Also removes all portfolios associated with this user
+// Also removes all operations associated with portfolios of this user
+func (db Db) DeleteUser(login string) (bool, error) {
+	c, err := db.connection.BeginTx(db.context, nil)
+	if err != nil {
+		return false, err
+	}
+	queries := []string{
+		"delete from operations where pid in (select p.id from portfolios p inner join users u on u.id = p.uid where u.login = ?1);",
+		"delete from portfolios where uid in (select id from users where login = ?1);",
+		"delete from user_sync where uid in (select id from users where login = ?1);",
+	}
+	for _, query := range queries {
+		_, err = c.ExecContext(db.context, query, login)
+		if err != nil {
+			c.Rollback()
+			return false, err
+		}
+	}
+	query := "delete from users where login = ?1;"
+	r, err := c.ExecContext(db.context, query, login)
+	if err != nil {
+		c.Rollback()
+		return false, err
+	}
+	err = c.Commit()
+	if err != nil {
+		return false, err
+	}
+	return hasAffected(r)
+}
+
+func hasAffected(r sql.Result) (bool, error) {
+	n, err := r.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,41 @@
+package cbr
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaseat/pManager/config"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var baseURL = config.Default().Sync.Cbr.URL
+
+// Configure sets Central Bank of Russia API settings
+func Configure(c config.Provider) {
+	baseURL = c.URL
+}
+
+// rates are published in Moscow time
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+func parseRate(value, nominal string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(value), ",", ".", 1), 64)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(nominal))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid nominal '%s'", nominal)
+	}
+	return v / float64(n), nil
+}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	if strings.ToLower(charset) == "windows-1251" {
+		return charmap.Windows1251.NewDecoder().Reader(input), nil
+	}
+	return nil, fmt.Errorf("unsupported charset %s", charset)
+}
@@ -0,0 +1,131 @@
+package cbr
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/storage"
+)
+
+var isSync int32
+
+// CBR internal currency codes
+var codes = map[currency.Type]string{
+	currency.USD: "R01235",
+	currency.EUR: "R01239",
+}
+
+type valCursDynamic struct {
+	Record []struct {
+		Date    string `xml:"Date,attr"`
+		Nominal string `xml:"Nominal"`
+		Value   string `xml:"Value"`
+	} `xml:"Record"`
+}
+
+// Sync starts CBR rates sync. Rates are fetched from the day after
+// the last update till tomorrow, since CBR publishes rates in advance.
+// Failures of single currencies are recorded to the job tracker
+func Sync(tr *jobs.Tracker, httpClient *http.Client) error {
+	if !atomic.CompareAndSwapInt32(&isSync, 0, 1) {
+		err := errors.New("CBR sync already in process")
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync rates:", err)
+		return err
+	}
+	defer func() {
+		atomic.StoreInt32(&isSync, 0)
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "End sync CBR")
+	}()
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Begin sync CBR")
+
+	s := storage.GetStorage()
+	to := today().AddDate(0, 0, 1)
+	tr.SetTotal(len(codes))
+	for curr, code := range codes {
+		tr.Done(string(curr), syncCurrency(s, httpClient, curr, code, to))
+	}
+	return nil
+}
+
+func syncCurrency(s storage.Db, httpClient *http.Client, curr currency.Type, code string, to time.Time) error {
+	from, err := s.GetRateUptdTime(curr)
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error get", curr, "rates update time:", err)
+		return err
+	}
+	if from.IsZero() {
+		from = time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if from.After(to) {
+		return nil
+	}
+
+	rates, err := fetchDynamic(httpClient, curr, code, from, to)
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error fetch", curr, "rates:", err)
+		return err
+	}
+	if len(rates) == 0 {
+		return nil
+	}
+
+	if err = s.AddRates(rates); err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error add", len(rates), curr, "rates to storage:", err)
+		return err
+	}
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Success add", len(rates), curr, "rates to storage")
+	_, err = s.SetRateUptdTime(curr, rates[len(rates)-1].Date.AddDate(0, 0, 1))
+	return err
+}
+
+// IsSyncing checks whether sync is in process
+func IsSyncing() bool {
+	return atomic.LoadInt32(&isSync) == 1
+}
+
+func fetchDynamic(client *http.Client, curr currency.Type, code string, from, to time.Time) ([]models.Rate, error) {
+	url := fmt.Sprintf("%s/XML_dynamic.asp?date_req1=%s&date_req2=%s&VAL_NM_RQ=%s",
+		baseURL, from.Format("02/01/2006"), to.Format("02/01/2006"), code)
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CBR responded with status %d", resp.StatusCode)
+	}
+
+	var curs valCursDynamic
+	decoder := xml.NewDecoder(resp.Body)
+	decoder.CharsetReader = charsetReader
+	err = decoder.Decode(&curs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Rate, 0, len(curs.Record))
+	for _, r := range curs.Record {
+		date, err := time.Parse("02.01.2006", r.Date)
+		if err != nil {
+			return nil, err
+		}
+		rate, err := parseRate(r.Value, r.Nominal)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models.Rate{Currency: curr, Date: date, Rate: rate})
+	}
+	return result, nil
+}
+
+func today() time.Time {
+	y, m, d := time.Now().In(moscow).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
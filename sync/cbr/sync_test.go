@@ -0,0 +1,71 @@
+package cbr
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/config"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/storage"
+)
+
+const dynamicResponse = `<?xml version="1.0" encoding="windows-1251"?>
+<ValCurs ID="%[1]s" DateRange1="01.01.2019" DateRange2="10.01.2019" name="Foreign Currency Market Dynamic">
+<Record Date="09.01.2019" Id="%[1]s"><Nominal>1</Nominal><Value>%[2]s</Value></Record>
+<Record Date="10.01.2019" Id="%[1]s"><Nominal>1</Nominal><Value>%[3]s</Value></Record>
+</ValCurs>`
+
+func TestSync(t *testing.T) {
+	requests := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query().Get("date_req1"))
+		switch r.URL.Query().Get("VAL_NM_RQ") {
+		case "R01235":
+			fmt.Fprintf(w, dynamicResponse, "R01235", "67,0795", "66,8164")
+		case "R01239":
+			fmt.Fprintf(w, dynamicResponse, "R01239", "76,6214", "76,8017")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	Configure(config.Provider{URL: srv.URL})
+	defer Configure(config.Default().Sync.Cbr)
+	storage.SwitchStorage(storage.Memory)
+	s := storage.GetStorage()
+
+	Sync(nil, srv.Client())
+
+	rates, _ := s.GetRates(currency.USD, "", "")
+	if len(rates) == 2 && rates[1].Rate == 66.8164 && rates[1].Date.Equal(time.Date(2019, 1, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Logf("Success! Got expected rates %v", rates)
+	} else {
+		t.Errorf("Fail! Unexpected rates %v", rates)
+	}
+	rates, _ = s.GetRates(currency.EUR, "", "")
+	if len(rates) == 2 && rates[0].Rate == 76.6214 {
+		t.Logf("Success! Got expected rates %v", rates)
+	} else {
+		t.Errorf("Fail! Unexpected rates %v", rates)
+	}
+
+	upd, _ := s.GetRateUptdTime(currency.USD)
+	expected := time.Date(2019, 1, 11, 0, 0, 0, 0, time.UTC)
+	if upd.Equal(expected) {
+		t.Logf("Success! Expected %v, got %v", expected, upd)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", expected, upd)
+	}
+
+	// next sync continues from last update
+	requests = requests[:0]
+	Sync(nil, srv.Client())
+	if len(requests) == 2 && requests[0] == "11/01/2019" && requests[1] == "11/01/2019" {
+		t.Logf("Success! Got expected requests %v", requests)
+	} else {
+		t.Errorf("Fail! Unexpected requests %v", requests)
+	}
+}
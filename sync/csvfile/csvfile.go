@@ -0,0 +1,296 @@
+package csvfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+// Columns maps operation fields to CSV header captions.
+// Empty caption means column is absent
+type Columns struct {
+	Date     string `json:"date" example:"date"`
+	Type     string `json:"type" example:"type"`
+	Ticker   string `json:"ticker" example:"ticker"`
+	ISIN     string `json:"isin" example:"isin"`
+	FIGI     string `json:"figi" example:"figi"`
+	Price    string `json:"price" example:"price"`
+	Volume   string `json:"volume" example:"vol"`
+	Currency string `json:"currency" example:"currency"`
+}
+
+// Mapping describes layout of operations CSV file
+type Mapping struct {
+	// Delimiter separates fields, comma by default
+	Delimiter string `json:"delimiter" example:";"`
+	// DecimalSeparator separates fractional part of numbers, dot by default
+	DecimalSeparator string `json:"decimalSeparator" example:","`
+	// DateFormat is Go time layout, RFC3339 by default
+	DateFormat string `json:"dateFormat" example:"02.01.2006 15:04:05"`
+	// Types maps CSV operation type values to operation types.
+	// Operation type names are used by default
+	Types map[string]operation.Type `json:"types"`
+	// Currency is used when there is no currency column
+	Currency currency.Type `json:"currency" example:"RUB"`
+	Columns  Columns       `json:"columns"`
+}
+
+// DefaultMapping returns mapping which exports operations losslessly
+func DefaultMapping() Mapping {
+	return Mapping{
+		Delimiter:        ",",
+		DecimalSeparator: ".",
+		DateFormat:       time.RFC3339Nano,
+		Columns: Columns{
+			Date:     "date",
+			Type:     "type",
+			Ticker:   "ticker",
+			ISIN:     "isin",
+			FIGI:     "figi",
+			Price:    "price",
+			Volume:   "vol",
+			Currency: "currency",
+		},
+	}
+}
+
+// withDefaults fills unspecified mapping settings with default values
+func (m Mapping) withDefaults() Mapping {
+	d := DefaultMapping()
+	if m.Delimiter == "" {
+		m.Delimiter = d.Delimiter
+	}
+	if m.DecimalSeparator == "" {
+		m.DecimalSeparator = d.DecimalSeparator
+	}
+	if m.DateFormat == "" {
+		m.DateFormat = d.DateFormat
+	}
+	if m.Columns == (Columns{}) {
+		m.Columns = d.Columns
+	}
+	return m
+}
+
+// Validate checks whether mapping is complete
+func (m Mapping) Validate() error {
+	m = m.withDefaults()
+	if utf8.RuneCountInString(m.Delimiter) != 1 {
+		return errors.New("Invalid delimiter. Expected single character")
+	}
+	if m.Columns.Date == "" || m.Columns.Type == "" || m.Columns.Price == "" || m.Columns.Volume == "" {
+		return errors.New("Date, type, price and volume columns must be specified")
+	}
+	if m.Columns.Ticker == "" && m.Columns.ISIN == "" {
+		return errors.New("Ticker or ISIN column must be specified")
+	}
+	if m.Columns.Currency == "" && m.Currency == "" {
+		return errors.New("Currency column or default currency must be specified")
+	}
+	return nil
+}
+
+// Read parses operations from CSV with header row using given mapping
+func Read(r io.Reader, m Mapping) ([]models.Operation, error) {
+	err := m.Validate()
+	if err != nil {
+		return nil, err
+	}
+	m = m.withDefaults()
+
+	cr := csv.NewReader(r)
+	cr.Comma, _ = utf8.DecodeRuneInString(m.Delimiter)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	for i, h := range header {
+		index[strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))] = i
+	}
+	col := func(caption string) (int, error) {
+		if caption == "" {
+			return -1, nil
+		}
+		i, ok := index[caption]
+		if !ok {
+			return -1, fmt.Errorf("column '%s' not found in CSV header", caption)
+		}
+		return i, nil
+	}
+	c := m.Columns
+	cols := make([]int, 8)
+	for i, caption := range []string{c.Date, c.Type, c.Ticker, c.ISIN, c.FIGI, c.Price, c.Volume, c.Currency} {
+		cols[i], err = col(caption)
+		if err != nil {
+			return nil, err
+		}
+	}
+	date, kind, ticker, isin, figi, price, volume, cur := cols[0], cols[1], cols[2], cols[3], cols[4], cols[5], cols[6], cols[7]
+
+	result := []models.Operation{}
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		value := func(i int) string {
+			if i < 0 || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		op := models.Operation{
+			Ticker:   value(ticker),
+			ISIN:     value(isin),
+			FIGI:     value(figi),
+			Currency: m.Currency,
+		}
+		if v := value(cur); v != "" {
+			op.Currency = currency.Type(v)
+		}
+		op.DateTime, err = time.Parse(m.DateFormat, value(date))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date '%s'. Expected %s format", line, value(date), m.DateFormat)
+		}
+		op.OperationType, err = m.parseType(value(kind))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		op.Price, err = m.parseFloat(value(price))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid price '%s'", line, value(price))
+		}
+		op.Volume, err = strconv.ParseInt(value(volume), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid volume '%s'. Expected integer number", line, value(volume))
+		}
+		if op.Ticker == "" && op.ISIN == "" {
+			return nil, fmt.Errorf("line %d: ticker or ISIN must be specified", line)
+		}
+		result = append(result, op)
+	}
+	return result, nil
+}
+
+// Write writes operations as CSV with header row using given mapping
+func Write(w io.Writer, ops []models.Operation, m Mapping) error {
+	err := m.Validate()
+	if err != nil {
+		return err
+	}
+	m = m.withDefaults()
+
+	c := m.Columns
+	header := []string{}
+	fields := []func(op models.Operation) string{}
+	add := func(caption string, field func(op models.Operation) string) {
+		if caption != "" {
+			header = append(header, caption)
+			fields = append(fields, field)
+		}
+	}
+	add(c.Date, func(op models.Operation) string { return op.DateTime.Format(m.DateFormat) })
+	add(c.Type, func(op models.Operation) string { return m.formatType(op.OperationType) })
+	add(c.Ticker, func(op models.Operation) string { return op.Ticker })
+	add(c.ISIN, func(op models.Operation) string { return op.ISIN })
+	add(c.FIGI, func(op models.Operation) string { return op.FIGI })
+	add(c.Price, func(op models.Operation) string { return m.formatFloat(op.Price) })
+	add(c.Volume, func(op models.Operation) string { return strconv.FormatInt(op.Volume, 10) })
+	add(c.Currency, func(op models.Operation) string { return string(op.Currency) })
+
+	cw := csv.NewWriter(w)
+	cw.Comma, _ = utf8.DecodeRuneInString(m.Delimiter)
+	err = cw.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			row[i] = field(op)
+		}
+		err = cw.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (m Mapping) parseType(value string) (operation.Type, error) {
+	if len(m.Types) == 0 {
+		t := operation.Type(value)
+		if knownTypes[t] {
+			return t, nil
+		}
+	} else if t, ok := m.Types[value]; ok {
+		return t, nil
+	}
+	return operation.Unknown, fmt.Errorf("unknown operation type '%s'", value)
+}
+
+// formatType returns the first CSV value mapped to given operation type
+func (m Mapping) formatType(t operation.Type) string {
+	values := []string{}
+	for k, v := range m.Types {
+		if v == t {
+			values = append(values, k)
+		}
+	}
+	if len(values) == 0 {
+		return string(t)
+	}
+	sort.Strings(values)
+	return values[0]
+}
+
+func (m Mapping) parseFloat(value string) (float64, error) {
+	value = strings.Replace(value, " ", "", -1)
+	if m.DecimalSeparator != "." {
+		value = strings.Replace(value, ".", "", -1)
+		value = strings.Replace(value, m.DecimalSeparator, ".", 1)
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func (m Mapping) formatFloat(value float64) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', -1, 64), ".", m.DecimalSeparator, 1)
+}
+
+var knownTypes = map[operation.Type]bool{
+	operation.Buy:             true,
+	operation.Sell:            true,
+	operation.BrokerageFee:    true,
+	operation.ExchangeFee:     true,
+	operation.PayIn:           true,
+	operation.PayOut:          true,
+	operation.Coupon:          true,
+	operation.AccInterestBuy:  true,
+	operation.AccInterestSell: true,
+	operation.Buyback:         true,
+	operation.Dividend:        true,
+	operation.Tax:             true,
+	operation.WithholdingTax:  true,
+	operation.Amortization:    true,
+}
@@ -0,0 +1,111 @@
+package csvfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+func TestRoundTrip(t *testing.T) {
+	msk := time.FixedZone("", 3*60*60)
+	ops := []models.Operation{
+		{Currency: currency.RUB, Price: 1, Volume: 50000, ISIN: "RU000Z13FK33", Ticker: "RUB", DateTime: time.Date(2020, 3, 2, 10, 0, 0, 0, msk), OperationType: operation.PayIn},
+		{Currency: currency.USD, Price: 293.61, Volume: 10, FIGI: "BBG00MVRXDB0", ISIN: "US9229083632", Ticker: "VOO", DateTime: time.Date(2020, 6, 6, 15, 54, 5, 123000000, time.UTC), OperationType: operation.Buy},
+		{Currency: currency.RUB, Price: 0.123456, Volume: 1, Ticker: "RUB", DateTime: time.Date(2020, 6, 6, 15, 54, 5, 0, time.UTC), OperationType: operation.BrokerageFee},
+	}
+
+	var buf bytes.Buffer
+	err := Write(&buf, ops, DefaultMapping())
+	if err != nil {
+		t.Fatalf("Fail! Could not write CSV: %s", err)
+	}
+	res, err := Read(&buf, DefaultMapping())
+	if err != nil {
+		t.Fatalf("Fail! Could not read CSV: %s", err)
+	}
+	if len(res) != len(ops) {
+		t.Fatalf("Fail! Expected %v operations, got %v", len(ops), len(res))
+	}
+	for i := range ops {
+		e, a := ops[i], res[i]
+		if e.Currency == a.Currency && e.Price == a.Price && e.Volume == a.Volume && e.FIGI == a.FIGI && e.ISIN == a.ISIN &&
+			e.Ticker == a.Ticker && e.DateTime.Equal(a.DateTime) && e.OperationType == a.OperationType {
+			t.Logf("Success! Expected %v, got %v", e, a)
+		} else {
+			t.Errorf("Fail! Expected %v, got %v", e, a)
+		}
+	}
+}
+
+func TestCustomMapping(t *testing.T) {
+	m := Mapping{
+		Delimiter:        ";",
+		DecimalSeparator: ",",
+		DateFormat:       "02.01.2006",
+		Currency:         currency.RUB,
+		Types: map[string]operation.Type{
+			"Покупка": operation.Buy,
+			"Продажа": operation.Sell,
+			"Купить":  operation.Buy,
+		},
+		Columns: Columns{Date: "Дата", Type: "Операция", ISIN: "ISIN", Price: "Цена", Volume: "Кол-во"},
+	}
+	data := "\ufeffДата;Операция;ISIN;Цена;Кол-во\n" +
+		"03.03.2020;Покупка;RU0009029540;1.245,5;20\n" +
+		"05.03.2020;Продажа;RU0009029540;250;20\n"
+
+	ops, err := Read(strings.NewReader(data), m)
+	if err != nil {
+		t.Fatalf("Fail! Could not read CSV: %s", err)
+	}
+	if len(ops) == 2 && ops[0].Price == 1245.5 && ops[0].OperationType == operation.Buy && ops[0].Currency == currency.RUB &&
+		ops[0].DateTime.Equal(time.Date(2020, 3, 3, 0, 0, 0, 0, time.UTC)) && ops[1].OperationType == operation.Sell {
+		t.Logf("Success! Got expected operations %v", ops)
+	} else {
+		t.Errorf("Fail! Unexpected operations %v", ops)
+	}
+
+	var buf bytes.Buffer
+	err = Write(&buf, ops, m)
+	if err != nil {
+		t.Fatalf("Fail! Could not write CSV: %s", err)
+	}
+	expected := "Дата;Операция;ISIN;Цена;Кол-во\n03.03.2020;Купить;RU0009029540;1245,5;20\n05.03.2020;Продажа;RU0009029540;250;20\n"
+	if buf.String() == expected {
+		t.Logf("Success! Expected %q, got %q", expected, buf.String())
+	} else {
+		t.Errorf("Fail! Expected %q, got %q", expected, buf.String())
+	}
+
+	_, err = Read(strings.NewReader("Дата;Операция;ISIN;Цена;Кол-во\n03.03.2020;Обмен;RU0009029540;1;1\n"), m)
+	if err != nil && strings.HasPrefix(err.Error(), "line 2:") {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected unknown type error on line 2, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []Mapping{
+		{Delimiter: ";;"},
+		{Columns: Columns{Date: "d", Type: "t", Price: "p", Volume: "v", Currency: "c"}},
+		{Columns: Columns{Date: "d", Type: "t", Price: "p", Volume: "v", Ticker: "s"}},
+	}
+	for _, m := range cases {
+		if err := m.Validate(); err != nil {
+			t.Logf("Success! Got expected error: %s", err)
+		} else {
+			t.Errorf("Fail! Expected error for mapping %v", m)
+		}
+	}
+	if err := (Mapping{}).Validate(); err == nil {
+		t.Logf("Success! Empty mapping falls back to defaults")
+	} else {
+		t.Errorf("Fail! Expected no error for empty mapping, got %s", err)
+	}
+}
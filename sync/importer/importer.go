@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/sync/sberbank"
+	"github.com/kaseat/pManager/sync/vtb"
+)
+
+// Format represents broker report format
+type Format string
+
+const (
+	// Sberbank - HTML broker report of Sberbank
+	Sberbank Format = "sberbank"
+	// Vtb - XLS or XLSX broker report of VTB
+	Vtb Format = "vtb"
+	// Unknown - unsupported file
+	Unknown Format = "unknown"
+)
+
+// File represents uploaded broker report
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Detect returns format of given broker report
+func Detect(data []byte) Format {
+	switch {
+	case sberbank.IsReport(data):
+		return Sberbank
+	case vtb.IsReport(data):
+		return Vtb
+	default:
+		return Unknown
+	}
+}
+
+// Parse detects format of every given broker report and parses operations.
+// Reports of the same format are parsed together so that overlapping
+// reports are merged the way broker parser does it
+func Parse(files []File) ([]models.Operation, error) {
+	byFormat := make(map[Format][][]byte)
+	for _, f := range files {
+		format := Detect(f.Data)
+		if format == Unknown {
+			return nil, fmt.Errorf("unknown format of '%s'. Expected Sberbank HTML or VTB XLS/XLSX report", f.Name)
+		}
+		byFormat[format] = append(byFormat[format], f.Data)
+	}
+
+	operations := []models.Operation{}
+	if reports, ok := byFormat[Sberbank]; ok {
+		operations = append(operations, sberbank.ParseReports(reports)...)
+	}
+	if reports, ok := byFormat[Vtb]; ok {
+		ops, err := vtb.ParseReports(reports)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, ops...)
+	}
+	sort.Sort(models.OperationSorter(operations))
+	return operations, nil
+}
@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/kaseat/pManager/models/operation"
+)
+
+const sberbankReport = `<html>
+<br>Отчет брокера</br>
+<p>за период с 02.03.2020 по 02.03.2020</p>
+<br>Движение денежных средств за период</br>
+<table>
+<tr><td>Дата</td><td>Торговая площадка</td><td>Описание операции</td><td>Валюта</td><td>Сумма зачисления</td><td>Сумма списания</td></tr>
+<tr><td>02.03.2020</td><td>Фондовый рынок</td><td>Зачисление д/с</td><td>RUB</td><td>50000</td><td>0</td></tr>
+</table>
+</html>`
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		Data     []byte
+		Expected Format
+	}{
+		{[]byte(sberbankReport), Sberbank},
+		{[]byte("PK\x03\x04xl/workbook.xml"), Vtb},
+		{[]byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"), Vtb},
+		{[]byte("date;ticker;price"), Unknown},
+	}
+	for _, c := range cases {
+		if f := Detect(c.Data); f == c.Expected {
+			t.Logf("Success! Expected %v, got %v", c.Expected, f)
+		} else {
+			t.Errorf("Fail! Expected %v, got %v", c.Expected, f)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	// the same daily report uploaded twice is parsed once
+	ops, err := Parse([]File{
+		{Name: "report.html", Data: []byte(sberbankReport)},
+		{Name: "copy.html", Data: []byte(sberbankReport)},
+	})
+	if err != nil {
+		t.Fatalf("Fail! Could not parse reports: %s", err)
+	}
+	if len(ops) == 1 && ops[0].OperationType == operation.PayIn && ops[0].Volume == 50000 && ops[0].ISIN == "RU000Z13FK33" {
+		t.Logf("Success! Got expected operations %v", ops)
+	} else {
+		t.Errorf("Fail! Unexpected operations %v", ops)
+	}
+
+	_, err = Parse([]File{{Name: "operations.csv", Data: []byte("date;ticker;price")}})
+	if err != nil {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected error for unknown report format")
+	}
+}
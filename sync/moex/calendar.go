@@ -0,0 +1,210 @@
+package moex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/event"
+	"github.com/kaseat/pManager/models/exchange"
+	"github.com/kaseat/pManager/models/instrument"
+	"github.com/kaseat/pManager/storage"
+)
+
+var isCalendarSync int32
+
+// ErrCalendarSyncInProgress returns when calendar sync already in process
+var ErrCalendarSyncInProgress = errors.New("calendar sync already in process")
+
+// IsCalendarSyncing checks whether calendar sync is in process
+func IsCalendarSyncing() bool {
+	return atomic.LoadInt32(&isCalendarSync) == 1
+}
+
+// issTable is ISS response block. Rows are positional, so values
+// are looked up by column names
+type issTable struct {
+	Columns []string        `json:"columns"`
+	Data    [][]interface{} `json:"data"`
+}
+
+func (t issTable) rows() []map[string]interface{} {
+	result := make([]map[string]interface{}, len(t.Data))
+	for i, data := range t.Data {
+		row := make(map[string]interface{}, len(t.Columns))
+		for j, c := range t.Columns {
+			if j < len(data) {
+				row[strings.ToLower(c)] = data[j]
+			}
+		}
+		result[i] = row
+	}
+	return result
+}
+
+// SyncCalendar fetches coupon, amortization and offer schedules of bonds
+// and dividends of shares traded on MOEX. Events of every security are
+// replaced with fetched ones. Progress and per-instrument errors are recorded to the job tracker
+func SyncCalendar(tr *jobs.Tracker, client *http.Client) error {
+	if !atomic.CompareAndSwapInt32(&isCalendarSync, 0, 1) {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync calendar:", ErrCalendarSyncInProgress)
+		return ErrCalendarSyncInProgress
+	}
+	defer atomic.StoreInt32(&isCalendarSync, 0)
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Begin sync calendar")
+
+	s := storage.GetStorage()
+	instruments, err := s.GetAllInstruments()
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error get securities list:", err)
+		return err
+	}
+	targets := []models.Instrument{}
+	for _, ins := range instruments {
+		if ins.Exchange == exchange.MOEX && (ins.Type == instrument.Bond || ins.Type == instrument.Stock) {
+			targets = append(targets, ins)
+		}
+	}
+	tr.SetTotal(len(targets))
+
+	added, failed := 0, 0
+	for _, ins := range targets {
+		n, err := syncEvents(s, client, ins)
+		if err != nil {
+			failed++
+		}
+		added += n
+		tr.Done(ins.Ticker, err)
+	}
+
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "End sync calendar. Added", added, "events,", failed, "errors")
+	return nil
+}
+
+func syncEvents(s storage.Db, client *http.Client, ins models.Instrument) (int, error) {
+	var events []models.SecurityEvent
+	var err error
+	if ins.Type == instrument.Bond {
+		events, err = fetchBondization(client, ins.ISIN)
+	} else {
+		events, err = fetchDividends(client, ins.Ticker)
+	}
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error fetch calendar for", ins.Ticker+":", err)
+		return 0, err
+	}
+	// ISS may repeat rows, storage keeps single event per date
+	distinct := make(map[string]int)
+	unique := []models.SecurityEvent{}
+	for _, e := range events {
+		e.ISIN = ins.ISIN
+		key := string(e.Type) + e.Date.Format("2006-01-02")
+		if i, ok := distinct[key]; ok {
+			unique[i] = e
+			continue
+		}
+		distinct[key] = len(unique)
+		unique = append(unique, e)
+	}
+	events = unique
+
+	if _, err = s.DeleteSecurityEvents(ins.ISIN); err != nil {
+		return 0, err
+	}
+	if err = s.AddSecurityEvents(events); err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error add", len(events), "events for", ins.Ticker, "to storage:", err)
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// fetchBondization fetches coupons, amortizations and offers of given bond
+func fetchBondization(client *http.Client, secID string) ([]models.SecurityEvent, error) {
+	url := fmt.Sprintf("%s/securities/%s/bondization.json?iss.meta=off&iss.only=coupons,amortizations,offers&limit=unlimited", baseURL, secID)
+	var rawResponse struct {
+		Coupons       issTable `json:"coupons"`
+		Amortizations issTable `json:"amortizations"`
+		Offers        issTable `json:"offers"`
+	}
+	err := getJSON(client, url, &rawResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.SecurityEvent{}
+	result = append(result, parseEvents(rawResponse.Coupons, event.Coupon, "coupondate", "value", "faceunit")...)
+	result = append(result, parseEvents(rawResponse.Amortizations, event.Amortization, "amortdate", "value", "faceunit")...)
+	result = append(result, parseEvents(rawResponse.Offers, event.Offer, "offerdate", "value", "faceunit")...)
+	return result, nil
+}
+
+// fetchDividends fetches dividends announced for given share. Event date is registry close date
+func fetchDividends(client *http.Client, secID string) ([]models.SecurityEvent, error) {
+	url := fmt.Sprintf("%s/securities/%s/dividends.json?iss.meta=off", baseURL, secID)
+	var rawResponse struct {
+		Dividends issTable `json:"dividends"`
+	}
+	err := getJSON(client, url, &rawResponse)
+	if err != nil {
+		return nil, err
+	}
+	return parseEvents(rawResponse.Dividends, event.Dividend, "registryclosedate", "value", "currencyid"), nil
+}
+
+// parseEvents reads events from given table. Rows with unknown date are skipped,
+// as well as coupons and dividends which value is not defined yet
+func parseEvents(t issTable, kind event.Type, date, value, curr string) []models.SecurityEvent {
+	result := []models.SecurityEvent{}
+	for _, row := range t.rows() {
+		str, _ := row[date].(string)
+		d, err := time.Parse("2006-01-02", str)
+		if err != nil {
+			continue
+		}
+		v, ok := row[value].(float64)
+		if !ok && kind != event.Offer {
+			continue
+		}
+		c, _ := row[curr].(string)
+		result = append(result, models.SecurityEvent{
+			Type:     kind,
+			Date:     d,
+			Value:    v,
+			Currency: parseCurrency(c),
+		})
+	}
+	return result
+}
+
+func parseCurrency(str string) currency.Type {
+	switch c := strings.ToUpper(strings.TrimSpace(str)); c {
+	case "", "SUR", "RUR":
+		return currency.RUB
+	default:
+		return currency.Type(c)
+	}
+}
+
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	r, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(r, v)
+}
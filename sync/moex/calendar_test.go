@@ -0,0 +1,76 @@
+package moex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/event"
+)
+
+const bondizationResponse = `{
+"coupons": {"columns": ["isin", "name", "coupondate", "recorddate", "startdate", "facevalue", "faceunit", "value", "valueprc", "value_rub"],
+	"data": [
+		["RU000A0JSMA2", "ОФЗ 26209", "2020-07-22", "2020-07-21", "2020-01-22", 1000, "SUR", 37.9, 7.6, 37.9],
+		["RU000A0JSMA2", "ОФЗ 26209", "2021-01-20", "2021-01-19", "2020-07-22", 1000, "SUR", null, null, null]
+	]},
+"amortizations": {"columns": ["isin", "name", "amortdate", "facevalue", "faceunit", "valueprc", "value", "value_rub", "data_source"],
+	"data": [["RU000A0JSMA2", "ОФЗ 26209", "2022-07-20", 1000, "SUR", 100, 1000, 1000, "maturity"]]},
+"offers": {"columns": ["isin", "name", "offerdate", "offerdatestart", "offerdateend", "facevalue", "faceunit", "price", "value", "agent", "offertype"],
+	"data": [["RU000A0JSMA2", "ОФЗ 26209", "0000-00-00", null, null, 1000, "SUR", 100, 1000, "", "Оферта"]]}
+}`
+
+const dividendsResponse = `{
+"dividends": {"columns": ["secid", "isin", "registryclosedate", "value", "currencyid"],
+	"data": [["SBER", "RU0009029540", "2020-10-05", 18.7, "RUB"]]}
+}`
+
+func TestFetchCalendar(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/securities/RU000A0JSMA2/bondization.json":
+			w.Write([]byte(bondizationResponse))
+		case "/securities/SBER/dividends.json":
+			w.Write([]byte(dividendsResponse))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defaultURL := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = defaultURL }()
+
+	events, err := fetchBondization(srv.Client(), "RU000A0JSMA2")
+	if err != nil {
+		t.Fatalf("Fail! Could not fetch bondization: %s", err)
+	}
+	if len(events) == 2 &&
+		events[0].Type == event.Coupon && events[0].Value == 37.9 && events[0].Currency == currency.RUB &&
+		events[0].Date.Equal(time.Date(2020, 7, 22, 0, 0, 0, 0, time.UTC)) &&
+		events[1].Type == event.Amortization && events[1].Value == 1000 {
+		t.Logf("Success! Got expected events %+v", events)
+	} else {
+		t.Errorf("Fail! Unexpected events %+v", events)
+	}
+
+	events, err = fetchDividends(srv.Client(), "SBER")
+	if err != nil {
+		t.Fatalf("Fail! Could not fetch dividends: %s", err)
+	}
+	if len(events) == 1 && events[0].Type == event.Dividend && events[0].Value == 18.7 &&
+		events[0].Date.Equal(time.Date(2020, 10, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Logf("Success! Got expected events %+v", events)
+	} else {
+		t.Errorf("Fail! Unexpected events %+v", events)
+	}
+
+	_, err = fetchDividends(srv.Client(), "UNKNOWN")
+	if err != nil {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected error for unknown security")
+	}
+}
@@ -25,7 +25,7 @@ type issSecurity struct {
 }
 
 func fetchFromAPI(client *http.Client, from time.Time, sec issSecurity, cursor int) ([]priceInternal, int, error) {
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Start fetching prices for", sec.Ticker, "from", cursor)
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Start fetching prices for", sec.Ticker, "from", cursor)
 	var board string
 	if sec.IsBond {
 		board = "bonds"
@@ -35,7 +35,7 @@ func fetchFromAPI(client *http.Client, from time.Time, sec issSecurity, cursor i
 	columns := "history.columns=BOARDID,TRADEDATE,LEGALCLOSEPRICE,VOLUME,FACEVALUE"
 	fromStr := fmt.Sprintf("from=%s", from.Format("2006-01-02"))
 	start := fmt.Sprintf("start=%d", cursor)
-	url := "http://iss.moex.com/iss/history/engines/stock/markets"
+	url := baseURL + "/history/engines/stock/markets"
 	url = fmt.Sprintf("%s/%s/securities/%s.json", url, board, sec.Ticker)
 	url = fmt.Sprintf("%s?iss.meta=off&%s&%s&%s", url, columns, fromStr, start)
 
@@ -101,12 +101,12 @@ func fetchFromAPI(client *http.Client, from time.Time, sec issSecurity, cursor i
 	} else {
 		cursor = 0
 	}
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Success load", len(prices), "prices for", sec.Ticker)
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Success load", len(prices), "prices for", sec.Ticker)
 	return prices, cursor, nil
 }
 
 func getSecurityInfo(client *http.Client, ticker string) (issSecurity, error) {
-	securitiesURI := "https://iss.moex.com/iss/securities/%s.json?iss.meta=off"
+	securitiesURI := baseURL + "/securities/%s.json?iss.meta=off"
 	url := fmt.Sprintf(securitiesURI, ticker)
 	var security issSecurity
 	response, err := client.Get(url)
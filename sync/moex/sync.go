@@ -1,121 +1,75 @@
 package moex
 
 import (
-	"errors"
-	"fmt"
 	"net/http"
-	"sync/atomic"
 	"time"
 
+	"github.com/kaseat/pManager/config"
 	"github.com/kaseat/pManager/models"
-	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/models/exchange"
+	"github.com/kaseat/pManager/sync/prices"
 )
 
-var isSync int32
+var baseURL = config.Default().Sync.Moex.URL
 
-// Sync starts moex sync
-func Sync(ticker string, httpClient *http.Client) {
-	defer func() {
-		atomic.StoreInt32(&isSync, 0)
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "End sync MOEX")
-	}()
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Begin sync MOEX")
-	if atomic.LoadInt32(&isSync) == 1 {
-		err := errors.New("MOEX sync already in process")
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-		return
-	}
-	atomic.StoreInt32(&isSync, 1)
+func init() {
+	prices.Register(exchange.MOEX, Provider{})
+}
 
-	s := storage.GetStorage()
+// Configure sets MOEX ISS API settings
+func Configure(c config.Provider) {
+	baseURL = c.URL
+}
 
-	var instrumentsToSync []models.Instrument
-	var err error = nil
-	if ticker == "" {
-		instrumentsToSync, err = s.GetInstruments("code", "MOEX")
-	} else {
-		instrumentsToSync, err = s.GetInstruments("ticker", ticker)
-	}
+// Provider fetches daily prices from MOEX ISS API
+type Provider struct{}
 
+// Name returns provider name
+func (Provider) Name() string {
+	return "MOEX"
+}
+
+// Fetch returns daily close prices of given instrument. Prices of
+// all boards trading in instrument currency are merged by date
+func (Provider) Fetch(client *http.Client, ins models.Instrument, from, to time.Time) ([]models.Price, error) {
+	securityInfo, err := getSecurityInfo(client, ins.Ticker)
 	if err != nil {
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error get securities list:", err)
-		return
+		return nil, err
 	}
 
-	today := today()
-	for _, instrument := range instrumentsToSync {
-		securityInfo, err := getSecurityInfo(httpClient, instrument.Ticker)
+	var pricesRaw []priceInternal
+	for cursor := 0; ; {
+		rawPrice, cursorAfterFetch, err := fetchFromAPI(client, from, securityInfo, cursor)
 		if err != nil {
-			fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error get instrument info:", err)
-			continue
+			return nil, err
 		}
-
-		from := instrument.PriceUptdTime
-		if from.IsZero() {
-			from = time.Date(2019, time.May, 1, 0, 0, 0, 0, time.UTC)
+		pricesRaw = append(pricesRaw, rawPrice...)
+		if cursorAfterFetch == 0 {
+			break
 		}
+		cursor = cursorAfterFetch
+	}
 
-		if from.After(today) {
+	distinctPrices := make(map[time.Time]models.Price)
+	for _, rawPrice := range pricesRaw {
+		if ins.Currency != rawPrice.Currency || !rawPrice.Date.Before(to) {
 			continue
 		}
-
-		var pricesRaw []priceInternal
-
-		for cursor := 0; ; {
-			rawPrice, cursorAfterFetch, err := fetchFromAPI(httpClient, from, securityInfo, cursor)
-			if err != nil {
-				break
-			}
-			pricesRaw = append(pricesRaw, rawPrice...)
-			if cursorAfterFetch == 0 {
-				break
-			}
-			cursor = cursorAfterFetch
-		}
-
-		distinctPrices := make(map[time.Time]models.Price)
-		var lastDate time.Time
-		for _, rawPrice := range pricesRaw {
-			if instrument.Currency != rawPrice.Currency {
-				continue
-			}
-			price := models.Price{
-				SecID:  instrument.SecID,
+		if val, ok := distinctPrices[rawPrice.Date]; ok {
+			val.Volume += rawPrice.Volume
+			distinctPrices[rawPrice.Date] = val
+		} else {
+			distinctPrices[rawPrice.Date] = models.Price{
 				Price:  rawPrice.Price,
 				Volume: rawPrice.Volume,
 				Date:   rawPrice.Date,
-				ISIN:   instrument.ISIN,
-			}
-			if rawPrice.Date.After(lastDate) {
-				lastDate = rawPrice.Date
-			}
-			if val, ok := distinctPrices[rawPrice.Date]; ok {
-				val.Volume += rawPrice.Volume
-				distinctPrices[rawPrice.Date] = val
-			} else {
-				distinctPrices[rawPrice.Date] = price
-			}
-		}
-
-		prices := make([]models.Price, 0, len(distinctPrices))
-		for _, price := range distinctPrices {
-			prices = append(prices, price)
-		}
-		fmt.Println(len(pricesRaw), len(prices))
-
-		if len(prices) > 0 {
-			if err = s.AddPrices(prices); err != nil {
-				fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error add", len(prices), "prices for", ticker, "to storage:", err)
-			} else {
-				fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Success add", len(prices), "prices for", ticker, "to storage")
-				s.SetInstrumentPriceUptdTime(instrument.SecID, lastDate.AddDate(0, 0, 1))
 			}
 		}
 	}
-}
 
-func today() time.Time {
-	now := time.Now().UTC()
-	y, m, d := now.Date()
-	return time.Date(y, m, d, 0, 0, 0, 0, now.Location()).Add(time.Hour * -1)
+	result := make([]models.Price, 0, len(distinctPrices))
+	for _, price := range distinctPrices {
+		result = append(result, price)
+	}
+	return result, nil
 }
@@ -4,6 +4,9 @@ import (
 	"net/http"
 	"testing"
 	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
 )
 
 func TestMoexSync(t *testing.T) {
@@ -11,6 +14,7 @@ func TestMoexSync(t *testing.T) {
 	client := &http.Client{
 		Timeout: time.Second * 5,
 	}
-	Sync("RU000A0JW1K9", client)
+	ins := models.Instrument{Ticker: "RU000A0JW1K9", Currency: currency.RUB}
+	Provider{}.Fetch(client, ins, time.Date(2019, time.May, 1, 0, 0, 0, 0, time.UTC), time.Now())
 	t.Fail()
 }
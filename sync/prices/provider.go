@@ -0,0 +1,51 @@
+package prices
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/exchange"
+)
+
+// Provider represents source of daily instrument prices
+type Provider interface {
+	// Name returns provider name used in logs
+	Name() string
+	// Fetch returns daily prices of given instrument from 'from' date inclusive
+	// till 'to' date exclusive
+	Fetch(client *http.Client, ins models.Instrument, from, to time.Time) ([]models.Price, error)
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[exchange.Type]Provider{}
+	fallback  Provider
+)
+
+// Register makes provider available for instruments of given exchange.
+// Registering provider for the same exchange twice replaces previous one
+func Register(exch exchange.Type, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[exch] = p
+}
+
+// RegisterFallback sets provider used for instruments
+// of exchanges that have no provider registered
+func RegisterFallback(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	fallback = p
+}
+
+// GetProvider returns provider for given exchange
+func GetProvider(exch exchange.Type) (Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if p, ok := providers[exch]; ok {
+		return p, true
+	}
+	return fallback, fallback != nil
+}
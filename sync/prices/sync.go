@@ -1,21 +1,123 @@
 package prices
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
-	"github.com/kaseat/pManager/sync/moex"
-	"github.com/kaseat/pManager/sync/spbex"
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/exchange"
+	"github.com/kaseat/pManager/storage"
 )
 
 var isSync int32
 
-// Sync starts prices sync
-// Sync via MOEX and SPBEX
-func Sync() {
-	client := &http.Client{
-		Timeout: time.Second * 5,
+// ErrSyncInProgress returns when prices sync already in process
+var ErrSyncInProgress = errors.New("prices sync already in process")
+
+// IsSyncing checks whether sync is in process
+func IsSyncing() bool {
+	return atomic.LoadInt32(&isSync) == 1
+}
+
+// Sync fetches prices of all instruments from providers registered for instrument exchange.
+// If exchanges provided only instruments of these exchanges are synced. Progress and
+// per-instrument errors are recorded to the job tracker
+func Sync(tr *jobs.Tracker, httpClient *http.Client, exchanges ...exchange.Type) error {
+	if !atomic.CompareAndSwapInt32(&isSync, 0, 1) {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync prices:", ErrSyncInProgress)
+		return ErrSyncInProgress
+	}
+	defer atomic.StoreInt32(&isSync, 0)
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Begin sync prices")
+
+	s := storage.GetStorage()
+	instruments, err := s.GetAllInstruments()
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error get securities list:", err)
+		return err
+	}
+	instruments = filter(instruments, exchanges)
+	tr.SetTotal(len(instruments))
+
+	added, failed := 0, 0
+	to := today()
+	for _, ins := range instruments {
+		n, err := syncInstrument(s, httpClient, ins, to)
+		if err != nil {
+			failed++
+		}
+		added += n
+		tr.Done(ins.Ticker, err)
+	}
+
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "End sync prices. Added", added, "prices,", failed, "errors")
+	return nil
+}
+
+func syncInstrument(s storage.Db, client *http.Client, ins models.Instrument, to time.Time) (int, error) {
+	p, ok := GetProvider(ins.Exchange)
+	if !ok {
+		err := fmt.Errorf("no price provider for %s exchange", ins.Exchange)
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync prices for", ins.Ticker+":", err)
+		return 0, err
+	}
+
+	from := ins.PriceUptdTime
+	if from.IsZero() {
+		from = time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if !from.Before(to) {
+		return 0, nil
 	}
-	go moex.Sync("", client)
-	go spbex.Sync("", client)
+
+	prices, err := p.Fetch(client, ins, from, to)
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error fetch prices for", ins.Ticker, "from", p.Name()+":", err)
+		return 0, err
+	}
+	if len(prices) == 0 {
+		return 0, nil
+	}
+
+	var lastDate time.Time
+	for i := range prices {
+		prices[i].SecID = ins.SecID
+		prices[i].ISIN = ins.ISIN
+		if prices[i].Date.After(lastDate) {
+			lastDate = prices[i].Date
+		}
+	}
+
+	if err = s.AddPrices(prices); err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error add", len(prices), "prices for", ins.Ticker, "to storage:", err)
+		return 0, err
+	}
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Success add", len(prices), "prices for", ins.Ticker, "from", p.Name())
+	_, err = s.SetInstrumentPriceUptdTime(ins.SecID, lastDate.AddDate(0, 0, 1))
+	return len(prices), err
+}
+
+func filter(instruments []models.Instrument, exchanges []exchange.Type) []models.Instrument {
+	if len(exchanges) == 0 {
+		return instruments
+	}
+	result := make([]models.Instrument, 0, len(instruments))
+	for _, ins := range instruments {
+		for _, exch := range exchanges {
+			if ins.Exchange == exch {
+				result = append(result, ins)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func today() time.Time {
+	y, m, d := time.Now().UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
 }
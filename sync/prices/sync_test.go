@@ -0,0 +1,95 @@
+package prices
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/exchange"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/storage"
+)
+
+type fakeProvider struct {
+	calls *int
+}
+
+func (fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p fakeProvider) Fetch(client *http.Client, ins models.Instrument, from, to time.Time) ([]models.Price, error) {
+	*p.calls++
+	return []models.Price{
+		{Price: 10, Volume: 1, Date: from},
+		{Price: 11, Volume: 1, Date: from.AddDate(0, 0, 1)},
+	}, nil
+}
+
+func TestSync(t *testing.T) {
+	calls := 0
+	Register(exchange.MOEX, fakeProvider{calls: &calls})
+	defer delete(providers, exchange.MOEX)
+
+	storage.SwitchStorage(storage.Memory)
+	s := storage.GetStorage()
+	s.AddInstruments([]models.Instrument{
+		{ISIN: "RU0009029540", Ticker: "SBER", Exchange: exchange.MOEX, Currency: currency.RUB},
+		{ISIN: "US45867G1013", Ticker: "IDCC", Exchange: exchange.SPBEX, Currency: currency.USD},
+	})
+
+	tr, _ := jobs.Start(job.Prices, "")
+	err := Sync(tr, nil)
+	if err != nil {
+		t.Errorf("Fail! Unexpected error: %v", err)
+	}
+	j, _ := s.GetJob(tr.ID())
+	if j.Total == 2 && j.Processed == 2 && j.State == job.Running {
+		t.Logf("Success! Got expected job %v", j)
+	} else {
+		t.Errorf("Fail! Unexpected job %v", j)
+	}
+	if len(j.Errors) == 1 && j.Errors[0].Item == "IDCC" {
+		t.Logf("Success! Got expected errors %v", j.Errors)
+	} else {
+		t.Errorf("Fail! Unexpected errors %v", j.Errors)
+	}
+
+	prices, _ := s.GetPricesByIsin("RU0009029540", "", "")
+	if len(prices) == 2 && prices[0].SecID != 0 {
+		t.Logf("Success! Expected %v prices, got %v", 2, len(prices))
+	} else {
+		t.Errorf("Fail! Expected %v prices, got %v", 2, prices)
+	}
+
+	ins, _ := s.GetInstruments("ticker", "SBER")
+	expected := time.Date(2019, time.January, 3, 0, 0, 0, 0, time.UTC)
+	if len(ins) == 1 && ins[0].PriceUptdTime.Equal(expected) {
+		t.Logf("Success! Expected %v, got %v", expected, ins[0].PriceUptdTime)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", expected, ins)
+	}
+
+	// only SPBEX instruments are synced
+	Sync(nil, nil, exchange.SPBEX)
+	if calls == 1 {
+		t.Logf("Success! Expected %v calls, got %v", 1, calls)
+	} else {
+		t.Errorf("Fail! Expected %v calls, got %v", 1, calls)
+	}
+}
+
+func TestGetProvider(t *testing.T) {
+	calls := 0
+	RegisterFallback(fakeProvider{calls: &calls})
+	defer RegisterFallback(nil)
+
+	if p, ok := GetProvider(exchange.SPBEX); ok && p.Name() == "fake" {
+		t.Logf("Success! Got fallback provider")
+	} else {
+		t.Errorf("Fail! Expected fallback provider, got %v", p)
+	}
+}
@@ -28,7 +28,7 @@ func parseReport(r io.Reader) report {
 			scanner.Scan()
 			re := regexp.MustCompile(`\d{2}.\d{2}.\d{4}`)
 			match := re.FindAllString(scanner.Text(), -1)
-			if match[0] != match[1] {
+			if len(match) < 2 || match[0] != match[1] {
 				result.IsEmpty = true
 				break
 			}
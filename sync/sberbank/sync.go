@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/kaseat/pManager/gmail"
+	"github.com/kaseat/pManager/jobs"
 	"github.com/kaseat/pManager/models"
 	"github.com/kaseat/pManager/models/provider"
 	"github.com/kaseat/pManager/storage"
@@ -19,27 +20,28 @@ import (
 var isSync int32
 
 // SyncGmail init sberbank report sync
-func SyncGmail(login, pid, from, to string) {
-	defer atomic.StoreInt32(&isSync, 0)
-	if atomic.LoadInt32(&isSync) == 1 {
+// and imports operations into given portfolio.
+// Every parsed report message is recorded to the job tracker
+func SyncGmail(tr *jobs.Tracker, login, pid, from, to string) error {
+	if !atomic.CompareAndSwapInt32(&isSync, 0, 1) {
 		err := errors.New("Sync already in process")
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-		return
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+		return err
 	}
-	atomic.StoreInt32(&isSync, 1)
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Begin sync sberbank operations via Gmail")
+	defer atomic.StoreInt32(&isSync, 0)
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Begin sync sberbank operations via Gmail")
 	cl := gmail.GetClient()
 	srv, err := cl.GetServiceForUser(login)
 	if err != nil {
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-		return
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+		return err
 	}
 
 	s := storage.GetStorage()
 	t, err := s.GetUserLastUpdateTime(login, provider.Sber)
 	if err != nil {
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-		return
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+		return err
 	}
 
 	query := "from:broker_rep@sberbank.ru subject:report filename:html"
@@ -56,21 +58,20 @@ func SyncGmail(login, pid, from, to string) {
 	fmt.Println(query)
 	r, err := srv.Users.Messages.List("me").Q(query).Do()
 	if err != nil {
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-		return
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+		return err
 	}
 
-	parsedDates := make(map[string]bool)
-	operations := make([]models.Operation, 0)
-	securities := make(map[ticker]securitiesInfo)
+	tr.SetTotal(len(r.Messages))
+	reports := [][]byte{}
 	lastUptdTime := time.Time{}
 
 	for _, m := range r.Messages {
 		msg, err := srv.Users.Messages.Get("me", m.Id).Do()
 
 		if err != nil {
-			fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-			return
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+			return err
 		}
 		attachmentID := ""
 		for _, p := range msg.Payload.Parts {
@@ -81,68 +82,86 @@ func SyncGmail(login, pid, from, to string) {
 
 		att, err := srv.Users.Messages.Attachments.Get("me", m.Id, attachmentID).Do()
 		if err != nil {
-			fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-			return
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+			return err
 		}
 
 		b, err := base64.URLEncoding.DecodeString(att.Data)
 		if err != nil {
-			fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-			return
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+			return err
 		}
+		reports = append(reports, b)
 
-		reader := bytes.NewReader(b)
-		report := parseReport(reader)
-		if !report.IsEmpty {
-			if !parsedDates[report.Date] {
-				parsedDates[report.Date] = true
-
-				for k, v := range report.SecuritiesInfo {
-					securities[k] = v
-				}
-
-				if len(report.Operations) > 0 {
-					operations = append(operations, report.Operations...)
-				}
-				if len(report.Buybacks) > 0 {
-					operations = append(operations, report.Buybacks...)
-				}
-				if len(report.CashFlow) > 0 {
-					operations = append(operations, report.CashFlow...)
-				}
-			}
-		}
 		msgTime := time.Unix(msg.InternalDate/1000, 0)
 		if msgTime.After(lastUptdTime) {
 			lastUptdTime = msgTime
 		}
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Parse message on", msgTime, "ok!")
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Fetch message on", msgTime, "ok!")
+		tr.Done(m.Id, nil)
 	}
-	securities["RUB"] = securitiesInfo{ISIN: "RU000Z13FK33"}
 
+	operations := ParseReports(reports)
 	if len(operations) != 0 {
-		for i, op := range operations {
-			inf := securities[ticker(op.Ticker)]
-			if inf.ISIN != "" {
-				operations[i].ISIN = inf.ISIN
-			}
-		}
-
-		sort.Sort(models.OperationSorter(operations))
-		_, err = s.AddOperations(pid, operations)
+		ids, err := s.AddOperations(pid, operations)
 		if err != nil {
-			fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-			return
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+			return err
 		}
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "save opertions for", login, "to storge OK")
+		skipped := 0
+		for _, id := range ids {
+			if id == "" {
+				skipped++
+			}
+		}
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "save opertions for", login, "to storge OK. Skipped", skipped, "duplicates")
 	}
 
 	if !lastUptdTime.IsZero() {
 		err = s.AddUserLastUpdateTime(login, provider.Sber, lastUptdTime.AddDate(0, 0, 1))
 		if err != nil {
-			fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-			return
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+			return err
+		}
+	}
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Success sync sberbank operations via Gmail")
+	return nil
+}
+
+// IsReport checks whether given file is Sberbank broker report
+func IsReport(data []byte) bool {
+	return bytes.Contains(data, []byte(reportHeaderMarker))
+}
+
+// ParseReports parses given Sberbank broker reports.
+// Monthly reports and reports of already parsed dates are skipped
+func ParseReports(reports [][]byte) []models.Operation {
+	parsedDates := make(map[string]bool)
+	operations := make([]models.Operation, 0)
+	securities := make(map[ticker]securitiesInfo)
+
+	for _, b := range reports {
+		report := parseReport(bytes.NewReader(b))
+		if report.IsEmpty || parsedDates[report.Date] {
+			continue
+		}
+		parsedDates[report.Date] = true
+
+		for k, v := range report.SecuritiesInfo {
+			securities[k] = v
+		}
+		operations = append(operations, report.Operations...)
+		operations = append(operations, report.Buybacks...)
+		operations = append(operations, report.CashFlow...)
+	}
+	securities["RUB"] = securitiesInfo{ISIN: "RU000Z13FK33"}
+
+	for i, op := range operations {
+		inf := securities[ticker(op.Ticker)]
+		if inf.ISIN != "" {
+			operations[i].ISIN = inf.ISIN
 		}
 	}
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Success sync sberbank operations via Gmail")
+	sort.Sort(models.OperationSorter(operations))
+	return operations
 }
@@ -2,131 +2,77 @@ package spbex
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
-	"sync/atomic"
 	"time"
 
+	"github.com/kaseat/pManager/config"
 	"github.com/kaseat/pManager/models"
-	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/models/exchange"
+	"github.com/kaseat/pManager/sync/prices"
 )
 
-var isSync int32
+var baseURL = config.Default().Sync.Spbex.URL
 
-// Sync starts spbex sync
-func Sync(ticker string, httpClient *http.Client) {
-	defer func() {
-		atomic.StoreInt32(&isSync, 0)
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "End sync SPBEX")
-	}()
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Begin sync SPBEX")
-	if atomic.LoadInt32(&isSync) == 1 {
-		err := errors.New("SPBEX sync already in process")
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync prices:", err)
-		return
-	}
-	atomic.StoreInt32(&isSync, 1)
+func init() {
+	prices.Register(exchange.SPBEX, Provider{})
+}
+
+// Configure sets SPBEX API settings
+func Configure(c config.Provider) {
+	baseURL = c.URL
+}
 
-	s := storage.GetStorage()
+// Provider fetches daily prices from SPBEX API
+type Provider struct{}
 
-	var instrumentsToSync []models.Instrument
-	var err error = nil
-	if ticker == "" {
-		instrumentsToSync, err = s.GetInstruments("code", "SPBEX")
-	} else {
-		instrumentsToSync, err = s.GetInstruments("ticker", ticker)
+// Name returns provider name
+func (Provider) Name() string {
+	return "SPBEX"
+}
+
+// Fetch returns daily close prices of given instrument
+func (Provider) Fetch(client *http.Client, ins models.Instrument, from, to time.Time) ([]models.Price, error) {
+	url := baseURL + "/chistory?symbol=%s&resolution=D"
+	url = fmt.Sprintf(url, ins.Ticker)
+	url += fmt.Sprintf("&from=%d&to=%d", from.Unix(), to.Unix())
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
+	r, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error get securities list:", err)
-		return
+		return nil, err
+	}
+	if len(r) < 2 {
+		return nil, fmt.Errorf("unexpected response: %s", string(r))
 	}
 
-	for _, instrument := range instrumentsToSync {
+	var rawPrices struct {
+		Timestamp []int     `json:"t"`
+		Price     []float64 `json:"c"`
+	}
+	err = json.Unmarshal([]byte(strings.Replace(string(r)[1:len(r)-1], `\"`, `"`, -1)), &rawPrices)
+	if err != nil {
+		return nil, err
+	}
 
-		from := instrument.PriceUptdTime
-		if from.IsZero() {
-			from = time.Date(2019, time.May, 1, 0, 0, 0, 0, time.UTC)
-		}
-		to := today(time.Now().UTC())
-		if from.After(to) {
+	result := make([]models.Price, 0, len(rawPrices.Price))
+	for i := 0; i < len(rawPrices.Price) && i < len(rawPrices.Timestamp); i++ {
+		date := time.Unix(int64(rawPrices.Timestamp[i]), 0)
+		if !date.Before(to) {
 			continue
 		}
-
-		url := "https://investcab.ru/api/chistory?symbol=%s&resolution=D"
-		url = fmt.Sprintf(url, instrument.Ticker)
-		url += fmt.Sprintf("&from=%d&to=%d", from.Unix(), to.Unix())
-
-		resp, err := httpClient.Get(url)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer resp.Body.Close()
-
-		r, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		var rawPrices struct {
-			Timestamp []int     `json:"t"`
-			Price     []float64 `json:"c"`
-		}
-		err = json.Unmarshal([]byte(strings.Replace(string(r)[1:len(r)-1], `\"`, `"`, -1)), &rawPrices)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		pricesInt := make([]struct {
-			Date  time.Time
-			Price float64
-		}, len(rawPrices.Price))
-
-		for i := 0; i < len(rawPrices.Price); i++ {
-			pricesInt[i] = struct {
-				Date  time.Time
-				Price float64
-			}{
-				Date:  time.Unix(int64(rawPrices.Timestamp[i]), 0),
-				Price: rawPrices.Price[i],
-			}
-		}
-
-		prices := make([]models.Price, 0, len(pricesInt))
-		var lastDate time.Time
-		for _, rawPrice := range pricesInt {
-			price := models.Price{
-				SecID:  instrument.SecID,
-				Price:  rawPrice.Price,
-				Volume: 0,
-				Date:   rawPrice.Date,
-				ISIN:   instrument.ISIN,
-			}
-			if rawPrice.Date.After(lastDate) {
-				lastDate = rawPrice.Date
-			}
-			prices = append(prices, price)
-		}
-
-		if len(prices) > 0 {
-			if err = s.AddPrices(prices); err != nil {
-				fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error add", len(prices), "prices for", instrument.Ticker, "to storage:", err)
-			} else {
-				fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Success add", len(prices), "prices for", instrument.Ticker, "to storage")
-				s.SetInstrumentPriceUptdTime(instrument.SecID, lastDate.AddDate(0, 0, 1))
-			}
-		}
+		result = append(result, models.Price{
+			Price:  rawPrices.Price[i],
+			Volume: 0,
+			Date:   date,
+		})
 	}
-
-}
-
-func today(t time.Time) time.Time {
-	y, m, d := t.Date()
-	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	return result, nil
 }
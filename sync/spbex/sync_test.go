@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"testing"
 	"time"
+
+	"github.com/kaseat/pManager/models"
 )
 
 func TestSpbexSync(t *testing.T) {
@@ -11,6 +13,7 @@ func TestSpbexSync(t *testing.T) {
 		Timeout: time.Second * 5,
 	}
 
-	Sync("", client)
+	ins := models.Instrument{Ticker: "IDCC"}
+	Provider{}.Fetch(client, ins, time.Date(2019, time.May, 1, 0, 0, 0, 0, time.UTC), time.Now())
 	t.Fail()
 }
@@ -2,20 +2,15 @@ package tcs
 
 import (
 	"time"
-)
 
-// Status represents sync status
-type Status string
+	"github.com/kaseat/pManager/config"
+)
 
-// SyncStatus represents current snc status
-type SyncStatus struct {
-	Status Status `json:"status"`
-	Error  error  `json:"error,omitempty"`
-}
+var baseURL = config.Default().Sync.Tcs.URL
 
-type syncError struct {
-	Error      error
-	IsNotEmpty bool
+// Configure sets Tinkoff API settings
+func Configure(c config.Provider) {
+	baseURL = c.URL
 }
 
 type chunk struct {
@@ -23,20 +18,6 @@ type chunk struct {
 	To   time.Time
 }
 
-const (
-	// Ok shows that last sync was ok
-	Ok Status = "ok"
-	// Err shows that there was error during sync
-	Err Status = "Error"
-	// Processing shows that sync is in process
-	Processing Status = "Processing"
-)
-
-func today() time.Time {
-	year, month, day := time.Now().Date()
-	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
-}
-
 func getTimeChunks(from, to time.Time, size int) []chunk {
 	result := []chunk{}
 	for {
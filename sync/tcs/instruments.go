@@ -9,49 +9,56 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/kaseat/pManager/jobs"
 	"github.com/kaseat/pManager/models"
 	"github.com/kaseat/pManager/storage"
 )
 
-const stocksURL = "https://api-invest.tinkoff.ru/openapi/sandbox/market/stocks"
-const bondsURL = "https://api-invest.tinkoff.ru/openapi/sandbox/market/bonds"
-const etfURL = "https://api-invest.tinkoff.ru/openapi/sandbox/market/etfs"
-const currURL = "https://api-invest.tinkoff.ru/openapi/sandbox/market/currencies"
-
-var lastSyncIstrumentsError atomic.Value
 var syncInstrumentsIsRunning int32
 
-// SyncInstruments start sync instruments from tcs API
-func SyncInstruments() {
-	defer atomic.StoreInt32(&syncInstrumentsIsRunning, 0)
-	if atomic.LoadInt32(&syncInstrumentsIsRunning) == 1 {
-		return
+// SyncInstruments start sync instruments from tcs API.
+// Instruments are recorded as processed once saved to the storage
+func SyncInstruments(tr *jobs.Tracker) error {
+	if !atomic.CompareAndSwapInt32(&syncInstrumentsIsRunning, 0, 1) {
+		return errors.New("instruments sync already in process")
 	}
-	atomic.StoreInt32(&syncInstrumentsIsRunning, 1)
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Begin sync instruments")
+	defer atomic.StoreInt32(&syncInstrumentsIsRunning, 0)
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Begin sync instruments")
 	s := storage.GetStorage()
 	token, _ := s.GetTcsToken()
 	if token == "" {
-		setLastInstrumentError(errors.New("No TCS token found"))
-		return
+		err := errors.New("No TCS token found")
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+		return err
+	}
+	urls := []string{
+		baseURL + "/market/stocks",
+		baseURL + "/market/bonds",
+		baseURL + "/market/etfs",
+		baseURL + "/market/currencies",
 	}
-	urls := []string{stocksURL, bondsURL, etfURL, currURL}
 	instruments := []models.Instrument{}
 	client := &http.Client{}
-	channel := make(chan []models.Instrument)
+	channel := make(chan instrumentsResult)
 
 	for _, url := range urls {
 		go getInstruments(client, token, url, channel)
 	}
 
 	for range urls {
-		instruments = append(instruments, <-channel...)
+		res := <-channel
+		if res.Err != nil {
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error get instruments from", res.URL+":", res.Err)
+			tr.Error(res.URL, res.Err)
+			continue
+		}
+		instruments = append(instruments, res.Instruments...)
 	}
 
 	instr, err := s.GetAllInstruments()
 	if err != nil {
-		setLastInstrumentError(err)
-		return
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+		return err
 	}
 	instrMap := make(map[string]models.Instrument)
 	for _, ins := range instr {
@@ -66,37 +73,29 @@ func SyncInstruments() {
 			instrToAdd = append(instrToAdd, ins)
 		}
 	}
+	tr.SetTotal(len(instrToAdd))
 	err = s.AddInstruments(instrToAdd)
 	if err != nil {
-		setLastInstrumentError(err)
-		return
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync instruments:", err)
+		return err
 	}
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Success sync instruments. Added", len(instrToAdd))
+	tr.Advance(len(instrToAdd))
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Success sync instruments. Added", len(instrToAdd))
+	return nil
 }
 
-// GetSyncInstrumentsStatus gets status of instruments sync
-func GetSyncInstrumentsStatus() SyncStatus {
-	if atomic.LoadInt32(&syncInstrumentsIsRunning) == 1 {
-		return SyncStatus{Status: Processing}
-	}
-	err := lastSyncIstrumentsError.Load()
-	if err != nil {
-		se := err.(syncError)
-		if se.IsNotEmpty {
-			resp := SyncStatus{Status: Err, Error: se.Error}
-			lastSyncIstrumentsError.Store(syncError{Error: nil, IsNotEmpty: false})
-			return resp
-		}
-	}
-	return SyncStatus{Status: Ok}
+// IsSyncingInstruments checks whether instruments sync is in process
+func IsSyncingInstruments() bool {
+	return atomic.LoadInt32(&syncInstrumentsIsRunning) == 1
 }
 
-func setLastInstrumentError(err error) {
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-	lastSyncIstrumentsError.Store(syncError{Error: err, IsNotEmpty: true})
+type instrumentsResult struct {
+	URL         string
+	Instruments []models.Instrument
+	Err         error
 }
 
-func getInstruments(client *http.Client, token string, url string, c chan []models.Instrument) {
+func getInstruments(client *http.Client, token string, url string, c chan instrumentsResult) {
 	var respObj struct {
 		Payload struct {
 			Total int                 `json:"total"`
@@ -106,21 +105,21 @@ func getInstruments(client *http.Client, token string, url string, c chan []mode
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		c <- nil
+		c <- instrumentsResult{URL: url, Err: err}
 		return
 	}
 	req.Header.Add("Authorization", "Bearer "+token)
 	resp, err := client.Do(req)
 	if err != nil {
-		c <- nil
+		c <- instrumentsResult{URL: url, Err: err}
 		return
 	}
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		c <- nil
+		c <- instrumentsResult{URL: url, Err: err}
 		return
 	}
-	json.Unmarshal(body, &respObj)
-	c <- respObj.Payload.Ins
+	err = json.Unmarshal(body, &respObj)
+	c <- instrumentsResult{URL: url, Instruments: respObj.Payload.Ins, Err: err}
 }
@@ -0,0 +1,311 @@
+package tcs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/models/provider"
+	"github.com/kaseat/pManager/storage"
+)
+
+// logins having operations sync in process. Last update time is kept per login,
+// so syncs of the same login must not overlap
+var syncOperationsRunning = struct {
+	sync.Mutex
+	logins map[string]bool
+}{logins: make(map[string]bool)}
+
+// operations are imported since this date unless other specified
+var defaultOperationsFrom = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// cash ISINs match currency instruments of tcs API
+var cashISIN = map[currency.Type]string{
+	currency.RUB: "RU000Z13FK33",
+	currency.USD: "BBG0013HGFT4",
+	currency.EUR: "BBG0013HJJ31",
+}
+
+type tcsOperation struct {
+	ID               string        `json:"id"`
+	Status           string        `json:"status"`
+	Currency         currency.Type `json:"currency"`
+	Payment          float64       `json:"payment"`
+	Price            float64       `json:"price"`
+	Quantity         int64         `json:"quantity"`
+	QuantityExecuted int64         `json:"quantityExecuted"`
+	FIGI             string        `json:"figi"`
+	Date             time.Time     `json:"date"`
+	OperationType    string        `json:"operationType"`
+}
+
+// SyncOperations imports operations of given broker account from tcs API
+// into given portfolio. Empty account means default broker account.
+// Dates are expected in 2006-01-02 format, empty from continues
+// since last sync. Every imported tcs operation is recorded to the job tracker.
+// Last sync time does not pass operations failed to import, so next sync retries them
+func SyncOperations(tr *jobs.Tracker, login, pid, account, from, to string) error {
+	syncOperationsRunning.Lock()
+	if syncOperationsRunning.logins[login] {
+		syncOperationsRunning.Unlock()
+		return errors.New("operations sync already in process")
+	}
+	syncOperationsRunning.logins[login] = true
+	syncOperationsRunning.Unlock()
+	defer func() {
+		syncOperationsRunning.Lock()
+		delete(syncOperationsRunning.logins, login)
+		syncOperationsRunning.Unlock()
+	}()
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Begin sync tcs operations for", login)
+	s := storage.GetStorage()
+	token, _ := s.GetTcsToken()
+	if token == "" {
+		err := errors.New("No TCS token found")
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync tcs operations:", err)
+		return err
+	}
+
+	start, end, err := getOperationsRange(login, from, to)
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync tcs operations:", err)
+		return err
+	}
+
+	instr, err := s.GetAllInstruments()
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync tcs operations:", err)
+		return err
+	}
+	instrMap := make(map[string]models.Instrument)
+	for _, ins := range instr {
+		instrMap[ins.FIGI] = ins
+	}
+
+	tcsOps, err := getOperations(&http.Client{}, token, account, start, end)
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync tcs operations:", err)
+		return err
+	}
+
+	tr.SetTotal(len(tcsOps))
+	operations := make([]models.Operation, 0, len(tcsOps))
+	synced := end
+	for _, op := range tcsOps {
+		if op.Status != "Done" {
+			tr.Advance(1)
+			continue
+		}
+		ops, err := convertOperation(op, instrMap)
+		if err != nil {
+			tr.Done(op.ID, err)
+			if op.Date.Before(synced) {
+				synced = op.Date.UTC()
+			}
+			continue
+		}
+		operations = append(operations, ops...)
+		tr.Done(op.ID, nil)
+	}
+
+	added := 0
+	if len(operations) != 0 {
+		sort.Sort(models.OperationSorter(operations))
+		ids, err := s.AddOperations(pid, operations)
+		if err != nil {
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync tcs operations:", err)
+			return err
+		}
+		added = countAdded(ids)
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "save opertions for", login, "to storge OK. Skipped", len(ids)-added, "duplicates")
+	}
+
+	last, err := s.GetUserLastUpdateTime(login, provider.Tcs)
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync tcs operations:", err)
+		return err
+	}
+	if synced.Before(end) {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Some tcs operations failed, next sync starts from", synced.Format("2006-01-02 15:04:05"))
+	}
+	if synced.After(last) {
+		err = s.AddUserLastUpdateTime(login, provider.Tcs, synced)
+		if err != nil {
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync tcs operations:", err)
+			return err
+		}
+	}
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Success sync tcs operations. Added", added)
+	return nil
+}
+
+func getOperationsRange(login, from, to string) (time.Time, time.Time, error) {
+	end := time.Now().UTC()
+	if to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid 'to' date format. Expected 2006-01-02")
+		}
+		end = t
+	}
+	if from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid 'from' date format. Expected 2006-01-02")
+		}
+		return t, end, nil
+	}
+	t, err := storage.GetStorage().GetUserLastUpdateTime(login, provider.Tcs)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if t.IsZero() {
+		t = defaultOperationsFrom
+	}
+	return t, end, nil
+}
+
+// convertOperation maps tcs operation to storage operations.
+// Trades are recorded with security ISIN, cash flows use currency ticker.
+// Cash flows bound to a security (coupons, dividends, their taxes and repayments)
+// keep security ISIN
+func convertOperation(op tcsOperation, instruments map[string]models.Instrument) ([]models.Operation, error) {
+	cash := models.Operation{
+		Currency: op.Currency,
+		Price:    math.Abs(op.Payment),
+		Volume:   1,
+		ISIN:     cashISIN[op.Currency],
+		Ticker:   string(op.Currency),
+		DateTime: op.Date,
+		// operation Id makes repeated imports idempotent
+		Source:     provider.Tcs,
+		ExternalID: op.ID,
+	}
+
+	switch op.OperationType {
+	case "PayIn":
+		cash.OperationType = operation.PayIn
+		return []models.Operation{cash}, nil
+	case "PayOut":
+		cash.OperationType = operation.PayOut
+		return []models.Operation{cash}, nil
+	case "BrokerCommission", "ServiceCommission", "MarginCommission", "OtherCommission":
+		cash.OperationType = operation.BrokerageFee
+		return []models.Operation{cash}, nil
+	case "ExchangeCommission":
+		cash.OperationType = operation.ExchangeFee
+		return []models.Operation{cash}, nil
+	case "Tax", "TaxLucre", "TaxBack":
+		// tax refund is recorded as negative tax
+		cash.OperationType = operation.Tax
+		cash.Price = -op.Payment
+		return []models.Operation{cash}, nil
+	}
+
+	ins, ok := instruments[op.FIGI]
+	if !ok {
+		return nil, fmt.Errorf("unknown instrument with FIGI '%s'", op.FIGI)
+	}
+	cash.FIGI = ins.FIGI
+	cash.ISIN = ins.ISIN
+	cash.Ticker = ins.Ticker
+
+	switch op.OperationType {
+	case "Buy", "BuyCard", "Sell":
+		trade := cash
+		trade.OperationType = operation.Buy
+		if op.OperationType == "Sell" {
+			trade.OperationType = operation.Sell
+		}
+		trade.Price = op.Price
+		trade.Volume = op.QuantityExecuted
+		if trade.Volume == 0 {
+			trade.Volume = op.Quantity
+		}
+		return []models.Operation{trade}, nil
+	case "Coupon":
+		cash.OperationType = operation.Coupon
+		return []models.Operation{cash}, nil
+	case "Dividend":
+		cash.OperationType = operation.Dividend
+		return []models.Operation{cash}, nil
+	case "TaxDividend", "TaxCoupon":
+		cash.OperationType = operation.WithholdingTax
+		cash.Price = -op.Payment
+		return []models.Operation{cash}, nil
+	case "PartRepayment":
+		// amortization brings cash but keeps the bonds
+		cash.OperationType = operation.Amortization
+		return []models.Operation{cash}, nil
+	case "Repayment":
+		// redemption closes the whole position at the redemption proceeds
+		cash.OperationType = operation.Buyback
+		if op.Quantity > 0 {
+			cash.Volume = op.Quantity
+			cash.Price = math.Abs(op.Payment) / float64(op.Quantity)
+		}
+		return []models.Operation{cash}, nil
+	}
+	return nil, fmt.Errorf("unsupported operation type '%s'", op.OperationType)
+}
+
+func getOperations(client *http.Client, token, account string, from, to time.Time) ([]tcsOperation, error) {
+	var respObj struct {
+		Payload struct {
+			Operations []tcsOperation `json:"operations"`
+		} `json:"payload"`
+	}
+
+	req, err := http.NewRequest("GET", baseURL+"/operations", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+token)
+	query := url.Values{
+		"from": {from.Format("2006-01-02T15:04:05Z")},
+		"to":   {to.Format("2006-01-02T15:04:05Z")},
+	}
+	if account != "" {
+		query.Set("brokerAccountId", account)
+	}
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(body, &respObj)
+	if err != nil {
+		return nil, err
+	}
+	return respObj.Payload.Operations, nil
+}
+
+func countAdded(ids []string) int {
+	n := 0
+	for _, id := range ids {
+		if id != "" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,196 @@
+package tcs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/config"
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/job"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/models/provider"
+	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/utils"
+)
+
+func TestSyncOperations(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/operations.json")
+	if err != nil {
+		t.Fatalf("Fail! Could not read fixture: %s", err)
+	}
+	queries := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/operations" || r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		queries = append(queries, r.URL.RawQuery)
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+	Configure(config.Provider{URL: srv.URL})
+	defer Configure(config.Default().Sync.Tcs)
+	storage.SwitchStorage(storage.Memory)
+	s := storage.GetStorage()
+
+	login := "tcs_operations_test"
+	uid, _ := s.AddUser(login, "tcs@test.com", "hash")
+	pid, _ := s.AddPortfolio(uid, models.Portfolio{Name: "tcs"})
+	s.AddTcsToken("test-token")
+	defer s.DeleteTcsToken()
+	s.AddInstruments([]models.Instrument{
+		{FIGI: "BBG004730N88", ISIN: "RU0009029540", Ticker: "SBER"},
+		{FIGI: "BBG00R05JT04", ISIN: "RU000A101FA1", Ticker: "RU000A101FA1"},
+	})
+
+	tr, _ := jobs.Start(job.Tcs, login)
+	err = SyncOperations(tr, login, pid, "2000123456", "", "2020-12-31")
+	j := tr.Finish(err)
+	if err != nil {
+		t.Fatalf("Fail! Could not sync operations: %s", err)
+	}
+
+	expectedQuery := "brokerAccountId=2000123456&from=2018-01-01T00%3A00%3A00Z&to=2020-12-31T00%3A00%3A00Z"
+	if len(queries) == 1 && queries[0] == expectedQuery {
+		t.Logf("Success! Expected %v, got %v", expectedQuery, queries)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", expectedQuery, queries)
+	}
+
+	if j.Total == 9 && j.Processed == 9 && len(j.Errors) == 1 && j.Errors[0].Item == "1009" {
+		t.Logf("Success! Got expected job %v", j)
+	} else {
+		t.Errorf("Fail! Unexpected job %v", j)
+	}
+
+	ops, _ := s.GetOperations(pid, "", "", "", "")
+	byType := make(map[operation.Type][]models.Operation)
+	for _, op := range ops {
+		byType[op.OperationType] = append(byType[op.OperationType], op)
+	}
+	if len(ops) == 7 {
+		t.Logf("Success! Expected %v operations, got %v", 7, len(ops))
+	} else {
+		t.Errorf("Fail! Expected %v operations, got %v", 7, len(ops))
+	}
+
+	cases := []struct {
+		Type   operation.Type
+		ISIN   string
+		Ticker string
+		Price  float64
+		Volume int64
+	}{
+		{operation.PayIn, "RU000Z13FK33", "RUB", 50000, 1},
+		{operation.Buy, "RU0009029540", "SBER", 245.5, 20},
+		{operation.BrokerageFee, "RU000Z13FK33", "RUB", 14.73, 1},
+		{operation.Coupon, "RU000A101FA1", "RU000A101FA1", 184.5, 1},
+		{operation.WithholdingTax, "RU000A101FA1", "RU000A101FA1", 24, 1},
+		{operation.Dividend, "RU0009029540", "SBER", 372, 1},
+		{operation.Buyback, "RU000A101FA1", "RU000A101FA1", 1000, 5},
+	}
+	for _, c := range cases {
+		found := byType[c.Type]
+		if len(found) == 1 && found[0].ISIN == c.ISIN && found[0].Ticker == c.Ticker &&
+			found[0].Price == c.Price && found[0].Volume == c.Volume {
+			t.Logf("Success! Got expected %s operation %v", c.Type, found[0])
+		} else {
+			t.Errorf("Fail! Unexpected %s operations %v", c.Type, found)
+		}
+	}
+
+	// failed operation 1009 is retried by next sync
+	upd, _ := s.GetUserLastUpdateTime(login, provider.Tcs)
+	expected := time.Date(2020, 10, 5, 12, 30, 0, 0, time.UTC)
+	if upd.Equal(expected) {
+		t.Logf("Success! Expected %v, got %v", expected, upd)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", expected, upd)
+	}
+
+	// next sync continues from last update
+	queries = queries[:0]
+	SyncOperations(nil, login, pid, "", "", "2021-01-31")
+	expectedQuery = "from=2020-10-05T12%3A30%3A00Z&to=2021-01-31T00%3A00%3A00Z"
+	if len(queries) == 1 && queries[0] == expectedQuery {
+		t.Logf("Success! Expected %v, got %v", expectedQuery, queries)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", expectedQuery, queries)
+	}
+
+	// sync of one login does not block other logins
+	syncOperationsRunning.logins["other_login"] = true
+	err = SyncOperations(nil, "other_login", pid, "", "", "2021-01-31")
+	if err != nil && err.Error() == "operations sync already in process" {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected running sync error, got %v", err)
+	}
+	delete(syncOperationsRunning.logins, "other_login")
+	queries = queries[:0]
+	err = SyncOperations(nil, login, pid, "", "", "2021-01-31")
+	if err == nil && len(queries) == 1 {
+		t.Logf("Success! Sync of %s is not blocked by other login", login)
+	} else {
+		t.Errorf("Fail! Expected sync of %s to run, got %v", login, err)
+	}
+
+	s.DeleteUser(login)
+}
+
+func TestConvertRepayment(t *testing.T) {
+	storage.SwitchStorage(storage.Memory)
+	s := storage.GetStorage()
+	uid, _ := s.AddUser("tcs_repayment_test", "tcs@test.com", "hash")
+	defer s.DeleteUser("tcs_repayment_test")
+	pid, _ := s.AddPortfolio(uid, models.Portfolio{Name: "tcs"})
+
+	instruments := map[string]models.Instrument{
+		"BBG00R05JT04": {FIGI: "BBG00R05JT04", ISIN: "RU000A101FA1", Ticker: "RU000A101FA1"},
+	}
+	buy := tcsOperation{OperationType: "Buy", FIGI: "BBG00R05JT04", Currency: "RUB", ID: "1",
+		Date: time.Date(2020, 3, 4, 9, 0, 0, 0, time.UTC), Quantity: 5, QuantityExecuted: 5, Price: 990, Payment: -4950}
+	part := tcsOperation{OperationType: "PartRepayment", FIGI: "BBG00R05JT04", Currency: "RUB", ID: "2",
+		Date: time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC), Payment: 1500}
+	full := tcsOperation{OperationType: "Repayment", FIGI: "BBG00R05JT04", Currency: "RUB", ID: "3",
+		Date: time.Date(2020, 9, 1, 9, 0, 0, 0, time.UTC), Quantity: 5, Payment: 3500}
+
+	ops := []models.Operation{}
+	for _, op := range []tcsOperation{buy, part, full} {
+		converted, err := convertOperation(op, instruments)
+		if err != nil {
+			t.Fatalf("Fail! Could not convert operation %v: %s", op, err)
+		}
+		ops = append(ops, converted...)
+	}
+	for i := range ops {
+		ops[i].PortfolioID = pid
+	}
+	s.AddOperations(pid, ops)
+
+	shares, _ := s.GetShares(pid, "2020-07-01T00:00:00Z")
+	if len(shares) == 2 && shares[0].ISIN == "RU000A101FA1" && shares[0].Volume == 5 {
+		t.Logf("Success! Amortization keeps position %v", shares)
+	} else {
+		t.Errorf("Fail! Unexpected position after amortization %v", shares)
+	}
+
+	shares, _ = s.GetShares(pid, "2020-10-01T00:00:00Z")
+	if len(shares) == 1 && shares[0].ISIN == "RUB" && shares[0].Price == 50 {
+		t.Logf("Success! Redemption closes position %v", shares)
+	} else {
+		t.Errorf("Fail! Unexpected position after redemption %v", shares)
+	}
+
+	realized := utils.GetRealized(ops)
+	if len(realized) == 1 && realized[0].Volume == 5 && realized[0].Price == 700 &&
+		realized[0].Proceeds == 3500 && realized[0].CostBasis == 4950 && realized[0].Gain == -1450 {
+		t.Logf("Success! Got expected realized trade %v", realized[0])
+	} else {
+		t.Errorf("Fail! Unexpected realized trades %v", realized)
+	}
+}
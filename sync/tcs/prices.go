@@ -2,63 +2,52 @@ package tcs
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"sync/atomic"
 	"time"
 
 	"github.com/kaseat/pManager/models"
 	"github.com/kaseat/pManager/storage"
+	"github.com/kaseat/pManager/sync/prices"
 )
 
-const candlesURL = "https://api-invest.tinkoff.ru/openapi/sandbox/market/candles"
+func init() {
+	prices.RegisterFallback(PriceProvider{})
+}
 
-var lastSyncPricesError atomic.Value
-var syncPricesIsRunning int32
+// PriceProvider fetches daily prices from Tinkoff API.
+// Instruments are looked up by FIGI so it suits any exchange
+type PriceProvider struct{}
 
-// SyncPrices sync daily prices for prices
-func SyncPrices() {
-	defer atomic.StoreInt32(&syncPricesIsRunning, 0)
-	if atomic.LoadInt32(&syncPricesIsRunning) == 1 {
-		return
-	}
-	atomic.StoreInt32(&syncPricesIsRunning, 1)
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Begin sync prices")
-	s := storage.GetStorage()
-	token, _ := s.GetTcsToken()
-	instruments, _ := s.GetAllInstruments()
-	client := &http.Client{}
+// Name returns provider name
+func (PriceProvider) Name() string {
+	return "TCS"
+}
 
-	for _, x := range instruments {
-		beginDate := x.PriceUptdTime
-		if beginDate.IsZero() {
-			beginDate = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+// Fetch returns daily close prices of given instrument.
+// Range is split into chunks since API limits candles interval
+func (PriceProvider) Fetch(client *http.Client, ins models.Instrument, from, to time.Time) ([]models.Price, error) {
+	token, err := storage.GetStorage().GetTcsToken()
+	if err != nil {
+		return nil, err
+	}
+	result := []models.Price{}
+	for _, ch := range getTimeChunks(from, to, 12) {
+		if ch.From == ch.To {
+			break
 		}
-
-		endDate := today()
-		chunks := getTimeChunks(beginDate, endDate, 12)
-		for _, ch := range chunks {
-			if ch.From == ch.To {
-				break
-			}
-			time.Sleep(500 * time.Millisecond)
-			now := time.Now().Format("2006-02-01 15:04:05")
-			from := ch.From.Format("2006-01-02")
-			to := ch.To.Format("2006-01-02")
-			if err := s.AddPrices(getPrices(client, token, x, ch.From, ch.To)); err != nil {
-				fmt.Printf("%s Sync price for %s from %s to %s error: %v\n", now, x.Ticker, from, to, err)
-			} else {
-				s.SetInstrumentPriceUptdTime(x.SecID, ch.To)
-				fmt.Printf("%s Sync price for %s from %s to %s succeded\n", now, x.Ticker, from, to)
-			}
+		time.Sleep(500 * time.Millisecond)
+		chunkPrices, err := getPrices(client, token, ins, ch.From, ch.To)
+		if err != nil {
+			return nil, err
 		}
+		result = append(result, chunkPrices...)
 	}
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Success sync prices")
+	return result, nil
 }
 
-func getPrices(client *http.Client, token string, ins models.Instrument, from, to time.Time) []models.Price {
+func getPrices(client *http.Client, token string, ins models.Instrument, from, to time.Time) ([]models.Price, error) {
 	var respObj struct {
 		Payload struct {
 			Candles []struct {
@@ -69,10 +58,9 @@ func getPrices(client *http.Client, token string, ins models.Instrument, from, t
 		} `json:"payload"`
 	}
 
-	req, err := http.NewRequest("GET", candlesURL, nil)
+	req, err := http.NewRequest("GET", baseURL+"/market/candles", nil)
 	if err != nil {
-		setLastPricesError(err)
-		return nil
+		return nil, err
 	}
 	req.Header.Add("Authorization", "Bearer "+token)
 	req.URL.RawQuery = url.Values{
@@ -84,19 +72,16 @@ func getPrices(client *http.Client, token string, ins models.Instrument, from, t
 
 	resp, err := client.Do(req)
 	if err != nil {
-		setLastPricesError(err)
-		return nil
+		return nil, err
 	}
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		setLastPricesError(err)
-		return nil
+		return nil, err
 	}
 	err = json.Unmarshal(body, &respObj)
 	if err != nil {
-		setLastPricesError(err)
-		return nil
+		return nil, err
 	}
 
 	result := make([]models.Price, len(respObj.Payload.Candles))
@@ -109,10 +94,5 @@ func getPrices(client *http.Client, token string, ins models.Instrument, from, t
 		}
 	}
 
-	return result
-}
-
-func setLastPricesError(err error) {
-	fmt.Println(time.Now().Format("2006-02-01 15:04:05"), "Error sync instruments:", err)
-	lastSyncPricesError.Store(syncError{Error: err, IsNotEmpty: true})
+	return result, nil
 }
@@ -0,0 +1,93 @@
+package vtb
+
+import (
+	"strings"
+
+	"github.com/kaseat/pManager/models"
+)
+
+const tradesTableMarker = "заключенные в отчетном периоде сделки с ценными бумагами"
+const cashFlowTableMarker = "движение денежных средств"
+
+type report struct {
+	Operations []models.Operation
+	CashFlow   []models.Operation
+}
+
+// parseReport parses VTB broker report workbook. Columns are looked up
+// by header captions so that reports of different years are supported
+func parseReport(data []byte) (report, error) {
+	result := report{}
+	rows, err := readRows(data)
+	if err != nil {
+		return result, err
+	}
+
+	for i := 0; i < len(rows); i++ {
+		if len(nonEmpty(rows[i])) != 1 {
+			continue
+		}
+		switch title := normalize(nonEmpty(rows[i])[0]); {
+		case strings.HasPrefix(title, tradesTableMarker):
+			table := readTable(rows[i+1:])
+			result.Operations = append(result.Operations, processTradesTable(table)...)
+			i += len(table)
+		case strings.HasPrefix(title, cashFlowTableMarker):
+			table := readTable(rows[i+1:])
+			result.CashFlow = append(result.CashFlow, processCashFlowTable(table)...)
+			i += len(table)
+		}
+	}
+	return result, nil
+}
+
+// readTable returns table header followed by its rows.
+// Table ends with next section title, which is the only filled cell of a row
+func readTable(rows [][]string) [][]string {
+	table := [][]string{}
+	for _, row := range rows {
+		cells := len(nonEmpty(row))
+		if cells == 0 {
+			if len(table) == 0 {
+				continue
+			}
+			break
+		}
+		if cells == 1 {
+			break
+		}
+		table = append(table, row)
+	}
+	return table
+}
+
+// column returns index of the first column which header starts with given caption
+func column(header []string, caption string) int {
+	for i, h := range header {
+		if strings.HasPrefix(normalize(h), caption) {
+			return i
+		}
+	}
+	return -1
+}
+
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func nonEmpty(row []string) []string {
+	result := []string{}
+	for _, c := range row {
+		if strings.TrimSpace(c) != "" {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,159 @@
+package vtb
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/storage"
+)
+
+var reportRows = [][]string{
+	{"Отчет Банка ВТБ (ПАО) за период с 01.03.2020 по 31.03.2020"},
+	{},
+	{"Заключенные в отчетном периоде сделки с ценными бумагами"},
+	{"Наименование ценной бумаги, № гос. регистрации, ISIN", "№ сделки", "Дата и время заключения сделки", "Вид сделки",
+		"Количество ЦБ, шт.", "Цена", "Валюта цены", "Сумма сделки в валюте расчетов", "НКД", "Валюта расчетов",
+		"Комиссия Банка за расчет по сделке", "Комиссия Банка за заключение сделки"},
+	{"Сбербанк ао, 10301481B, RU0009029540", "1001", "03.03.2020 11:02:31", "Покупка", "20", "245.5", "RUR", "4910", "0", "RUR", "0.49", "2.46"},
+	{"ОФЗ 26209, 26209RMFS, RU000A0JSMA2", "1002", "43894.5", "Продажа", "5", "101.2", "%", "5060", "12.35", "RUR", "0", "2.53"},
+	{"Итого", "", "", "", "", "", "", "9970", "", "", "", ""},
+	{"Движение денежных средств"},
+	{"Дата", "Сумма", "Валюта", "Тип операции", "Комментарий"},
+	{"02.03.2020", "50000", "RUR", "Зачисление денежных средств", "Перевод с банковского счета"},
+	{"03.03.2020", "-4910", "RUR", "Расчеты по сделке", "Сделка 1001"},
+	{"15.03.2020", "184,5", "RUR", "Купонный доход", "Купон по ОФЗ 26209, ISIN RU000A0JSMA2"},
+	{"15.03.2020", "-24", "RUR", "НДФЛ", "Налог с купона, ISIN RU000A0JSMA2"},
+	{"20.03.2020", "-150", "RUR", "Вывод денежных средств", ""},
+	{"Остатки денежных средств"},
+	{"Валюта", "Входящий остаток", "Исходящий остаток"},
+	{"RUR", "0", "44926"},
+}
+
+func TestParseReport(t *testing.T) {
+	r, err := parseReport(buildXlsx(reportRows))
+	if err != nil {
+		t.Fatalf("Fail! Could not parse report: %s", err)
+	}
+
+	msk := time.FixedZone("MSK", 3*60*60)
+	expectedOps := []models.Operation{
+		{Currency: currency.RUB, Price: 245.5, Volume: 20, ISIN: "RU0009029540", DateTime: time.Date(2020, 3, 3, 11, 2, 31, 0, msk), OperationType: operation.Buy},
+		{Currency: currency.RUB, Price: 2.46, Volume: 1, ISIN: "RU000Z13FK33", Ticker: "RUB", DateTime: time.Date(2020, 3, 3, 11, 2, 31, 0, msk), OperationType: operation.BrokerageFee},
+		{Currency: currency.RUB, Price: 0.49, Volume: 1, ISIN: "RU000Z13FK33", Ticker: "RUB", DateTime: time.Date(2020, 3, 3, 11, 2, 31, 0, msk), OperationType: operation.ExchangeFee},
+		{Currency: currency.RUB, Price: 1012, Volume: 5, ISIN: "RU000A0JSMA2", DateTime: time.Date(2020, 3, 4, 12, 0, 0, 0, msk), OperationType: operation.Sell},
+		{Currency: currency.RUB, Price: 12.35, Volume: 1, ISIN: "RU000Z13FK33", Ticker: "RUB", DateTime: time.Date(2020, 3, 4, 12, 0, 0, 0, msk), OperationType: operation.AccInterestSell},
+		{Currency: currency.RUB, Price: 2.53, Volume: 1, ISIN: "RU000Z13FK33", Ticker: "RUB", DateTime: time.Date(2020, 3, 4, 12, 0, 0, 0, msk), OperationType: operation.BrokerageFee},
+	}
+	compareOperations(t, expectedOps, r.Operations)
+
+	expectedCash := []models.Operation{
+		{Currency: currency.RUB, Price: 50000, Volume: 1, ISIN: "RU000Z13FK33", Ticker: "RUB", DateTime: time.Date(2020, 3, 2, 10, 0, 0, 0, msk), OperationType: operation.PayIn},
+		{Currency: currency.RUB, Price: 184.5, Volume: 1, ISIN: "RU000A0JSMA2", DateTime: time.Date(2020, 3, 15, 10, 0, 0, 0, msk), OperationType: operation.Coupon},
+		{Currency: currency.RUB, Price: 24, Volume: 1, ISIN: "RU000A0JSMA2", DateTime: time.Date(2020, 3, 15, 10, 0, 0, 0, msk), OperationType: operation.WithholdingTax},
+		{Currency: currency.RUB, Price: 150, Volume: 1, ISIN: "RU000Z13FK33", Ticker: "RUB", DateTime: time.Date(2020, 3, 20, 10, 0, 0, 0, msk), OperationType: operation.PayOut},
+	}
+	compareOperations(t, expectedCash, r.CashFlow)
+}
+
+func TestParseReports(t *testing.T) {
+	storage.SwitchStorage(storage.Memory)
+	s := storage.GetStorage()
+	s.AddInstruments([]models.Instrument{{FIGI: "BBG004730N88", ISIN: "RU0009029540", Ticker: "SBER"}})
+
+	ops, err := ParseReports([][]byte{buildXlsx(reportRows)})
+	if err != nil {
+		t.Fatalf("Fail! Could not parse reports: %s", err)
+	}
+	if len(ops) == 10 && ops[0].OperationType == operation.PayIn {
+		t.Logf("Success! Got %v sorted operations", len(ops))
+	} else {
+		t.Errorf("Fail! Unexpected operations %v", ops)
+	}
+	for _, op := range ops {
+		switch op.ISIN {
+		case "RU0009029540":
+			if op.Ticker != "SBER" || op.FIGI != "BBG004730N88" {
+				t.Errorf("Fail! Expected SBER ticker, got %v", op)
+			}
+		case "RU000A0JSMA2":
+			if op.Ticker != "RU000A0JSMA2" {
+				t.Errorf("Fail! Expected ISIN as ticker for unknown security, got %v", op)
+			}
+		}
+	}
+
+	_, err = ParseReports([][]byte{[]byte("<html></html>")})
+	if err != nil {
+		t.Logf("Success! Got expected error: %s", err)
+	} else {
+		t.Errorf("Fail! Expected error for unknown report format")
+	}
+}
+
+func compareOperations(t *testing.T, expected, actual []models.Operation) {
+	if len(expected) != len(actual) {
+		t.Errorf("Fail! Expected %v operations, got %v: %v", len(expected), len(actual), actual)
+		return
+	}
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		if e.Currency == a.Currency && e.Price == a.Price && e.Volume == a.Volume && e.ISIN == a.ISIN &&
+			e.Ticker == a.Ticker && e.DateTime.Equal(a.DateTime) && e.OperationType == a.OperationType {
+			t.Logf("Success! Expected %v, got %v", e, a)
+		} else {
+			t.Errorf("Fail! Expected %v, got %v", e, a)
+		}
+	}
+}
+
+// buildXlsx makes minimal XLSX workbook. Numbers are stored
+// as numeric cells, text goes to shared strings
+func buildXlsx(rows [][]string) []byte {
+	shared := []string{}
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8"?><worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, v := range row {
+			if v == "" {
+				continue
+			}
+			ref := fmt.Sprintf("%c%d", 'A'+j, i+1)
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, v)
+			} else {
+				fmt.Fprintf(&sheet, `<c r="%s" t="s"><v>%d</v></c>`, ref, len(shared))
+				shared = append(shared, v)
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var sst strings.Builder
+	sst.WriteString(`<?xml version="1.0" encoding="UTF-8"?><sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	for _, s := range shared {
+		fmt.Fprintf(&sst, `<si><t>%s</t></si>`, s)
+	}
+	sst.WriteString(`</sst>`)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"xl/worksheets/sheet1.xml": sheet.String(),
+		"xl/sharedStrings.xml":     sst.String(),
+	} {
+		f, _ := zw.Create(name)
+		f.Write([]byte(content))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
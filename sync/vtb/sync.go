@@ -0,0 +1,174 @@
+package vtb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaseat/pManager/gmail"
+	"github.com/kaseat/pManager/jobs"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/provider"
+	"github.com/kaseat/pManager/storage"
+)
+
+const gmailQuery = "from:vtb.ru has:attachment {filename:xls filename:xlsx}"
+
+var isSync int32
+
+// IsReport checks whether given file looks like VTB broker report workbook
+func IsReport(data []byte) bool {
+	return bytes.HasPrefix(data, xlsxMagic) || bytes.HasPrefix(data, xlsMagic)
+}
+
+// ParseReports parses given VTB broker reports.
+// Securities are resolved by ISIN from instruments storage
+func ParseReports(reports [][]byte) ([]models.Operation, error) {
+	operations := []models.Operation{}
+	for i, data := range reports {
+		r, err := parseReport(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse report #%d: %v", i+1, err)
+		}
+		operations = append(operations, r.Operations...)
+		operations = append(operations, r.CashFlow...)
+	}
+
+	instr, err := storage.GetStorage().GetAllInstruments()
+	if err != nil {
+		return nil, err
+	}
+	instrMap := make(map[string]models.Instrument)
+	for _, ins := range instr {
+		instrMap[ins.ISIN] = ins
+	}
+	for i, op := range operations {
+		if op.Ticker != "" {
+			continue
+		}
+		if ins, ok := instrMap[op.ISIN]; ok {
+			operations[i].FIGI = ins.FIGI
+			operations[i].Ticker = ins.Ticker
+		} else {
+			operations[i].Ticker = op.ISIN
+		}
+	}
+	sort.Sort(models.OperationSorter(operations))
+	return operations, nil
+}
+
+// ImportReports parses given VTB broker reports and saves operations into given portfolio.
+// Returns added operations, already imported ones are skipped
+func ImportReports(pid string, reports [][]byte) ([]models.Operation, error) {
+	operations, err := ParseReports(reports)
+	if err != nil {
+		return nil, err
+	}
+	added := []models.Operation{}
+	if len(operations) != 0 {
+		ids, err := storage.GetStorage().AddOperations(pid, operations)
+		if err != nil {
+			return nil, err
+		}
+		for i, id := range ids {
+			if id != "" {
+				added = append(added, operations[i])
+			}
+		}
+	}
+	return added, nil
+}
+
+// SyncGmail imports VTB broker reports found in user's Gmail
+// into given portfolio. Every found report message is recorded to the job tracker
+func SyncGmail(tr *jobs.Tracker, login, pid, from, to string) error {
+	if !atomic.CompareAndSwapInt32(&isSync, 0, 1) {
+		return errors.New("Sync already in process")
+	}
+	defer atomic.StoreInt32(&isSync, 0)
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Begin sync vtb operations via Gmail")
+	srv, err := gmail.GetClient().GetServiceForUser(login)
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync vtb operations:", err)
+		return err
+	}
+
+	s := storage.GetStorage()
+	t, err := s.GetUserLastUpdateTime(login, provider.Vtb)
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync vtb operations:", err)
+		return err
+	}
+
+	query := gmailQuery
+	if from == "" && to == "" && !t.IsZero() {
+		query = fmt.Sprintf("%s after:%s", query, t.Format("2006/01/02"))
+	}
+	if from != "" {
+		query = fmt.Sprintf("%s after:%s", query, from)
+	}
+	if to != "" {
+		query = fmt.Sprintf("%s before:%s", query, to)
+	}
+
+	r, err := srv.Users.Messages.List("me").Q(query).Do()
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync vtb operations:", err)
+		return err
+	}
+
+	tr.SetTotal(len(r.Messages))
+	reports := [][]byte{}
+	lastUptdTime := time.Time{}
+	for _, m := range r.Messages {
+		msg, err := srv.Users.Messages.Get("me", m.Id).Do()
+		if err != nil {
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync vtb operations:", err)
+			return err
+		}
+		for _, p := range msg.Payload.Parts {
+			name := strings.ToLower(p.Filename)
+			if !strings.HasSuffix(name, ".xls") && !strings.HasSuffix(name, ".xlsx") {
+				continue
+			}
+			att, err := srv.Users.Messages.Attachments.Get("me", m.Id, p.Body.AttachmentId).Do()
+			if err != nil {
+				fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync vtb operations:", err)
+				return err
+			}
+			b, err := base64.URLEncoding.DecodeString(att.Data)
+			if err != nil {
+				fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync vtb operations:", err)
+				return err
+			}
+			reports = append(reports, b)
+		}
+		msgTime := time.Unix(msg.InternalDate/1000, 0)
+		if msgTime.After(lastUptdTime) {
+			lastUptdTime = msgTime
+		}
+		tr.Done(m.Id, nil)
+	}
+
+	ops, err := ImportReports(pid, reports)
+	if err != nil {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync vtb operations:", err)
+		return err
+	}
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "save", len(ops), "opertions for", login, "to storge OK")
+
+	if !lastUptdTime.IsZero() {
+		err = s.AddUserLastUpdateTime(login, provider.Vtb, lastUptdTime.AddDate(0, 0, 1))
+		if err != nil {
+			fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Error sync vtb operations:", err)
+			return err
+		}
+	}
+	fmt.Println(time.Now().Format("2006-01-02 15:04:05"), "Success sync vtb operations via Gmail")
+	return nil
+}
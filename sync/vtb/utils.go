@@ -0,0 +1,229 @@
+package vtb
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/models/provider"
+)
+
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+var isinRegexp = regexp.MustCompile(`\b[A-Z]{2}[A-Z0-9]{9}[0-9]\b`)
+
+var cashISIN = map[currency.Type]string{
+	currency.RUB: "RU000Z13FK33",
+	currency.USD: "BBG0013HGFT4",
+	currency.EUR: "BBG0013HJJ31",
+}
+
+func processTradesTable(table [][]string) []models.Operation {
+	result := []models.Operation{}
+	if len(table) == 0 {
+		return result
+	}
+	header := table[0]
+	security := column(header, "наименование")
+	trade := column(header, "№ сделки")
+	date := column(header, "дата и время заключения")
+	if date < 0 {
+		date = column(header, "дата заключения")
+	}
+	kind := column(header, "вид сделки")
+	volume := column(header, "количество")
+	amount := column(header, "сумма сделки")
+	interest := column(header, "нкд")
+	cur := column(header, "валюта расчетов")
+	exchangeFee := column(header, "комиссия банка за расчет")
+	brokerageFee := column(header, "комиссия банка за заключение")
+
+	for _, row := range table[1:] {
+		opTime, ok := parseTime(cell(row, date))
+		if !ok {
+			continue
+		}
+		var opType operation.Type
+		switch normalize(cell(row, kind)) {
+		case "покупка":
+			opType = operation.Buy
+		case "продажа":
+			opType = operation.Sell
+		default:
+			continue
+		}
+		vol := int64(parseFloat(cell(row, volume)))
+		if vol == 0 {
+			continue
+		}
+		c := parseCurrency(cell(row, cur))
+		isin := isinRegexp.FindString(cell(row, security))
+
+		// bond prices are quoted in percents so price is taken from trade amount
+		op := models.Operation{
+			Currency:      c,
+			Price:         math.Abs(parseFloat(cell(row, amount))) / float64(vol),
+			Volume:        vol,
+			ISIN:          isin,
+			DateTime:      opTime,
+			OperationType: opType,
+		}
+		first := len(result)
+		result = append(result, op)
+
+		if v := math.Abs(parseFloat(cell(row, interest))); v != 0 {
+			accrued := cashOperation(c, v, opTime, operation.AccInterestBuy)
+			if opType == operation.Sell {
+				accrued.OperationType = operation.AccInterestSell
+			}
+			result = append(result, accrued)
+		}
+		if v := math.Abs(parseFloat(cell(row, brokerageFee))); v != 0 {
+			result = append(result, cashOperation(c, v, opTime, operation.BrokerageFee))
+		}
+		if v := math.Abs(parseFloat(cell(row, exchangeFee))); v != 0 {
+			result = append(result, cashOperation(c, v, opTime, operation.ExchangeFee))
+		}
+
+		// trade number identifies trade and its related cash operations
+		if id := cell(row, trade); id != "" {
+			for i := first; i < len(result); i++ {
+				result[i].Source = provider.Vtb
+				result[i].ExternalID = id
+			}
+		}
+	}
+	return result
+}
+
+func processCashFlowTable(table [][]string) []models.Operation {
+	result := []models.Operation{}
+	if len(table) == 0 {
+		return result
+	}
+	header := table[0]
+	date := column(header, "дата")
+	amount := column(header, "сумма")
+	cur := column(header, "валюта")
+	kind := column(header, "тип операции")
+	comment := column(header, "комментарий")
+
+	for _, row := range table[1:] {
+		opTime, ok := parseTime(cell(row, date))
+		if !ok {
+			continue
+		}
+		value := parseFloat(cell(row, amount))
+		if value == 0 {
+			continue
+		}
+		op := cashOperation(parseCurrency(cell(row, cur)), math.Abs(value), opTime, operation.Unknown)
+
+		switch t := normalize(cell(row, kind)); {
+		case strings.Contains(t, "ндфл") || strings.Contains(t, "налог"):
+			op.OperationType = operation.Tax
+			// tax refund is recorded as negative tax
+			if strings.Contains(t, "возврат") {
+				op.Price = -op.Price
+			}
+		case strings.Contains(t, "купон"):
+			op.OperationType = operation.Coupon
+		case strings.Contains(t, "дивиденд"):
+			op.OperationType = operation.Dividend
+		case strings.Contains(t, "погашение"):
+			op.OperationType = operation.Buyback
+		case strings.Contains(t, "комиссия"):
+			op.OperationType = operation.BrokerageFee
+		case strings.Contains(t, "зачисление денежных средств"):
+			op.OperationType = operation.PayIn
+		case strings.Contains(t, "вывод денежных средств"), strings.Contains(t, "списание денежных средств"):
+			op.OperationType = operation.PayOut
+		default:
+			// trade settlements are already covered by trades table
+			continue
+		}
+
+		// income and taxes refer to security mentioned in comment
+		if isin := isinRegexp.FindString(cell(row, comment)); isin != "" && op.OperationType != operation.PayIn &&
+			op.OperationType != operation.PayOut && op.OperationType != operation.BrokerageFee {
+			op.ISIN = isin
+			op.Ticker = ""
+			// tax on security income is withheld by broker as tax agent
+			if op.OperationType == operation.Tax {
+				op.OperationType = operation.WithholdingTax
+			}
+		}
+		result = append(result, op)
+	}
+	return result
+}
+
+func cashOperation(c currency.Type, price float64, t time.Time, opType operation.Type) models.Operation {
+	return models.Operation{
+		Currency:      c,
+		Price:         price,
+		Volume:        1,
+		ISIN:          cashISIN[c],
+		Ticker:        string(c),
+		DateTime:      t,
+		OperationType: opType,
+	}
+}
+
+func parseCurrency(str string) currency.Type {
+	switch c := strings.ToUpper(strings.TrimSpace(str)); c {
+	case "", "RUR", "РУБ", "РУБ.":
+		return currency.RUB
+	default:
+		return currency.Type(c)
+	}
+}
+
+// parseTime supports text dates as well as excel serial dates.
+// Report times are in Moscow time zone
+func parseTime(str string) (time.Time, bool) {
+	for _, layout := range []string{"02.01.2006 15:04:05", "02.01.2006 15:04", "02.01.2006"} {
+		t, err := time.ParseInLocation(layout, str, moscow)
+		if err == nil {
+			if layout == "02.01.2006" {
+				t = t.Add(10 * time.Hour)
+			}
+			return t, true
+		}
+	}
+	t, err := time.Parse(time.RFC3339, str)
+	if err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, moscow), true
+	}
+	serial, err := strconv.ParseFloat(str, 64)
+	if err != nil || serial < 1 || serial > 2958465 {
+		return time.Time{}, false
+	}
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	t = time.Date(1899, 12, 30, 0, 0, 0, 0, moscow).AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	if seconds == 0 {
+		t = t.Add(10 * time.Hour)
+	}
+	return t, true
+}
+
+func parseFloat(str string) float64 {
+	var sb strings.Builder
+	sb.Grow(len(str))
+	for _, ch := range str {
+		if ch == ',' {
+			sb.WriteRune('.')
+		} else if unicode.IsDigit(ch) || ch == '.' || ch == '-' {
+			sb.WriteRune(ch)
+		}
+	}
+	f, _ := strconv.ParseFloat(sb.String(), 64)
+	return f
+}
@@ -0,0 +1,176 @@
+package vtb
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/extrame/xls"
+)
+
+var xlsxMagic = []byte("PK\x03\x04")
+var xlsMagic = []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+
+// readRows returns cell values of all workbook sheets row by row.
+// Both XLSX and legacy XLS workbooks are supported
+func readRows(data []byte) ([][]string, error) {
+	switch {
+	case bytes.HasPrefix(data, xlsxMagic):
+		return readXlsx(data)
+	case bytes.HasPrefix(data, xlsMagic):
+		return readXls(data)
+	default:
+		return nil, errors.New("unknown report format. Expected XLS or XLSX workbook")
+	}
+}
+
+func readXls(data []byte) (rows [][]string, err error) {
+	// xls library panics on malformed workbooks
+	defer func() {
+		if r := recover(); r != nil {
+			rows, err = nil, fmt.Errorf("could not read XLS workbook: %v", r)
+		}
+	}()
+	wb, err := xls.OpenReader(bytes.NewReader(data), "utf-8")
+	if err != nil {
+		return nil, err
+	}
+	if wb == nil {
+		return nil, errors.New("could not read XLS workbook: no workbook stream found")
+	}
+	return wb.ReadAllCells(math.MaxInt32), nil
+}
+
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		Text string `xml:"t"`
+	} `xml:"is"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxSharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+func readXlsx(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File)
+	sheets := []string{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+		if strings.HasPrefix(f.Name, "xl/worksheets/sheet") && strings.HasSuffix(f.Name, ".xml") {
+			sheets = append(sheets, f.Name)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, errors.New("could not read XLSX workbook: no worksheets found")
+	}
+	sort.Slice(sheets, func(i, j int) bool {
+		return sheetNumber(sheets[i]) < sheetNumber(sheets[j])
+	})
+
+	shared := []string{}
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst xlsxSharedStrings
+		err = decodeXML(f, &sst)
+		if err != nil {
+			return nil, err
+		}
+		for _, si := range sst.Items {
+			text := si.Text
+			for _, r := range si.Runs {
+				text += r.Text
+			}
+			shared = append(shared, text)
+		}
+	}
+
+	rows := [][]string{}
+	for _, name := range sheets {
+		var sheet xlsxSheet
+		err = decodeXML(files[name], &sheet)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range sheet.Rows {
+			row := []string{}
+			for _, c := range r.Cells {
+				col := columnIndex(c.Ref, len(row))
+				for len(row) <= col {
+					row = append(row, "")
+				}
+				switch c.Type {
+				case "s":
+					idx, err := strconv.Atoi(c.Value)
+					if err == nil && idx < len(shared) {
+						row[col] = shared[idx]
+					}
+				case "inlineStr":
+					row[col] = c.Inline.Text
+				default:
+					row[col] = c.Value
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(b, v)
+}
+
+func sheetNumber(name string) int {
+	n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "xl/worksheets/sheet"), ".xml"))
+	return n
+}
+
+// columnIndex converts cell reference like "AB12" to zero based column index.
+// Cells without reference follow previous one
+func columnIndex(ref string, fallback int) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A') + 1
+	}
+	if col == 0 {
+		return fallback
+	}
+	return col - 1
+}
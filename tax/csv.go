@@ -0,0 +1,51 @@
+package tax
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/kaseat/pManager/models"
+)
+
+// WriteCSV writes report trades followed by summary lines
+func WriteCSV(w io.Writer, r models.TaxReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"sellDate", "ticker", "isin", "vol", "proceeds", "costBasis", "fee", "gain"})
+	for _, t := range r.Trades {
+		cw.Write([]string{
+			t.Date.Format("2006-01-02"),
+			t.Ticker,
+			t.ISIN,
+			strconv.FormatInt(t.Volume, 10),
+			formatMoney(t.Proceeds),
+			formatMoney(t.CostBasis),
+			formatMoney(t.Fee),
+			formatMoney(t.Gain),
+		})
+	}
+
+	cw.Write([]string{})
+	summary := [][]string{
+		{"year", strconv.Itoa(r.Year)},
+		{"proceeds", formatMoney(r.Proceeds)},
+		{"costBasis", formatMoney(r.CostBasis)},
+		{"fees", formatMoney(r.Fees)},
+		{"realizedGain", formatMoney(r.RealizedGain)},
+		{"coupons", formatMoney(r.Coupons)},
+		{"accruedInterestReceived", formatMoney(r.AccruedInterestReceived)},
+		{"accruedInterestPaid", formatMoney(r.AccruedInterestPaid)},
+		{"taxBase", formatMoney(r.TaxBase)},
+		{"taxRate", strconv.FormatFloat(r.TaxRate, 'f', -1, 64)},
+		{"tax", formatMoney(r.Tax)},
+	}
+	for _, line := range summary {
+		cw.Write(line)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
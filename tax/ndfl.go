@@ -0,0 +1,90 @@
+package tax
+
+import (
+	"math"
+
+	"github.com/kaseat/pManager/fx"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/kaseat/pManager/utils"
+)
+
+// Rate is personal income tax rate
+const Rate = 0.13
+
+// GetReport builds NDFL report for given calendar year. Every operation is
+// converted to rubles at the rate of its own date, so cost basis uses buy date
+// rate and proceeds use sell date rate. Fees are included into cost basis and
+// proceeds, Fees field shows the deducted amount for reference.
+// Rates are taken from converter loaded from stored CBR rates
+func GetReport(ops []models.Operation, year int, rates fx.Converter) (models.TaxReport, error) {
+	report := models.TaxReport{
+		Year:    year,
+		Trades:  []models.RealizedTrade{},
+		TaxRate: Rate,
+	}
+
+	converted := make([]models.Operation, 0, len(ops))
+	for _, op := range ops {
+		if op.DateTime.Year() > year {
+			continue
+		}
+		if op.Currency != "" && op.Currency != currency.RUB {
+			rate, err := rates.GetRate(op.Currency, op.DateTime)
+			if err != nil {
+				return report, err
+			}
+			op.Price *= rate
+			op.Currency = currency.RUB
+		}
+		converted = append(converted, op)
+	}
+
+	for _, trade := range utils.GetRealized(converted) {
+		if trade.Date.Year() != year {
+			continue
+		}
+		report.Trades = append(report.Trades, trade)
+		report.Proceeds += trade.Proceeds
+		report.CostBasis += trade.CostBasis
+		report.Fees += trade.Fee
+		for _, lot := range trade.Lots {
+			report.Fees += lot.Fee
+		}
+	}
+
+	for _, op := range converted {
+		if op.DateTime.Year() != year {
+			continue
+		}
+		amount := op.Price * float64(op.Volume)
+		switch op.OperationType {
+		case operation.Coupon:
+			report.Coupons += amount
+		case operation.AccInterestSell:
+			report.AccruedInterestReceived += amount
+		case operation.AccInterestBuy:
+			report.AccruedInterestPaid += amount
+		}
+	}
+
+	report.Proceeds = roundMoney(report.Proceeds)
+	report.CostBasis = roundMoney(report.CostBasis)
+	report.Fees = roundMoney(report.Fees)
+	report.RealizedGain = roundMoney(report.Proceeds - report.CostBasis)
+	report.Coupons = roundMoney(report.Coupons)
+	report.AccruedInterestReceived = roundMoney(report.AccruedInterestReceived)
+	report.AccruedInterestPaid = roundMoney(report.AccruedInterestPaid)
+
+	// losses reduce tax base but never make it negative
+	base := report.RealizedGain + report.Coupons + report.AccruedInterestReceived - report.AccruedInterestPaid
+	report.TaxBase = roundMoney(math.Max(base, 0))
+	// tax is calculated in full rubles
+	report.Tax = math.Round(report.TaxBase * Rate)
+	return report, nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
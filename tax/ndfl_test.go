@@ -0,0 +1,90 @@
+package tax
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/fx"
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+// usdRates makes converter of USD rates set on given dates
+func usdRates(rates map[string]float64) fx.Converter {
+	result := []models.Rate{}
+	for date, rate := range rates {
+		d, _ := time.Parse("2006-01-02", date)
+		result = append(result, models.Rate{Currency: currency.USD, Date: d, Rate: rate})
+	}
+	return fx.NewConverter(result)
+}
+
+func getTestOperations() []models.Operation {
+	buy := time.Date(2019, 12, 10, 10, 0, 0, 0, time.UTC)
+	sell := time.Date(2020, 3, 10, 10, 0, 0, 0, time.UTC)
+	next := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	return []models.Operation{
+		{ISIN: "US0000000001", Currency: currency.USD, DateTime: buy, OperationType: operation.Buy, Price: 100, Volume: 20},
+		{Ticker: "RUB", Currency: currency.RUB, DateTime: buy, OperationType: operation.BrokerageFee, Price: 60, Volume: 1},
+		{ISIN: "US0000000001", Currency: currency.USD, DateTime: sell, OperationType: operation.Sell, Price: 110, Volume: 10},
+		{Ticker: "RUB", Currency: currency.RUB, DateTime: sell, OperationType: operation.BrokerageFee, Price: 38.5, Volume: 1},
+		{ISIN: "US0000000001", Currency: currency.USD, DateTime: next, OperationType: operation.Sell, Price: 120, Volume: 10},
+		{ISIN: "RU0000000001", Currency: currency.RUB, DateTime: sell, OperationType: operation.Coupon, Price: 50, Volume: 10},
+		{Ticker: "RUB", Currency: currency.RUB, DateTime: sell, OperationType: operation.AccInterestBuy, Price: 100, Volume: 1},
+		{Ticker: "RUB", Currency: currency.RUB, DateTime: sell, OperationType: operation.AccInterestSell, Price: 40, Volume: 1},
+	}
+}
+
+func TestGetReport(t *testing.T) {
+	rates := usdRates(map[string]float64{"2019-12-10": 60, "2020-03-10": 70, "2021-02-01": 75})
+	r, err := GetReport(getTestOperations(), 2020, rates)
+	if err != nil {
+		t.Fatalf("Fail! Error during build report: %v", err)
+	}
+
+	// buy: 10 * 100 USD * 60 + 30 RUB fee, sell: 10 * 110 USD * 70 - 38.5 RUB fee
+	if len(r.Trades) == 1 && r.Proceeds == 76961.5 && r.CostBasis == 60030 && r.Fees == 68.5 && r.RealizedGain == 16931.5 {
+		t.Logf("Success! Got expected trades summary %+v", r)
+	} else {
+		t.Errorf("Fail! Unexpected trades summary %+v", r)
+	}
+	if r.Coupons == 500 && r.AccruedInterestReceived == 40 && r.AccruedInterestPaid == 100 {
+		t.Logf("Success! Got expected coupons summary %+v", r)
+	} else {
+		t.Errorf("Fail! Unexpected coupons summary %+v", r)
+	}
+	if r.TaxBase == 17371.5 && r.Tax == 2258 {
+		t.Logf("Success! Expected %v, got %v", 2258, r.Tax)
+	} else {
+		t.Errorf("Fail! Expected %v with base %v, got %v with base %v", 2258, 17371.5, r.Tax, r.TaxBase)
+	}
+}
+
+func TestGetReportLoss(t *testing.T) {
+	rates := usdRates(map[string]float64{"2019-12-10": 60, "2020-03-10": 50, "2021-02-01": 75})
+	r, _ := GetReport(getTestOperations(), 2020, rates)
+	if r.RealizedGain < 0 && r.TaxBase == 0 && r.Tax == 0 {
+		t.Logf("Success! Expected zero tax, got %v", r.Tax)
+	} else {
+		t.Errorf("Fail! Expected zero tax, got %v with base %v", r.Tax, r.TaxBase)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	rates := usdRates(map[string]float64{"2019-12-10": 60, "2020-03-10": 70, "2021-02-01": 75})
+	r, _ := GetReport(getTestOperations(), 2020, rates)
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, r)
+	if err != nil {
+		t.Fatalf("Fail! Error during write csv: %v", err)
+	}
+	res := buf.String()
+	if strings.Contains(res, "2020-03-10,,US0000000001,10,76961.50,60030.00,38.50,16931.50\n") && strings.HasSuffix(res, "tax,2258.00\n") {
+		t.Logf("Success! Got expected csv")
+	} else {
+		t.Errorf("Fail! Unexpected csv:\n%s", res)
+	}
+}
@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/action"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+// ApplyCorporateActions restates operations made before corporate actions in terms of
+// resulting securities, so positions and FIFO lots stay continuous across them.
+// Ratio is applied to the whole position open on action date: open buy lots share
+// resulting units keeping their cost, and old units not making up a whole new unit
+// are sold at cost as cash in lieu. Trades closed before the action keep their volumes.
+// Spin-off splits lots open on action date into lots of both securities by cost ratio.
+// Given operations are not modified, result is sorted by time
+func ApplyCorporateActions(ops []models.Operation, actions []models.CorporateAction) []models.Operation {
+	result := make([]models.Operation, len(ops))
+	copy(result, ops)
+	sort.Stable(models.OperationSorter(result))
+	if len(actions) == 0 {
+		return result
+	}
+
+	sorted := make([]models.CorporateAction, len(actions))
+	copy(sorted, actions)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	for _, a := range sorted {
+		if a.RatioFrom <= 0 || a.RatioTo <= 0 {
+			continue
+		}
+		if a.Type == action.SpinOff {
+			result = spinOff(result, a)
+			continue
+		}
+		result = convert(result, a)
+	}
+	sort.Stable(models.OperationSorter(result))
+	return result
+}
+
+// convert applies action ratio to position open on action date and renames
+// all operations made before the action
+func convert(ops []models.Operation, a models.CorporateAction) []models.Operation {
+	open, queue := openLots(ops, a)
+	if a.RatioFrom == a.RatioTo {
+		// rename keeps lots as they are
+		open, queue = nil, nil
+	}
+	var total int64
+	for _, i := range queue {
+		total += open[i]
+	}
+	newTotal := total * a.RatioTo / a.RatioFrom
+	// old units converted to whole new units, the rest is paid in lieu
+	kept := (newTotal*a.RatioFrom + a.RatioTo - 1) / a.RatioTo
+	inLieu := total - kept
+
+	result := make([]models.Operation, 0, len(ops)+len(queue)+1)
+	lieu := models.Operation{}
+	var seen, allocated int64
+	var carry, lieuCost float64
+	for i, op := range ops {
+		if op.ISIN != a.ISIN || !op.DateTime.Before(a.Date) {
+			result = append(result, op)
+			continue
+		}
+		op = rename(op, a)
+		vol := open[i]
+		if vol == 0 {
+			result = append(result, op)
+			continue
+		}
+		if closed := op.Volume - vol; closed > 0 {
+			c := op
+			c.Volume = closed
+			result = append(result, c)
+		}
+		// earliest open units are sold first, as FIFO does
+		if n := inLieu - lieu.Volume; n > 0 {
+			if n > vol {
+				n = vol
+			}
+			c := op
+			c.Volume = n
+			result = append(result, c)
+			if lieu.Volume == 0 {
+				lieu = op
+				lieu.Volume = 0
+			}
+			lieu.Volume += n
+			lieuCost += op.Price * float64(n)
+			vol -= n
+		}
+		if vol == 0 {
+			continue
+		}
+
+		// lots too small for a whole new unit pass their cost to the next one
+		seen += vol
+		carry += op.Price * float64(vol)
+		newVol := seen*newTotal/kept - allocated
+		if newVol == 0 {
+			continue
+		}
+		allocated += newVol
+		lot := op
+		lot.Volume = newVol
+		lot.Price = carry / float64(newVol)
+		carry = 0
+		result = append(result, lot)
+	}
+
+	if lieu.Volume > 0 {
+		lieu.OperationID = ""
+		lieu.OperationType = operation.Sell
+		lieu.DateTime = a.Date
+		lieu.Price = lieuCost / float64(lieu.Volume)
+		lieu.Source = ""
+		lieu.ExternalID = ""
+		result = append(result, lieu)
+	}
+	return result
+}
+
+func rename(op models.Operation, a models.CorporateAction) models.Operation {
+	if a.NewISIN != "" {
+		op.ISIN = a.NewISIN
+		op.FIGI = ""
+	}
+	if a.NewTicker != "" {
+		op.Ticker = a.NewTicker
+	}
+	return op
+}
+
+// openLots finds buy lots of action security open on action date using FIFO.
+// Returns open volume by operation index and indices of open lots in FIFO order
+func openLots(ops []models.Operation, a models.CorporateAction) (map[int]int64, []int) {
+	open := make(map[int]int64)
+	queue := []int{}
+	for i, op := range ops {
+		if op.ISIN != a.ISIN || !op.DateTime.Before(a.Date) {
+			continue
+		}
+		switch op.OperationType {
+		case operation.Buy:
+			open[i] = op.Volume
+			queue = append(queue, i)
+		case operation.Sell, operation.Buyback:
+			left := op.Volume
+			for left > 0 && len(queue) != 0 {
+				j := queue[0]
+				take := open[j]
+				if take > left {
+					take = left
+				}
+				open[j] -= take
+				left -= take
+				if open[j] == 0 {
+					queue = queue[1:]
+				}
+			}
+		}
+	}
+	return open, queue
+}
+
+// spinOff splits every buy lot open on action date into lot of original security
+// and lot of new one. Both keep lot date, new security units are shared
+// by open lots the same way convert does
+func spinOff(ops []models.Operation, a models.CorporateAction) []models.Operation {
+	open, queue := openLots(ops, a)
+	var total int64
+	for _, i := range queue {
+		total += open[i]
+	}
+	newTotal := total * a.RatioTo / a.RatioFrom
+
+	result := make([]models.Operation, 0, len(ops)+2*len(queue))
+	var seen, allocated int64
+	var carry float64
+	for i, op := range ops {
+		vol := open[i]
+		if vol == 0 {
+			result = append(result, op)
+			continue
+		}
+		if closed := op.Volume - vol; closed > 0 {
+			c := op
+			c.Volume = closed
+			result = append(result, c)
+		}
+		kept := op
+		kept.Volume = vol
+		kept.Price = op.Price * (1 - a.CostRatio)
+		result = append(result, kept)
+
+		seen += vol
+		carry += op.Price * float64(vol) * a.CostRatio
+		newVol := seen*newTotal/total - allocated
+		if newVol == 0 {
+			continue
+		}
+		allocated += newVol
+		derived := op
+		derived.ISIN = a.NewISIN
+		derived.FIGI = ""
+		derived.Ticker = a.NewTicker
+		derived.Volume = newVol
+		derived.Price = carry / float64(newVol)
+		carry = 0
+		result = append(result, derived)
+	}
+	return result
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/action"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+func TestApplySplitAndRename(t *testing.T) {
+	t1 := time.Date(2020, 1, 10, 10, 0, 0, 0, time.UTC)
+	split := time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC)
+	ops := []models.Operation{
+		{OperationID: "1", ISIN: "US0378331005", Ticker: "AAPL", DateTime: t1, OperationType: operation.Buy, Price: 400, Volume: 10},
+		{OperationID: "2", ISIN: "US0378331005", Ticker: "AAPL", DateTime: t1.AddDate(0, 1, 0), OperationType: operation.Dividend, Price: 7.7, Volume: 1},
+		{OperationID: "3", ISIN: "US0378331005", Ticker: "AAPL", DateTime: split.AddDate(0, 0, 1), OperationType: operation.Sell, Price: 130, Volume: 20},
+		{OperationID: "4", ISIN: "RU000A0JP5V6", Ticker: "VTBR", DateTime: t1, OperationType: operation.Buy, Price: 0.04, Volume: 10000},
+	}
+	actions := []models.CorporateAction{
+		{ISIN: "RU000A0JP5V6", Type: action.Split, Date: split, RatioFrom: 5000, RatioTo: 1},
+		{ISIN: "US0378331005", Type: action.Split, Date: split, RatioFrom: 1, RatioTo: 4},
+		{ISIN: "US0378331005", Type: action.Rename, Date: split.AddDate(0, 1, 0), RatioFrom: 1, RatioTo: 1, NewTicker: "APPL"},
+	}
+
+	res := ApplyCorporateActions(ops, actions)
+	if len(res) != 4 {
+		t.Fatalf("Fail! Expected %v operations, got %v", 4, len(res))
+	}
+	for _, op := range res {
+		switch op.OperationID {
+		case "1":
+			if op.Volume == 40 && op.Price == 100 && op.Ticker == "APPL" {
+				t.Logf("Success! Got split buy %+v", op)
+			} else {
+				t.Errorf("Fail! Unexpected split buy %+v", op)
+			}
+		case "2":
+			if op.Volume == 1 && op.Price == 7.7 && op.Ticker == "APPL" {
+				t.Logf("Success! Got renamed dividend %+v", op)
+			} else {
+				t.Errorf("Fail! Unexpected dividend %+v", op)
+			}
+		case "3":
+			if op.Volume == 20 && op.Price == 130 && op.Ticker == "APPL" {
+				t.Logf("Success! Got sell after split %+v", op)
+			} else {
+				t.Errorf("Fail! Unexpected sell %+v", op)
+			}
+		case "4":
+			if op.Volume == 2 && op.Price == 200 {
+				t.Logf("Success! Got reverse split buy %+v", op)
+			} else {
+				t.Errorf("Fail! Unexpected reverse split buy %+v", op)
+			}
+		}
+	}
+	if ops[0].Volume != 10 || ops[0].Ticker != "AAPL" {
+		t.Errorf("Fail! Source operations must not be modified, got %+v", ops[0])
+	}
+
+	avg := GetAverage([]models.Operation{res[0], res[3]})
+	if avg == 100 {
+		t.Logf("Success! Expected average %v, got %v", 100, avg)
+	} else {
+		t.Errorf("Fail! Expected average %v, got %v", 100, avg)
+	}
+}
+
+func TestApplyConversionAndSpinOff(t *testing.T) {
+	t1 := time.Date(2020, 1, 10, 10, 0, 0, 0, time.UTC)
+	t2 := t1.AddDate(0, 1, 0)
+	date := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	ops := []models.Operation{
+		{OperationID: "1", ISIN: "A", Ticker: "A", DateTime: t1, OperationType: operation.Buy, Price: 100, Volume: 10},
+		{OperationID: "2", ISIN: "A", Ticker: "A", DateTime: t2, OperationType: operation.Buy, Price: 120, Volume: 10},
+		{OperationID: "3", ISIN: "A", Ticker: "A", DateTime: t2.AddDate(0, 0, 1), OperationType: operation.Sell, Price: 130, Volume: 15},
+		{OperationID: "4", ISIN: "C", Ticker: "C", DateTime: t1, OperationType: operation.Buy, Price: 30, Volume: 3},
+		{OperationID: "5", ISIN: "D", Ticker: "D", DateTime: date.AddDate(0, 0, 1), OperationType: operation.Sell, Price: 18, Volume: 6},
+	}
+	actions := []models.CorporateAction{
+		{ISIN: "A", Type: action.SpinOff, Date: date, RatioFrom: 1, RatioTo: 2, NewISIN: "B", NewTicker: "B", CostRatio: 0.25},
+		{ISIN: "C", Type: action.Conversion, Date: date, RatioFrom: 1, RatioTo: 2, NewISIN: "D", NewTicker: "D"},
+	}
+
+	res := ApplyCorporateActions(ops, actions)
+	expected := []models.Operation{
+		{OperationID: "1", ISIN: "A", Ticker: "A", DateTime: t1, OperationType: operation.Buy, Price: 100, Volume: 10},
+		{OperationID: "4", ISIN: "D", Ticker: "D", DateTime: t1, OperationType: operation.Buy, Price: 15, Volume: 6},
+		{OperationID: "2", ISIN: "A", Ticker: "A", DateTime: t2, OperationType: operation.Buy, Price: 120, Volume: 5},
+		{OperationID: "2", ISIN: "A", Ticker: "A", DateTime: t2, OperationType: operation.Buy, Price: 90, Volume: 5},
+		{OperationID: "2", ISIN: "B", Ticker: "B", DateTime: t2, OperationType: operation.Buy, Price: 15, Volume: 10},
+		{OperationID: "3", ISIN: "A", Ticker: "A", DateTime: t2.AddDate(0, 0, 1), OperationType: operation.Sell, Price: 130, Volume: 15},
+		{OperationID: "5", ISIN: "D", Ticker: "D", DateTime: date.AddDate(0, 0, 1), OperationType: operation.Sell, Price: 18, Volume: 6},
+	}
+	if len(res) != len(expected) {
+		t.Fatalf("Fail! Expected %v operations, got %v: %+v", len(expected), len(res), res)
+	}
+	for i := range expected {
+		e, a := expected[i], res[i]
+		if e.OperationID == a.OperationID && e.ISIN == a.ISIN && e.Ticker == a.Ticker && e.DateTime.Equal(a.DateTime) &&
+			e.OperationType == a.OperationType && e.Price == a.Price && e.Volume == a.Volume {
+			t.Logf("Success! Expected %+v, got %+v", e, a)
+		} else {
+			t.Errorf("Fail! Expected %+v, got %+v", e, a)
+		}
+	}
+
+	realized := GetRealized(res)
+	if len(realized) == 2 && realized[1].ISIN == "D" && realized[1].CostBasis == 90 && realized[1].Gain == 18 {
+		t.Logf("Success! Got converted position realized %+v", realized[1])
+	} else {
+		t.Errorf("Fail! Unexpected realized trades %+v", realized)
+	}
+}
+
+func TestApplyReverseSplitOverSmallLots(t *testing.T) {
+	t1 := time.Date(2020, 1, 10, 10, 0, 0, 0, time.UTC)
+	split := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	ops := []models.Operation{
+		{OperationID: "1", ISIN: "A", Ticker: "A", DateTime: t1, OperationType: operation.Buy, Price: 10, Volume: 5},
+		{OperationID: "2", ISIN: "A", Ticker: "A", DateTime: t1.AddDate(0, 1, 0), OperationType: operation.Buy, Price: 12, Volume: 5},
+		{OperationID: "3", ISIN: "A", Ticker: "A", DateTime: t1.AddDate(0, 2, 0), OperationType: operation.Buy, Price: 11, Volume: 3},
+		{OperationID: "4", ISIN: "A", Ticker: "A", DateTime: split.AddDate(0, 0, 1), OperationType: operation.Sell, Price: 150, Volume: 1},
+	}
+	actions := []models.CorporateAction{
+		{ISIN: "A", Type: action.Split, Date: split, RatioFrom: 10, RatioTo: 1},
+	}
+
+	// 13 shares make 1.3 new share, 3 old shares are paid in lieu
+	res := ApplyCorporateActions(ops, actions)
+	expected := []models.Operation{
+		{OperationID: "1", DateTime: t1, OperationType: operation.Buy, Price: 10, Volume: 3},
+		{OperationID: "3", DateTime: t1.AddDate(0, 2, 0), OperationType: operation.Buy, Price: 113, Volume: 1},
+		{OperationID: "", DateTime: split, OperationType: operation.Sell, Price: 10, Volume: 3},
+		{OperationID: "4", DateTime: split.AddDate(0, 0, 1), OperationType: operation.Sell, Price: 150, Volume: 1},
+	}
+	if len(res) != len(expected) {
+		t.Fatalf("Fail! Expected %v operations, got %v: %+v", len(expected), len(res), res)
+	}
+	for i := range expected {
+		e, a := expected[i], res[i]
+		if e.OperationID == a.OperationID && a.ISIN == "A" && e.DateTime.Equal(a.DateTime) &&
+			e.OperationType == a.OperationType && e.Price == a.Price && e.Volume == a.Volume {
+			t.Logf("Success! Expected %+v, got %+v", e, a)
+		} else {
+			t.Errorf("Fail! Expected %+v, got %+v", e, a)
+		}
+	}
+
+	cost := 0.0
+	for _, op := range res {
+		if op.OperationType == operation.Buy {
+			cost += op.Price * float64(op.Volume)
+		}
+	}
+	if cost == 143 {
+		t.Logf("Success! Expected cost %v, got %v", 143, cost)
+	} else {
+		t.Errorf("Fail! Expected cost %v, got %v", 143, cost)
+	}
+
+	realized := GetRealized(res)
+	if len(realized) == 2 && realized[0].Volume == 3 && realized[0].Gain == 0 &&
+		realized[1].Volume == 1 && realized[1].CostBasis == 113 && realized[1].Gain == 37 {
+		t.Logf("Success! Got realized trades %+v", realized)
+	} else {
+		t.Errorf("Fail! Unexpected realized trades %+v", realized)
+	}
+
+	// the whole position smaller than one new share is paid in lieu
+	res = ApplyCorporateActions(ops[:2], []models.CorporateAction{
+		{ISIN: "A", Type: action.Split, Date: split, RatioFrom: 20, RatioTo: 1},
+	})
+	if len(res) == 3 && res[2].OperationType == operation.Sell && res[2].Volume == 10 && res[2].Price == 11 {
+		t.Logf("Success! Got cash in lieu sell %+v", res[2])
+	} else {
+		t.Errorf("Fail! Unexpected operations %+v", res)
+	}
+
+	res = ApplyCorporateActions(ops[:2], actions)
+	if len(res) == 1 && res[0].OperationID == "2" && res[0].Volume == 1 && res[0].Price == 110 {
+		t.Logf("Success! Two lots of 5 shares make 1 share %+v", res[0])
+	} else {
+		t.Errorf("Fail! Unexpected operations %+v", res)
+	}
+}
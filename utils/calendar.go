@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+// GetCalendar projects expected cash inflows of given security events
+// from current holdings. Events of securities not held are skipped.
+// Result is sorted by date
+func GetCalendar(ops []models.Operation, events []models.SecurityEvent) []models.CalendarEntry {
+	holdings := make(map[string]int64)
+	tickers := make(map[string]string)
+	for _, op := range ops {
+		switch op.OperationType {
+		case operation.Buy:
+			holdings[op.ISIN] += op.Volume
+		case operation.Sell:
+			holdings[op.ISIN] -= op.Volume
+		default:
+			continue
+		}
+		tickers[op.ISIN] = op.Ticker
+	}
+
+	result := []models.CalendarEntry{}
+	for _, e := range events {
+		vol := holdings[e.ISIN]
+		if vol <= 0 {
+			continue
+		}
+		result = append(result, models.CalendarEntry{
+			Date:     e.Date,
+			ISIN:     e.ISIN,
+			Ticker:   tickers[e.ISIN],
+			Type:     e.Type,
+			Currency: e.Currency,
+			Value:    e.Value,
+			Volume:   vol,
+			Amount:   roundMoney(e.Value * float64(vol)),
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Date.Before(result[j].Date)
+	})
+	return result
+}
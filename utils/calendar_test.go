@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/event"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+func TestGetCalendar(t *testing.T) {
+	t1 := time.Date(2020, 1, 10, 10, 0, 0, 0, time.UTC)
+	ops := []models.Operation{
+		{ISIN: "RU000A0JSMA2", Ticker: "SU26209RMFS5", DateTime: t1, OperationType: operation.Buy, Price: 1010, Volume: 10},
+		{ISIN: "RU000A0JSMA2", Ticker: "SU26209RMFS5", DateTime: t1.AddDate(0, 1, 0), OperationType: operation.Sell, Price: 1020, Volume: 4},
+		{ISIN: "RU0009029540", Ticker: "SBER", DateTime: t1, OperationType: operation.Buy, Price: 250, Volume: 10},
+		{ISIN: "RU0009029540", Ticker: "SBER", DateTime: t1.AddDate(0, 2, 0), OperationType: operation.Sell, Price: 260, Volume: 10},
+		{ISIN: "RU000Z13FK33", Ticker: "RUB", DateTime: t1, OperationType: operation.PayIn, Price: 50000, Volume: 1},
+	}
+	events := []models.SecurityEvent{
+		{ISIN: "RU000A0JSMA2", Type: event.Amortization, Date: time.Date(2022, 7, 20, 0, 0, 0, 0, time.UTC), Value: 1000, Currency: currency.RUB},
+		{ISIN: "RU000A0JSMA2", Type: event.Coupon, Date: time.Date(2020, 7, 22, 0, 0, 0, 0, time.UTC), Value: 37.9, Currency: currency.RUB},
+		{ISIN: "RU0009029540", Type: event.Dividend, Date: time.Date(2020, 7, 14, 0, 0, 0, 0, time.UTC), Value: 18.7, Currency: currency.RUB},
+	}
+
+	res := GetCalendar(ops, events)
+	expected := []models.CalendarEntry{
+		{Date: events[1].Date, ISIN: "RU000A0JSMA2", Ticker: "SU26209RMFS5", Type: event.Coupon, Currency: currency.RUB, Value: 37.9, Volume: 6, Amount: 227.4},
+		{Date: events[0].Date, ISIN: "RU000A0JSMA2", Ticker: "SU26209RMFS5", Type: event.Amortization, Currency: currency.RUB, Value: 1000, Volume: 6, Amount: 6000},
+	}
+	if len(res) != len(expected) {
+		t.Fatalf("Fail! Expected %v entries, got %v: %+v", len(expected), len(res), res)
+	}
+	for i := range expected {
+		if res[i] == expected[i] {
+			t.Logf("Success! Got expected entry %+v", res[i])
+		} else {
+			t.Errorf("Fail! Expected %+v, got %+v", expected[i], res[i])
+		}
+	}
+}
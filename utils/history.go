@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+// Interval represents distance between history points
+type Interval string
+
+const (
+	// Day interval
+	Day Interval = "day"
+	// Week interval
+	Week Interval = "week"
+	// Month interval
+	Month Interval = "month"
+)
+
+type position struct {
+	volume   int64
+	currency currency.Type
+	prices   []models.Price
+	cursor   int
+}
+
+// GetHistoryDates returns dates from given range with given interval.
+// Last date is always included
+func GetHistoryDates(from, to time.Time, interval Interval) ([]time.Time, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("'from' date must not be after 'to' date")
+	}
+	result := []time.Time{}
+	for i := 0; ; i++ {
+		var d time.Time
+		switch interval {
+		case Day:
+			d = from.AddDate(0, 0, i)
+		case Week:
+			d = from.AddDate(0, 0, 7*i)
+		case Month:
+			d = from.AddDate(0, i, 0)
+		default:
+			return nil, fmt.Errorf("Unknown interval '%s'. Expected '%s', '%s' or '%s'", interval, Day, Week, Month)
+		}
+		if d.After(to) {
+			break
+		}
+		result = append(result, d)
+	}
+	if !result[len(result)-1].Equal(to) {
+		result = append(result, to)
+	}
+	return result, nil
+}
+
+// GetHistory returns portfolio valuation at the end of every given day in one pass
+// over operations and prices. Prices are keyed by ISIN, security is valued at the
+// last known price not later than the day. Market value includes cash.
+// Dates must be sorted ascending
+func GetHistory(ops []models.Operation, prices map[string][]models.Price, dates []time.Time) []models.HistoryPoint {
+	sorted := make([]models.Operation, len(ops))
+	copy(sorted, ops)
+	sort.Stable(models.OperationSorter(sorted))
+
+	positions := make(map[string]*position)
+	cash := make(map[currency.Type]int64)
+	invested := make(map[currency.Type]int64)
+	result := make([]models.HistoryPoint, 0, len(dates))
+
+	i := 0
+	for _, date := range dates {
+		end := date.AddDate(0, 0, 1)
+		for ; i < len(sorted) && sorted[i].DateTime.Before(end); i++ {
+			op := sorted[i]
+			amount := int64(math.Round(op.Price*1e6)) * op.Volume
+			if isIncome(op.OperationType) {
+				cash[op.Currency] += amount
+			} else {
+				cash[op.Currency] -= amount
+			}
+
+			switch op.OperationType {
+			case operation.PayIn:
+				invested[op.Currency] += amount
+			case operation.PayOut:
+				invested[op.Currency] -= amount
+			case operation.Buy, operation.Sell, operation.Buyback:
+				p, ok := positions[op.ISIN]
+				if !ok {
+					p = &position{currency: op.Currency, prices: prices[op.ISIN]}
+					sort.SliceStable(p.prices, func(a, b int) bool { return p.prices[a].Date.Before(p.prices[b].Date) })
+					positions[op.ISIN] = p
+				}
+				if op.OperationType == operation.Buy {
+					p.volume += op.Volume
+				} else {
+					p.volume -= op.Volume
+				}
+			}
+		}
+
+		point := models.HistoryPoint{
+			Date:        date,
+			MarketValue: make(map[currency.Type]float64),
+			Cash:        make(map[currency.Type]float64),
+			Invested:    make(map[currency.Type]float64),
+		}
+		value := make(map[currency.Type]int64)
+		for _, p := range positions {
+			for p.cursor < len(p.prices) && p.prices[p.cursor].Date.Before(end) {
+				p.cursor++
+			}
+			if p.volume == 0 || p.cursor == 0 {
+				continue
+			}
+			value[p.currency] += int64(math.Round(p.prices[p.cursor-1].Price*1e6)) * p.volume
+		}
+		for curr, v := range cash {
+			point.Cash[curr] = toMoney(v)
+			value[curr] += v
+		}
+		for curr, v := range value {
+			point.MarketValue[curr] = toMoney(v)
+		}
+		for curr, v := range invested {
+			point.Invested[curr] = toMoney(v)
+		}
+		result = append(result, point)
+	}
+	return result
+}
+
+// RateConverter converts amount between currencies at the rate of given date
+type RateConverter interface {
+	Convert(amount float64, from, to currency.Type, date time.Time) (float64, error)
+}
+
+// ConsolidateHistory fills totals of every point in given currency
+// at the rate of point date
+func ConsolidateHistory(points []models.HistoryPoint, conv RateConverter, curr currency.Type) error {
+	for i := range points {
+		p := &points[i]
+		p.ReportCurrency = curr
+		var err error
+		if p.TotalMarketValue, err = consolidate(p.MarketValue, conv, curr, p.Date); err != nil {
+			return err
+		}
+		if p.TotalCash, err = consolidate(p.Cash, conv, curr, p.Date); err != nil {
+			return err
+		}
+		if p.TotalInvested, err = consolidate(p.Invested, conv, curr, p.Date); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func consolidate(values map[currency.Type]float64, conv RateConverter, curr currency.Type, date time.Time) (float64, error) {
+	total := float64(0)
+	for c, v := range values {
+		if v == 0 {
+			continue
+		}
+		converted, err := conv.Convert(v, c, curr, date)
+		if err != nil {
+			return 0, err
+		}
+		total += converted
+	}
+	return math.Round(total*100) / 100, nil
+}
+
+func toMoney(v int64) float64 {
+	return math.Round(float64(v)/1e4) / 100
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+func TestGetHistoryDates(t *testing.T) {
+	from := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 4, 15, 0, 0, 0, 0, time.UTC)
+	dates, err := GetHistoryDates(from, to, Month)
+	if err != nil {
+		t.Fatalf("Fail! Error during get dates: %v", err)
+	}
+	if len(dates) == 4 && dates[0].Equal(from) && dates[3].Equal(to) {
+		t.Logf("Success! Got expected dates %v", dates)
+	} else {
+		t.Errorf("Fail! Unexpected dates %v", dates)
+	}
+
+	_, err = GetHistoryDates(from, to, "year")
+	if err != nil {
+		t.Logf("Success! Expected error, got %v", err)
+	} else {
+		t.Errorf("Fail! Expected error for unknown interval, got nil")
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	d := func(day int) time.Time { return time.Date(2020, 1, day, 0, 0, 0, 0, time.UTC) }
+	ops := []models.Operation{
+		{ISIN: "A", Currency: currency.USD, DateTime: d(2).Add(time.Hour), OperationType: operation.Buy, Price: 10, Volume: 5},
+		{Ticker: "USD", Currency: currency.USD, DateTime: d(1), OperationType: operation.PayIn, Price: 1, Volume: 100},
+		{Ticker: "RUB", Currency: currency.RUB, DateTime: d(2).Add(time.Hour), OperationType: operation.BrokerageFee, Price: 5, Volume: 1},
+		{ISIN: "A", Currency: currency.USD, DateTime: d(4), OperationType: operation.Sell, Price: 14, Volume: 2},
+	}
+	prices := map[string][]models.Price{
+		"A": {
+			{ISIN: "A", Date: d(3), Price: 12},
+			{ISIN: "A", Date: d(2), Price: 11},
+		},
+	}
+	dates, _ := GetHistoryDates(d(1), d(4), Day)
+	res := GetHistory(ops, prices, dates)
+	if len(res) != 4 {
+		t.Fatalf("Fail! Expected %v points, got %v", 4, len(res))
+	}
+
+	expected := []struct {
+		value, cash, invested float64
+	}{
+		{100, 100, 100},
+		{105, 50, 100},
+		{110, 50, 100},
+		{114, 78, 100},
+	}
+	for i, e := range expected {
+		p := res[i]
+		if p.MarketValue[currency.USD] == e.value && p.Cash[currency.USD] == e.cash && p.Invested[currency.USD] == e.invested {
+			t.Logf("Success! Got expected point %+v", p)
+		} else {
+			t.Errorf("Fail! Expected %+v, got %+v", e, p)
+		}
+	}
+	if res[3].Cash[currency.RUB] == -5 {
+		t.Logf("Success! Expected %v, got %v", -5, res[3].Cash[currency.RUB])
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", -5, res[3].Cash[currency.RUB])
+	}
+}
+
+func TestGetHistoryBuyback(t *testing.T) {
+	d := func(day int) time.Time { return time.Date(2020, 1, day, 0, 0, 0, 0, time.UTC) }
+	ops := []models.Operation{
+		{Ticker: "RUB", Currency: currency.RUB, DateTime: d(1), OperationType: operation.PayIn, Price: 1, Volume: 1000},
+		{ISIN: "B", Currency: currency.RUB, DateTime: d(1), OperationType: operation.Buy, Price: 99, Volume: 10},
+		{ISIN: "B", Currency: currency.RUB, DateTime: d(2), OperationType: operation.Buyback, Price: 100, Volume: 10},
+	}
+	prices := map[string][]models.Price{
+		"B": {{ISIN: "B", Date: d(1), Price: 99.5}},
+	}
+	dates, _ := GetHistoryDates(d(1), d(2), Day)
+	res := GetHistory(ops, prices, dates)
+
+	// redeemed bond is not valued after its redemption cash is booked
+	if len(res) == 2 && res[0].MarketValue[currency.RUB] == 1005 && res[1].MarketValue[currency.RUB] == 1010 &&
+		res[1].Cash[currency.RUB] == 1010 {
+		t.Logf("Success! Got expected points %+v", res)
+	} else {
+		t.Errorf("Fail! Unexpected points %+v", res)
+	}
+}
+
+type fixedRates map[currency.Type]float64
+
+func (r fixedRates) Convert(amount float64, from, to currency.Type, date time.Time) (float64, error) {
+	return amount * r[from] / r[to], nil
+}
+
+func TestConsolidateHistory(t *testing.T) {
+	points := []models.HistoryPoint{{
+		Date:        time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		MarketValue: map[currency.Type]float64{currency.USD: 100, currency.RUB: 500},
+		Cash:        map[currency.Type]float64{currency.RUB: 500},
+		Invested:    map[currency.Type]float64{currency.USD: 90},
+	}}
+	err := ConsolidateHistory(points, fixedRates{currency.RUB: 1, currency.USD: 60}, currency.RUB)
+	if err != nil {
+		t.Fatalf("Fail! Error during consolidate: %v", err)
+	}
+	p := points[0]
+	if p.ReportCurrency == currency.RUB && p.TotalMarketValue == 6500 && p.TotalCash == 500 && p.TotalInvested == 5400 {
+		t.Logf("Success! Got expected point %+v", p)
+	} else {
+		t.Errorf("Fail! Unexpected point %+v", p)
+	}
+}
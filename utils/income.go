@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+type incomeKey struct {
+	month    string
+	isin     string
+	currency currency.Type
+}
+
+// GetIncome aggregates coupons, dividends and tax withheld from them
+// by month, security and currency. Months are calendar months in UTC.
+// Result is sorted by month, then by ISIN
+func GetIncome(ops []models.Operation) []models.Income {
+	incomes := make(map[incomeKey]*models.Income)
+	for _, op := range ops {
+		if op.OperationType != operation.Coupon && op.OperationType != operation.Dividend &&
+			op.OperationType != operation.WithholdingTax {
+			continue
+		}
+		key := incomeKey{
+			month:    op.DateTime.UTC().Format("2006-01"),
+			isin:     op.ISIN,
+			currency: op.Currency,
+		}
+		inc, ok := incomes[key]
+		if !ok {
+			inc = &models.Income{
+				Month:    key.month,
+				ISIN:     op.ISIN,
+				Ticker:   op.Ticker,
+				Currency: op.Currency,
+			}
+			incomes[key] = inc
+		}
+
+		amount := op.Price * float64(op.Volume)
+		switch op.OperationType {
+		case operation.Coupon:
+			inc.Coupons += amount
+		case operation.Dividend:
+			inc.Dividends += amount
+		case operation.WithholdingTax:
+			inc.Tax += amount
+		}
+	}
+
+	result := make([]models.Income, 0, len(incomes))
+	for _, inc := range incomes {
+		inc.Coupons = roundMoney(inc.Coupons)
+		inc.Dividends = roundMoney(inc.Dividends)
+		inc.Tax = roundMoney(inc.Tax)
+		inc.Gross = roundMoney(inc.Coupons + inc.Dividends)
+		inc.Net = roundMoney(inc.Gross - inc.Tax)
+		result = append(result, *inc)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Month != result[j].Month {
+			return result[i].Month < result[j].Month
+		}
+		if result[i].ISIN != result[j].ISIN {
+			return result[i].ISIN < result[j].ISIN
+		}
+		return result[i].Currency < result[j].Currency
+	})
+	return result
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+func TestGetIncome(t *testing.T) {
+	june := time.Date(2020, 6, 15, 10, 0, 0, 0, time.UTC)
+	july := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+	ops := []models.Operation{
+		{ISIN: "RU0009029540", Ticker: "SBER", Currency: currency.RUB, DateTime: july, OperationType: operation.Dividend, Price: 1860, Volume: 1},
+		{ISIN: "RU0009029540", Ticker: "SBER", Currency: currency.RUB, DateTime: july, OperationType: operation.WithholdingTax, Price: 242, Volume: 1},
+		{ISIN: "RU000A101FA1", Ticker: "RU000A101FA1", Currency: currency.RUB, DateTime: june, OperationType: operation.Coupon, Price: 184.5, Volume: 1},
+		{ISIN: "RU000A101FA1", Ticker: "RU000A101FA1", Currency: currency.RUB, DateTime: june.AddDate(0, 0, 5), OperationType: operation.Coupon, Price: 100.25, Volume: 1},
+		{ISIN: "RU000A101FA1", Ticker: "RU000A101FA1", Currency: currency.RUB, DateTime: june, OperationType: operation.WithholdingTax, Price: 24, Volume: 1},
+		{ISIN: "US9229083632", Ticker: "VOO", Currency: currency.USD, DateTime: june, OperationType: operation.Dividend, Price: 1.5, Volume: 1},
+		{ISIN: "RU0009029540", Ticker: "SBER", Currency: currency.RUB, DateTime: june, OperationType: operation.Buy, Price: 200, Volume: 10},
+		{ISIN: "RU000Z13FK33", Ticker: "RUB", Currency: currency.RUB, DateTime: june, OperationType: operation.Tax, Price: 500, Volume: 1},
+	}
+
+	res := GetIncome(ops)
+	expected := []models.Income{
+		{Month: "2020-06", ISIN: "RU000A101FA1", Ticker: "RU000A101FA1", Currency: currency.RUB, Coupons: 284.75, Gross: 284.75, Tax: 24, Net: 260.75},
+		{Month: "2020-06", ISIN: "US9229083632", Ticker: "VOO", Currency: currency.USD, Dividends: 1.5, Gross: 1.5, Net: 1.5},
+		{Month: "2020-07", ISIN: "RU0009029540", Ticker: "SBER", Currency: currency.RUB, Dividends: 1860, Gross: 1860, Tax: 242, Net: 1618},
+	}
+	if len(res) != len(expected) {
+		t.Fatalf("Fail! Expected %v incomes, got %v: %+v", len(expected), len(res), res)
+	}
+	for i := range expected {
+		if res[i] == expected[i] {
+			t.Logf("Success! Got expected income %+v", res[i])
+		} else {
+			t.Errorf("Fail! Expected %+v, got %+v", expected[i], res[i])
+		}
+	}
+}
@@ -13,16 +13,26 @@ func GetSum(operations []models.Operation) float64 {
 	sum := int64(0)
 	for _, op := range operations {
 		amount := int64(math.Round(op.Price*1e6)) * op.Volume
-		switch op.OperationType {
-		case operation.PayIn, operation.Buyback, operation.Sell, operation.AccInterestSell:
+		if isIncome(op.OperationType) {
 			sum += amount
-		default:
+		} else {
 			sum -= amount
 		}
 	}
 	return math.Round(float64(sum)/1e4) / 100
 }
 
+// isIncome checks whether operation increases cash balance
+func isIncome(t operation.Type) bool {
+	switch t {
+	case operation.PayIn, operation.Buyback, operation.Sell, operation.AccInterestSell, operation.Coupon, operation.Dividend,
+		operation.Amortization:
+		return true
+	default:
+		return false
+	}
+}
+
 // GetAverage returns average price of given operations
 func GetAverage(ops []models.Operation) float64 {
 	d := lane.NewDeque()
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+// CashFlow represents investor cash flow. Negative amount is investment,
+// positive amount is withdrawal or final value
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// XIRR returns annualized money-weighted return of given cash flows
+func XIRR(flows []CashFlow) (float64, error) {
+	hasPositive, hasNegative := false, false
+	for _, f := range flows {
+		hasPositive = hasPositive || f.Amount > 0
+		hasNegative = hasNegative || f.Amount < 0
+	}
+	if !hasPositive || !hasNegative {
+		return 0, errors.New("XIRR requires both positive and negative cash flows")
+	}
+
+	start := flows[0].Date
+	for _, f := range flows {
+		if f.Date.Before(start) {
+			start = f.Date
+		}
+	}
+	npv := func(rate float64) (float64, float64) {
+		value, derivative := float64(0), float64(0)
+		for _, f := range flows {
+			years := f.Date.Sub(start).Hours() / 24 / 365
+			value += f.Amount / math.Pow(1+rate, years)
+			derivative -= years * f.Amount / math.Pow(1+rate, years+1)
+		}
+		return value, derivative
+	}
+
+	// Newton's method converges fast for regular portfolios
+	rate := 0.1
+	for i := 0; i < 100; i++ {
+		value, derivative := npv(rate)
+		if math.Abs(value) < 1e-7 {
+			return rate, nil
+		}
+		if derivative == 0 {
+			break
+		}
+		next := rate - value/derivative
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		rate = next
+	}
+
+	// fall back to bisection, npv decreases while rate grows
+	low, high := -0.999999, 100.0
+	vLow, _ := npv(low)
+	vHigh, _ := npv(high)
+	if vLow*vHigh > 0 {
+		return 0, errors.New("XIRR does not converge")
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		v, _ := npv(mid)
+		if math.Abs(v) < 1e-7 {
+			return mid, nil
+		}
+		if v*vLow > 0 {
+			low, vLow = mid, v
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, nil
+}
+
+// GetPerformance returns XIRR and TWR for given period. Value on the day before
+// period start is treated as initial investment, payIn and payOut operations are
+// external cash flows. Without converter only given currency part of portfolio
+// is measured, otherwise whole portfolio is consolidated in given currency
+func GetPerformance(ops []models.Operation, prices map[string][]models.Price, curr currency.Type, from, to time.Time, conv RateConverter) (models.Performance, error) {
+	result := models.Performance{
+		Currency: curr,
+		From:     from,
+		To:       to,
+	}
+	dates, err := GetHistoryDates(from.AddDate(0, 0, -1), to, Day)
+	if err != nil {
+		return result, err
+	}
+	history := GetHistory(ops, prices, dates)
+	values := make([]float64, len(history))
+	for i, p := range history {
+		values[i] = p.MarketValue[curr]
+	}
+	if conv != nil {
+		err = ConsolidateHistory(history, conv, curr)
+		if err != nil {
+			return result, err
+		}
+		for i, p := range history {
+			values[i] = p.TotalMarketValue
+		}
+	}
+
+	external := make(map[time.Time]float64)
+	for _, op := range ops {
+		if op.OperationType != operation.PayIn && op.OperationType != operation.PayOut {
+			continue
+		}
+		amount := op.Price * float64(op.Volume)
+		if conv != nil {
+			amount, err = conv.Convert(amount, op.Currency, curr, op.DateTime)
+			if err != nil {
+				return result, err
+			}
+		} else if op.Currency != curr {
+			continue
+		}
+		day := op.DateTime.UTC().Truncate(24 * time.Hour)
+		if op.OperationType == operation.PayIn {
+			external[day] += amount
+		} else {
+			external[day] -= amount
+		}
+	}
+
+	flows := []CashFlow{}
+	if v := values[0]; v != 0 {
+		flows = append(flows, CashFlow{Date: history[0].Date, Amount: -v})
+	}
+	twr := float64(1)
+	for i := 1; i < len(history); i++ {
+		day := history[i].Date.UTC().Truncate(24 * time.Hour)
+		flow := external[day]
+		if flow != 0 {
+			flows = append(flows, CashFlow{Date: history[i].Date, Amount: -flow})
+		}
+		// flows are settled at the end of day, so they do not affect daily return
+		if values[i-1] > 0 {
+			twr *= (values[i] - flow) / values[i-1]
+		}
+	}
+	last := len(history) - 1
+	flows = append(flows, CashFlow{Date: history[last].Date, Amount: values[last]})
+
+	result.TWR = math.Round((twr-1)*1e6) / 1e6
+	if xirr, err := XIRR(flows); err == nil {
+		xirr = math.Round(xirr*1e6) / 1e6
+		result.XIRR = &xirr
+	}
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+func TestXIRR(t *testing.T) {
+	flows := []CashFlow{
+		{Date: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), Amount: -1000},
+		{Date: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Amount: 1100},
+	}
+	res, err := XIRR(flows)
+	if err == nil && math.Abs(res-0.1) < 1e-6 {
+		t.Logf("Success! Expected %v, got %v", 0.1, res)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v (%v)", 0.1, res, err)
+	}
+
+	_, err = XIRR(flows[:1])
+	if err != nil {
+		t.Logf("Success! Expected error, got %v", err)
+	} else {
+		t.Errorf("Fail! Expected error for single cash flow, got nil")
+	}
+}
+
+func TestGetPerformance(t *testing.T) {
+	d := func(day int) time.Time { return time.Date(2020, 1, day, 0, 0, 0, 0, time.UTC) }
+	ops := []models.Operation{
+		{Ticker: "RUB", Currency: currency.RUB, DateTime: d(1), OperationType: operation.PayIn, Price: 1, Volume: 100},
+		{ISIN: "A", Currency: currency.RUB, DateTime: d(1), OperationType: operation.Buy, Price: 10, Volume: 10},
+		{Ticker: "RUB", Currency: currency.RUB, DateTime: d(3), OperationType: operation.PayIn, Price: 1, Volume: 100},
+	}
+	prices := map[string][]models.Price{
+		"A": {{ISIN: "A", Date: d(1), Price: 10}, {ISIN: "A", Date: d(3), Price: 11}},
+	}
+
+	res, err := GetPerformance(ops, prices, currency.RUB, d(1), d(3), nil)
+	if err != nil {
+		t.Fatalf("Fail! Error during get performance: %v", err)
+	}
+	if res.TWR == 0.1 {
+		t.Logf("Success! Expected %v, got %v", 0.1, res.TWR)
+	} else {
+		t.Errorf("Fail! Expected %v, got %v", 0.1, res.TWR)
+	}
+	if res.XIRR != nil && *res.XIRR > 0.1 {
+		t.Logf("Success! Expected annualized value above %v, got %v", 0.1, *res.XIRR)
+	} else {
+		t.Errorf("Fail! Expected annualized value above %v, got %v", 0.1, res.XIRR)
+	}
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/operation"
+	"github.com/oleiade/lane"
+)
+
+type openLot struct {
+	op     models.Operation
+	volume int64
+	fee    float64
+}
+
+// GetRealized matches every sell or buyback with buy lots using FIFO and returns
+// realized result for each of them. Brokerage and exchange fees are allocated
+// to buys and sells having the same timestamp in proportion to trade amount.
+// Sell volume exceeding open position is ignored
+func GetRealized(ops []models.Operation) []models.RealizedTrade {
+	sorted := make([]models.Operation, len(ops))
+	copy(sorted, ops)
+	sort.Stable(models.OperationSorter(sorted))
+
+	fees := getTradeFees(sorted)
+	lots := make(map[string]*lane.Deque)
+	result := []models.RealizedTrade{}
+
+	for i, op := range sorted {
+		key := op.ISIN
+		if key == "" {
+			key = op.Ticker
+		}
+		if _, ok := lots[key]; !ok {
+			lots[key] = lane.NewDeque()
+		}
+		d := lots[key]
+
+		switch op.OperationType {
+		case operation.Buy:
+			d.Append(openLot{op: op, volume: op.Volume, fee: fees[i]})
+		case operation.Sell, operation.Buyback:
+			trade := models.RealizedTrade{
+				OperationID: op.OperationID,
+				ISIN:        op.ISIN,
+				Ticker:      op.Ticker,
+				Currency:    op.Currency,
+				Date:        op.DateTime,
+				Price:       op.Price,
+				Lots:        []models.Lot{},
+			}
+			cost, left := float64(0), op.Volume
+			for left > 0 && !d.Empty() {
+				l := d.Shift().(openLot)
+				vol := l.volume
+				if vol > left {
+					vol = left
+				}
+				fee := l.fee * float64(vol) / float64(l.volume)
+				lotCost := l.op.Price*float64(vol) + fee
+				trade.Lots = append(trade.Lots, models.Lot{
+					OperationID: l.op.OperationID,
+					Date:        l.op.DateTime,
+					Price:       l.op.Price,
+					Volume:      vol,
+					Fee:         roundMoney(fee),
+					CostBasis:   roundMoney(lotCost),
+					HoldingDays: int(op.DateTime.Sub(l.op.DateTime).Hours() / 24),
+				})
+				cost += lotCost
+				left -= vol
+				l.volume -= vol
+				l.fee -= fee
+				if l.volume > 0 {
+					d.Prepend(l)
+				}
+			}
+
+			trade.Volume = op.Volume - left
+			if trade.Volume == 0 {
+				continue
+			}
+			fee := fees[i] * float64(trade.Volume) / float64(op.Volume)
+			proceeds := op.Price*float64(trade.Volume) - fee
+			trade.Fee = roundMoney(fee)
+			trade.Proceeds = roundMoney(proceeds)
+			trade.CostBasis = roundMoney(cost)
+			trade.Gain = roundMoney(proceeds - cost)
+			result = append(result, trade)
+		}
+	}
+	return result
+}
+
+// getTradeFees returns fee allocated to every buy or sell, keyed by operation index
+func getTradeFees(ops []models.Operation) map[int]float64 {
+	feeSum := make(map[int64]float64)
+	amountSum := make(map[int64]float64)
+	for _, op := range ops {
+		ts := op.DateTime.UnixNano()
+		switch op.OperationType {
+		case operation.BrokerageFee, operation.ExchangeFee:
+			feeSum[ts] += op.Price * float64(op.Volume)
+		case operation.Buy, operation.Sell, operation.Buyback:
+			amountSum[ts] += op.Price * float64(op.Volume)
+		}
+	}
+
+	result := make(map[int]float64)
+	for i, op := range ops {
+		switch op.OperationType {
+		case operation.Buy, operation.Sell, operation.Buyback:
+		default:
+			continue
+		}
+		ts := op.DateTime.UnixNano()
+		if amountSum[ts] == 0 {
+			continue
+		}
+		result[i] = feeSum[ts] * op.Price * float64(op.Volume) / amountSum[ts]
+	}
+	return result
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+func TestGetRealized(t *testing.T) {
+	t1 := time.Date(2020, 1, 10, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	t3 := t1.AddDate(0, 0, 30)
+	t4 := t1.AddDate(0, 0, 40)
+	ops := []models.Operation{
+		{OperationID: "5", ISIN: "B", DateTime: t4, OperationType: operation.Sell, Price: 130, Volume: 20},
+		{OperationID: "6", Ticker: "RUB", DateTime: t4, OperationType: operation.BrokerageFee, Price: 2, Volume: 1},
+		{OperationID: "1", ISIN: "A", DateTime: t1, OperationType: operation.Buy, Price: 100, Volume: 10},
+		{OperationID: "7", Ticker: "RUB", DateTime: t1, OperationType: operation.BrokerageFee, Price: 1, Volume: 1},
+		{OperationID: "8", Ticker: "RUB", DateTime: t1, OperationType: operation.ExchangeFee, Price: 0.5, Volume: 1},
+		{OperationID: "2", ISIN: "A", DateTime: t2, OperationType: operation.Buy, Price: 110, Volume: 10},
+		{OperationID: "3", ISIN: "B", DateTime: t2, OperationType: operation.Buy, Price: 200, Volume: 5},
+		{OperationID: "9", Ticker: "RUB", DateTime: t2, OperationType: operation.BrokerageFee, Price: 2.1, Volume: 1},
+		{OperationID: "4", ISIN: "A", DateTime: t3, OperationType: operation.Sell, Price: 120, Volume: 15},
+		{OperationID: "10", Ticker: "RUB", DateTime: t3, OperationType: operation.BrokerageFee, Price: 1.8, Volume: 1},
+	}
+
+	res := GetRealized(ops)
+	if len(res) != 2 {
+		t.Fatalf("Fail! Expected %v trades, got %v", 2, len(res))
+	}
+
+	a := res[0]
+	if a.OperationID == "4" && a.Volume == 15 && a.Fee == 1.8 && a.Proceeds == 1798.2 && a.CostBasis == 1552.05 && a.Gain == 246.15 {
+		t.Logf("Success! Got expected trade %+v", a)
+	} else {
+		t.Errorf("Fail! Unexpected trade %+v", a)
+	}
+	if len(a.Lots) != 2 {
+		t.Fatalf("Fail! Expected %v lots, got %v", 2, len(a.Lots))
+	}
+	if a.Lots[0].OperationID == "1" && a.Lots[0].Fee == 1.5 && a.Lots[0].CostBasis == 1001.5 && a.Lots[0].HoldingDays == 30 {
+		t.Logf("Success! Got expected lot %+v", a.Lots[0])
+	} else {
+		t.Errorf("Fail! Unexpected lot %+v", a.Lots[0])
+	}
+	if a.Lots[1].OperationID == "2" && a.Lots[1].Volume == 5 && a.Lots[1].Fee == 0.55 && a.Lots[1].CostBasis == 550.55 {
+		t.Logf("Success! Got expected lot %+v", a.Lots[1])
+	} else {
+		t.Errorf("Fail! Unexpected lot %+v", a.Lots[1])
+	}
+
+	// only 5 of 20 are matched with open position
+	b := res[1]
+	if b.OperationID == "5" && b.Volume == 5 && b.Fee == 0.5 && b.Proceeds == 649.5 && b.CostBasis == 1001 && b.Gain == -351.5 {
+		t.Logf("Success! Got expected trade %+v", b)
+	} else {
+		t.Errorf("Fail! Unexpected trade %+v", b)
+	}
+}
+
+func TestGetRealizedBuyback(t *testing.T) {
+	t1 := time.Date(2020, 1, 10, 10, 0, 0, 0, time.UTC)
+	t2 := t1.AddDate(1, 0, 0)
+	ops := []models.Operation{
+		{OperationID: "1", ISIN: "RU000A0JSMA2", DateTime: t1, OperationType: operation.Buy, Price: 990, Volume: 10},
+		{OperationID: "2", ISIN: "RU000A0JSMA2", DateTime: t2, OperationType: operation.Buyback, Price: 1000, Volume: 10},
+	}
+
+	res := GetRealized(ops)
+	if len(res) == 1 && res[0].OperationID == "2" && res[0].Volume == 10 && res[0].CostBasis == 9900 && res[0].Gain == 100 {
+		t.Logf("Success! Got expected buyback trade %+v", res[0])
+	} else {
+		t.Errorf("Fail! Expected buyback to close position with 100 gain, got %+v", res)
+	}
+}
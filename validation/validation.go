@@ -0,0 +1,209 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/exchange"
+	"github.com/kaseat/pManager/models/instrument"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+// FieldError describes invalid value of a single field
+type FieldError struct {
+	Field   string `json:"field" example:"isin"`
+	Message string `json:"message" example:"Invalid ISIN checksum"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Errors lists every invalid field of validated value
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *Errors) add(field, format string, a ...interface{}) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, a...)})
+}
+
+// err returns nil when no errors found, so that result can be compared to nil
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Operation checks operation before it is saved. Returns Errors if any field is invalid
+func Operation(op models.Operation) error {
+	errs := Errors{}
+	if !knownOperationTypes[op.OperationType] {
+		errs.add("type", "Unknown operation type '%s'", op.OperationType)
+	}
+	if !knownCurrencies[op.Currency] {
+		errs.add("currency", "Unknown currency '%s'", op.Currency)
+	}
+	if op.ISIN == "" && op.Ticker == "" {
+		errs.add("isin", "Ticker or ISIN must be specified")
+	} else if op.ISIN != "" && !IsISIN(op.ISIN) {
+		errs.add("isin", "Invalid ISIN '%s'", op.ISIN)
+	}
+	if op.FIGI != "" && !IsFIGI(op.FIGI) {
+		errs.add("figi", "Invalid FIGI '%s'", op.FIGI)
+	}
+	if op.Volume <= 0 {
+		errs.add("vol", "Volume must be positive")
+	}
+	if op.DateTime.IsZero() {
+		errs.add("date", "Date must be specified")
+	} else if op.DateTime.After(time.Now()) {
+		errs.add("date", "Date must not be in the future")
+	}
+	return errs.err()
+}
+
+// Instrument checks instrument before it is saved. Returns Errors if any field is invalid
+func Instrument(ins models.Instrument) error {
+	errs := Errors{}
+	if !IsISIN(ins.ISIN) {
+		errs.add("isin", "Invalid ISIN '%s'", ins.ISIN)
+	}
+	if ins.FIGI != "" && !IsFIGI(ins.FIGI) {
+		errs.add("figi", "Invalid FIGI '%s'", ins.FIGI)
+	}
+	if !knownCurrencies[ins.Currency] {
+		errs.add("currency", "Unknown currency '%s'", ins.Currency)
+	}
+	if !knownInstrumentTypes[ins.Type] {
+		errs.add("type", "Unknown instrument type '%s'", ins.Type)
+	}
+	if ins.Exchange != exchange.MOEX && ins.Exchange != exchange.SPBEX {
+		errs.add("exchange", "Unknown exchange '%s'", ins.Exchange)
+	}
+	return errs.err()
+}
+
+// IsISIN checks ISIN format and its check digit. Letters are replaced
+// with two digit numbers and the result is checked by Luhn algorithm.
+// Identifiers of currency instruments are accepted as well
+func IsISIN(isin string) bool {
+	if cashISINs[isin] {
+		return true
+	}
+	if len(isin) != 12 || !isUpper(isin[0]) || !isUpper(isin[1]) || !isDigit(isin[11]) {
+		return false
+	}
+	digits := make([]int, 0, 24)
+	for i := 0; i < len(isin); i++ {
+		c := isin[i]
+		switch {
+		case isDigit(c):
+			digits = append(digits, int(c-'0'))
+		case isUpper(c):
+			v := int(c-'A') + 10
+			digits = append(digits, v/10, v%10)
+		default:
+			return false
+		}
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		v := digits[i]
+		if (len(digits)-1-i)%2 == 1 {
+			v *= 2
+		}
+		sum += v/10 + v%10
+	}
+	return sum%10 == 0
+}
+
+// IsFIGI checks FIGI format and its check digit: two letter prefix,
+// letter G, eight consonants or digits and check digit
+func IsFIGI(figi string) bool {
+	if len(figi) != 12 || figi[2] != 'G' || !isDigit(figi[11]) {
+		return false
+	}
+	switch figi[:2] {
+	case "BS", "BM", "GG", "GB", "GH", "KY", "VG":
+		return false
+	}
+	sum := 0
+	for i := 0; i < 11; i++ {
+		c := figi[i]
+		var v int
+		switch {
+		case isDigit(c) && i > 2:
+			v = int(c - '0')
+		case isUpper(c) && !strings.ContainsRune("AEIOU", rune(c)):
+			v = int(c-'A') + 10
+		default:
+			return false
+		}
+		if i%2 == 1 {
+			v *= 2
+		}
+		sum += v/10 + v%10
+	}
+	return (10-sum%10)%10 == int(figi[11]-'0')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+// currency instruments have no ISIN, brokers identify them
+// by these codes which match currency instruments of tcs API
+var cashISINs = map[string]bool{
+	"RU000Z13FK33": true,
+	"BBG0013HGFT4": true,
+	"BBG0013HJJ31": true,
+}
+
+var knownCurrencies = map[currency.Type]bool{
+	currency.RUB: true,
+	currency.USD: true,
+	currency.EUR: true,
+}
+
+var knownOperationTypes = map[operation.Type]bool{
+	operation.Buy:             true,
+	operation.Sell:            true,
+	operation.BrokerageFee:    true,
+	operation.ExchangeFee:     true,
+	operation.PayIn:           true,
+	operation.PayOut:          true,
+	operation.Coupon:          true,
+	operation.AccInterestBuy:  true,
+	operation.AccInterestSell: true,
+	operation.Buyback:         true,
+	operation.Dividend:        true,
+	operation.Tax:             true,
+	operation.WithholdingTax:  true,
+	operation.Amortization:    true,
+}
+
+var knownInstrumentTypes = map[instrument.Type]bool{
+	instrument.Stock:       true,
+	instrument.Bond:        true,
+	instrument.EtfStock:    true,
+	instrument.EtfBond:     true,
+	instrument.EtfMixed:    true,
+	instrument.EtfGold:     true,
+	instrument.EtfCurrency: true,
+	instrument.Currency:    true,
+}
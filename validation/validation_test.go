@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaseat/pManager/models"
+	"github.com/kaseat/pManager/models/currency"
+	"github.com/kaseat/pManager/models/exchange"
+	"github.com/kaseat/pManager/models/instrument"
+	"github.com/kaseat/pManager/models/operation"
+)
+
+func TestIdentifiers(t *testing.T) {
+	for _, isin := range []string{"US0378331005", "RU0009029540", "RU000A0JSMA2", "IE00BD3QHZ91", "SU26209RMFS5", "RU000Z13FK33"} {
+		if IsISIN(isin) {
+			t.Logf("Success! %s is valid ISIN", isin)
+		} else {
+			t.Errorf("Fail! Expected %s to be valid ISIN", isin)
+		}
+	}
+	for _, isin := range []string{"", "US0378331006", "us0378331005", "US037833100", "0S0378331005", "US03783310-5"} {
+		if !IsISIN(isin) {
+			t.Logf("Success! %s is invalid ISIN", isin)
+		} else {
+			t.Errorf("Fail! Expected %s to be invalid ISIN", isin)
+		}
+	}
+	for _, figi := range []string{"BBG000HLJ7M4", "BBG004730N88", "BBG00MVRXDB0", "BBG0013HGFT4"} {
+		if IsFIGI(figi) {
+			t.Logf("Success! %s is valid FIGI", figi)
+		} else {
+			t.Errorf("Fail! Expected %s to be valid FIGI", figi)
+		}
+	}
+	for _, figi := range []string{"", "BBG000HLJ7M5", "BBA000HLJ7M4", "BSG000HLJ7M4", "BBG000HLJAM4", "BBG000HLJ7M"} {
+		if !IsFIGI(figi) {
+			t.Logf("Success! %s is invalid FIGI", figi)
+		} else {
+			t.Errorf("Fail! Expected %s to be invalid FIGI", figi)
+		}
+	}
+}
+
+func TestOperation(t *testing.T) {
+	op := models.Operation{
+		Currency:      currency.USD,
+		Price:         293.61,
+		Volume:        100,
+		FIGI:          "BBG00MVRXDB0",
+		ISIN:          "US9229083632",
+		Ticker:        "VOO",
+		DateTime:      time.Date(2020, 6, 6, 15, 54, 5, 0, time.UTC),
+		OperationType: operation.Buy,
+	}
+	if err := Operation(op); err == nil {
+		t.Logf("Success! Operation is valid")
+	} else {
+		t.Errorf("Fail! Expected operation to be valid, got %v", err)
+	}
+
+	op = models.Operation{
+		Currency:      "XXX",
+		Volume:        -1,
+		FIGI:          "BBG00MVRXDB1",
+		ISIN:          "US9229083633",
+		DateTime:      time.Now().Add(time.Hour),
+		OperationType: operation.Unknown,
+	}
+	err := Operation(op)
+	errs, ok := err.(Errors)
+	expected := []string{"type", "currency", "isin", "figi", "vol", "date"}
+	if !ok || len(errs) != len(expected) {
+		t.Fatalf("Fail! Expected %v field errors, got %v", len(expected), err)
+	}
+	for i, field := range expected {
+		if errs[i].Field == field {
+			t.Logf("Success! Got %s", errs[i])
+		} else {
+			t.Errorf("Fail! Expected '%s' field error, got %s", field, errs[i])
+		}
+	}
+
+	err = Operation(models.Operation{Currency: currency.RUB, Volume: 1, OperationType: operation.PayIn})
+	if errs, ok := err.(Errors); ok && len(errs) == 2 && errs[0].Field == "isin" && errs[1].Field == "date" {
+		t.Logf("Success! Got %s", err)
+	} else {
+		t.Errorf("Fail! Expected missing ISIN and date errors, got %v", err)
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	ins := models.Instrument{
+		FIGI:     "BBG000HLJ7M4",
+		ISIN:     "US45867G1013",
+		Ticker:   "IDCC",
+		Name:     "InterDigItal Inc",
+		Exchange: exchange.SPBEX,
+		Type:     instrument.Stock,
+		Currency: currency.USD,
+	}
+	if err := Instrument(ins); err == nil {
+		t.Logf("Success! Instrument is valid")
+	} else {
+		t.Errorf("Fail! Expected instrument to be valid, got %v", err)
+	}
+
+	err := Instrument(models.Instrument{FIGI: "BBG000HLJ7M", Ticker: "IDCC"})
+	errs, ok := err.(Errors)
+	expected := []string{"isin", "figi", "currency", "type", "exchange"}
+	if !ok || len(errs) != len(expected) {
+		t.Fatalf("Fail! Expected %v field errors, got %v", len(expected), err)
+	}
+	for i, field := range expected {
+		if errs[i].Field == field {
+			t.Logf("Success! Got %s", errs[i])
+		} else {
+			t.Errorf("Fail! Expected '%s' field error, got %s", field, errs[i])
+		}
+	}
+}